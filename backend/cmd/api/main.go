@@ -12,6 +12,11 @@ import (
 	"github.com/bendemouth/mlb-prediction-pool/internal/database"
 	"github.com/bendemouth/mlb-prediction-pool/internal/handlers"
 	"github.com/bendemouth/mlb-prediction-pool/internal/middleware"
+	"github.com/bendemouth/mlb-prediction-pool/internal/quota"
+	"github.com/bendemouth/mlb-prediction-pool/internal/services"
+	"github.com/bendemouth/mlb-prediction-pool/internal/stats"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+	"github.com/bendemouth/mlb-prediction-pool/internal/teams"
 	"github.com/joho/godotenv"
 )
 
@@ -21,7 +26,7 @@ func main() {
 	ctx := context.Background()
 
 	// Initialize database with retries
-	var db *database.DB
+	var db database.Store
 	var err error
 	maxRetries := 10
 	retryDelay := 2 * time.Second
@@ -29,7 +34,7 @@ func main() {
 	log.Println("Attempting to connect to database...")
 
 	for i := 0; i < maxRetries; i++ {
-		db, err = database.NewDBFromEnv(ctx)
+		db, err = database.NewStoreFromEnv(ctx)
 		if err == nil {
 			// Test the connection with health check
 			healthErr := db.HealthCheck(ctx)
@@ -60,25 +65,50 @@ func main() {
 
 	log.Println("Database connection established")
 
-	// Initialize S3 client
-	awsRegion := os.Getenv("AWS_REGION")
-	if awsRegion == "" {
-		awsRegion = "us-east-1"
+	// Keep the team reference data in step with the copy bundled in the binary
+	seeded, err := teams.Seed(ctx, db)
+	if err != nil {
+		log.Fatal("Failed to seed teams:", err)
+	}
+	log.Printf("Seeded %d teams", seeded)
+
+	// Initialize model file storage, S3 unless BLOB_STORE says otherwise
+	blobs, err := storage.NewBlobStoreFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to initialize blob store:", err)
+	}
+
+	// Per-user model quotas, MODEL_QUOTA_* in the environment
+	quotaLimits, err := quota.LimitsFromEnv()
+	if err != nil {
+		log.Fatal("Invalid model quota configuration:", err)
 	}
 
-	s3Client, err := handlers.NewS3Client(ctx, awsRegion)
+	// Team stat snapshots written by the ingestion Lambda. The API still starts without them.
+	var teamStats *stats.TeamStats
+	dataBlobs, err := storage.NewDataStoreFromEnv(ctx)
 	if err != nil {
-		log.Fatal("Failed to initialize S3 client:", err)
+		log.Printf("Team stats are unavailable: %v", err)
+	} else {
+		teamStats = stats.NewTeamStats(dataBlobs)
 	}
 
+	// Inspect files uploaded straight to storage in the background. Anything still queued at
+	// shutdown stays pending for the reconcile job.
+	uploads := services.NewModelUploadService(db, blobs)
+	uploadsCtx, stopUploads := context.WithCancel(ctx)
+	defer stopUploads()
+	go uploads.Run(uploadsCtx)
+
 	// Create handlers
-	h := handlers.NewHandler(db, *s3Client)
+	h := handlers.NewHandler(db, blobs, uploads, quotaLimits, teamStats)
 
 	// Create public server and routes
 	publicMux := http.NewServeMux()
 
 	publicMux.HandleFunc("/health", h.HandleHealthCheck)
 	publicMux.HandleFunc("/leaderboard", h.GetLeaderboard)
+	publicMux.HandleFunc("/leaderboard/models", h.GetModelLeaderboard)
 
 	// Create protected server and routes
 	protectedMux := http.NewServeMux()
@@ -88,6 +118,7 @@ func main() {
 	protectedMux.HandleFunc("/predictions/create", h.CreatePrediction)
 	protectedMux.HandleFunc("/predictions/batchCreate", h.CreateBulkPredictions)
 	protectedMux.HandleFunc("/predictions/game", h.GetPredictionsByGame)
+	protectedMux.HandleFunc("/predictions/model", h.GetPredictionsByModel)
 
 	// User endpoints
 	protectedMux.HandleFunc("/users/create", h.HandleCreateUser)
@@ -96,12 +127,51 @@ func main() {
 	protectedMux.HandleFunc("/users/stats", h.HandleGetUserStats)
 
 	// Games endpoints
+	protectedMux.HandleFunc("/games", h.GetGames)
 	protectedMux.HandleFunc("/games/upcoming", h.GetUpcomingGamesSummary)
+	protectedMux.HandleFunc("/games/", h.GetGameById)
+
+	// Teams endpoints
+	protectedMux.HandleFunc("/teams", h.GetTeams)
+	protectedMux.HandleFunc("/teams/", h.GetTeamById)
+
+	// Seasons endpoints
+	protectedMux.HandleFunc("/seasons", h.GetSeasons)
+	protectedMux.HandleFunc("/seasons/", h.GetSeasonByYear)
+
+	// League endpoints
+	protectedMux.HandleFunc("/leagues", h.GetUserLeaguesHandler)
+	protectedMux.HandleFunc("/leagues/create", h.CreateLeagueHandler)
+	protectedMux.HandleFunc("/leagues/join", h.JoinLeagueHandler)
+	protectedMux.HandleFunc("/leagues/invite/", h.RegenerateInviteHandler)
+	protectedMux.HandleFunc("/leagues/members/", h.LeagueMembersHandler)
+	protectedMux.HandleFunc("/leagues/leaderboard/", h.GetLeagueLeaderboard)
+	protectedMux.HandleFunc("/leagues/leaderboard/models/", h.GetLeagueModelLeaderboard)
+	protectedMux.HandleFunc("/leagues/predictions/", h.GetLeaguePredictions)
+	protectedMux.HandleFunc("/leagues/", h.LeagueHandler)
+
+	// Contest endpoints
+	protectedMux.HandleFunc("/contests", h.GetLeagueContestsHandler)
+	protectedMux.HandleFunc("/contests/create", h.CreateContestHandler)
+	protectedMux.HandleFunc("/contests/enter/", h.EnterContestHandler)
+	protectedMux.HandleFunc("/contests/leaderboard/", h.GetContestLeaderboard)
+	protectedMux.HandleFunc("/contests/", h.GetContestHandler)
+
+	// Feature store endpoints
+	protectedMux.HandleFunc("/stats/teams", h.GetTeamStats)
+	protectedMux.HandleFunc("/stats/teams/dates", h.GetTeamStatsDates)
+	protectedMux.HandleFunc("/export/games", h.ExportGames)
 
 	// Model endpoints
 	protectedMux.HandleFunc("/models/submitModel", h.UploadModelHandler)
+	protectedMux.HandleFunc("/models/uploads", h.CreateModelUpload)
+	protectedMux.HandleFunc("/models/uploads/", h.CompleteModelUpload)
 	protectedMux.HandleFunc("/models", h.GetUserModelsHandler)
 	protectedMux.HandleFunc("/models/delete/", h.DeleteModelHandler)
+	protectedMux.HandleFunc("/models/stats", h.GetModelStatsHandler)
+	protectedMux.HandleFunc("/models/versions/", h.GetModelVersionsHandler)
+	protectedMux.HandleFunc("/models/promote/", h.PromoteModelHandler)
+	protectedMux.HandleFunc("/models/rollback/", h.RollbackModelHandler)
 	protectedMux.HandleFunc("/models/", h.GetModelHandler)
 
 	protectedHandler := middleware.Auth(protectedMux)
@@ -109,8 +179,14 @@ func main() {
 	mainMux := http.NewServeMux()
 	mainMux.Handle("/health", publicMux)
 	mainMux.Handle("/leaderboard", publicMux)
+	mainMux.Handle("/leaderboard/models", publicMux)
 	mainMux.Handle("/", protectedHandler)
 
+	// The local blob store serves its own presigned URLs, which carry their own signature instead of a token
+	if local, ok := blobs.(*storage.LocalStore); ok {
+		mainMux.Handle(local.MountPath(), local)
+	}
+
 	// Add middleware
 	handler := middleware.Logger(
 		middleware.CORS(
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/joho/godotenv"
+)
+
+// backfill-games fills in gameDay, string team ids, season, gameType and gameNumber on games
+// stored before the API set them (DYNAMODB_GAMES_TABLE), so the games table GSIs and the
+// leaderboards find them. It only sets what is missing, so it is safe to run while the API and
+// ingest jobs are writing games, and again until it reports nothing updated.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "count the games that would be updated without writing anything")
+	flag.Parse()
+
+	godotenv.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	db, err := database.NewDBFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	result, err := db.BackfillGames(ctx, *dryRun)
+	if err != nil {
+		log.Fatal("Backfill failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+
+	if len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/dataset"
+	"github.com/bendemouth/mlb-prediction-pool/internal/stats"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+	"github.com/joho/godotenv"
+)
+
+// export writes a training dataset of completed games and the team stats known on each game day,
+// the same data GET /export/games serves.
+func main() {
+	formatName := flag.String("format", "csv", "csv, parquet or jsonl")
+	season := flag.Int("season", 0, "export one season, e.g. 2025")
+	from := flag.String("from", "", "first game day to export, YYYY-MM-DD")
+	to := flag.String("to", "", "last game day to export, YYYY-MM-DD")
+	out := flag.String("out", "", "file to write (default stdout)")
+	flag.Parse()
+
+	godotenv.Load()
+
+	format, err := dataset.ParseFormat(*formatName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	db, err := database.NewStoreFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	// Without the data bucket the export still has every game, just no stat columns
+	var teamStats *stats.TeamStats
+	if dataBlobs, err := storage.NewDataStoreFromEnv(ctx); err != nil {
+		log.Printf("Exporting without team stats: %v", err)
+	} else {
+		teamStats = stats.NewTeamStats(dataBlobs)
+	}
+
+	data, err := dataset.NewExporter(db, teamStats).Build(ctx, dataset.Query{Season: *season, From: *from, To: *to})
+	if err != nil {
+		log.Fatal("Failed to build dataset:", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatal("Failed to create output file:", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := data.Write(w, format); err != nil {
+		log.Fatal("Failed to write dataset:", err)
+	}
+
+	log.Printf("Exported %d games with %d columns", len(data.Rows), len(data.Columns))
+}
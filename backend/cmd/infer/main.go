@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/inference"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+	"github.com/joho/godotenv"
+)
+
+// infer runs every active model once against upcoming games and submits the predictions.
+// It is meant to be triggered on a schedule (cron, EventBridge) rather than run as a server.
+func main() {
+	godotenv.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	command := strings.Fields(os.Getenv("INFERENCE_COMMAND"))
+	if len(command) == 0 {
+		log.Fatal("INFERENCE_COMMAND is required, e.g. \"python3 scripts/run_model.py\"")
+	}
+
+	artifactDir := os.Getenv("MODEL_ARTIFACT_DIR")
+	if artifactDir == "" {
+		artifactDir = "model-artifacts"
+	}
+
+	db, err := database.NewStoreFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	// With a blob store configured, artifacts missing from artifactDir are downloaded on first use
+	var blobs storage.BlobStore
+	if os.Getenv("BLOB_STORE") != "" || os.Getenv("AWS_S3_BUCKET_NAME") != "" {
+		blobs, err = storage.NewBlobStoreFromEnv(ctx)
+		if err != nil {
+			log.Fatal("Failed to initialize blob store:", err)
+		}
+	}
+
+	pickleExecutor := inference.NewSubprocessExecutor(command, artifactDir)
+	pickleExecutor.Blobs = blobs
+	executors := inference.FrameworkExecutors{
+		models.ModelFrameworkPickle: pickleExecutor,
+	}
+	if onnxCommand := strings.Fields(os.Getenv("ONNX_INFERENCE_COMMAND")); len(onnxCommand) > 0 {
+		onnxExecutor := inference.NewONNXExecutor(onnxCommand, artifactDir)
+		onnxExecutor.Blobs = blobs
+		executors[models.ModelFrameworkONNX] = onnxExecutor
+	}
+
+	runner := inference.NewRunner(db, executors, inference.NewResultFeatures(db))
+
+	results, err := runner.RunAll(ctx)
+	if err != nil {
+		log.Fatal("Inference run failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(results)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/ingest"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/joho/godotenv"
+)
+
+// ingest loads MLB data into the games table. With -mode=schedule (the default) it loads the
+// schedule for today and the next 3 days; with -mode=results it records results for games
+// from yesterday and today, and with -settle also completes final games, scoring their predictions.
+// With -mode=season it loads a season's calendar, and -round-weights sets how much each
+// postseason round counts on that season's leaderboards.
+// It is meant to be triggered on a schedule (cron, EventBridge) rather than run as a server.
+func main() {
+	mode := flag.String("mode", "schedule", "what to ingest: schedule, results or season")
+	from := flag.String("from", "", "first day to ingest, YYYY-MM-DD")
+	to := flag.String("to", "", "last day to ingest, YYYY-MM-DD")
+	settle := flag.Bool("settle", false, "with -mode=results, complete final games and score their predictions")
+	season := flag.Int("season", 0, "with -mode=season, the season to load (default this year)")
+	roundWeights := flag.String("round-weights", "", "with -mode=season, postseason weights such as world_series=2,league_championship_series=1.5")
+	flag.Parse()
+
+	godotenv.Load()
+
+	now := time.Now()
+	switch *mode {
+	case "schedule":
+		*from = defaultDay(*from, now)
+		*to = defaultDay(*to, now.AddDate(0, 0, 3))
+	case "results":
+		*from = defaultDay(*from, now.AddDate(0, 0, -1))
+		*to = defaultDay(*to, now)
+	case "season":
+		if *season == 0 {
+			*season = now.Year()
+		}
+	default:
+		log.Fatalf("Unknown mode %q, expected schedule, results or season", *mode)
+	}
+
+	var weights map[string]float32
+	if *roundWeights != "" {
+		var err error
+		if weights, err = parseRoundWeights(*roundWeights); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	db, err := database.NewStoreFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	client := ingest.NewStatsAPIClient(os.Getenv("MLB_API_BASE"))
+
+	if *mode == "season" {
+		loaded, err := ingest.IngestSeason(ctx, client, db, *season)
+		if err != nil {
+			log.Fatal("Season ingestion failed:", err)
+		}
+		if weights != nil {
+			loaded.RoundWeights = weights
+			if err := db.PutSeason(ctx, loaded); err != nil {
+				log.Fatal("Failed to save round weights:", err)
+			}
+		}
+		printJSON(loaded)
+		return
+	}
+
+	ingester := ingest.NewIngester(client, db)
+
+	var result *ingest.Result
+	if *mode == "results" {
+		if *settle {
+			ingester.Settle = ingest.CompleteGames(db)
+		}
+		result, err = ingester.IngestResults(ctx, *from, *to)
+	} else {
+		result, err = ingester.IngestSchedule(ctx, *from, *to)
+	}
+	if err != nil {
+		log.Fatal("Ingestion failed:", err)
+	}
+
+	printJSON(result)
+}
+
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// parseRoundWeights reads round=weight pairs, e.g. world_series=2,league_championship_series=1.5
+func parseRoundWeights(value string) (map[string]float32, error) {
+	weights := make(map[string]float32)
+	for _, pair := range strings.Split(value, ",") {
+		round, weight, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !models.IsPostseason(round) {
+			return nil, fmt.Errorf("invalid round weight %q, expected one of %s with a weight", pair, strings.Join(models.PostseasonRounds, ", "))
+		}
+		parsed, err := strconv.ParseFloat(weight, 32)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s, expected a positive number", weight, round)
+		}
+		weights[round] = float32(parsed)
+	}
+	return weights, nil
+}
+
+func defaultDay(day string, fallback time.Time) string {
+	if day != "" {
+		return day
+	}
+	return fallback.Format(models.GameDayLayout)
+}
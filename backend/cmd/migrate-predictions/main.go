@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/joho/godotenv"
+)
+
+// migrate-predictions copies the predictions table that was keyed by userId and gameId into
+// the one keyed by userId and predictionKey (DYNAMODB_PREDICTIONS_TABLE). It never replaces a
+// pick with an older one, so it is safe to run before and again after the API is switched to
+// the new table.
+//
+// Rollout:
+//  1. terraform apply creates <project>-<env>-predictions-v2 next to the old table.
+//  2. Run this with DYNAMODB_PREDICTIONS_TABLE set to the new table and -from the old one.
+//  3. Point the API and jobs at the new table and restart them.
+//  4. Run it again to pick up picks made or changed on the old table during the switch.
+//  5. Once the counts check out, drop the old table in a later change.
+func main() {
+	source := flag.String("from", "", "the old predictions table, e.g. mlb-prediction-pool-prod-predictions")
+	dryRun := flag.Bool("dry-run", false, "count what would be copied without writing anything")
+	flag.Parse()
+
+	if *source == "" {
+		log.Fatal("-from is required")
+	}
+
+	godotenv.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+
+	db, err := database.NewDBFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	result, err := db.CopyPredictions(ctx, *source, *dryRun)
+	if err != nil {
+		log.Fatal("Copy failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+
+	if len(result.Errors) > 0 {
+		os.Exit(1)
+	}
+}
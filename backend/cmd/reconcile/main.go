@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/services"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+	"github.com/joho/godotenv"
+)
+
+// reconcile removes model files with no record and model records whose file is gone,
+// and inspects uploads that were left pending.
+// It is meant to be triggered on a schedule (cron, EventBridge) rather than run as a server.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report orphans without deleting anything")
+	gracePeriod := flag.Duration("grace", 24*time.Hour, "leave anything younger than this alone, so uploads in flight aren't touched")
+	flag.Parse()
+
+	godotenv.Load()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	db, err := database.NewStoreFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	blobs, err := storage.NewBlobStoreFromEnv(ctx)
+	if err != nil {
+		log.Fatal("Failed to initialize blob store:", err)
+	}
+
+	reconciler := &Reconciler{db: db, blobs: blobs, uploads: services.NewModelUploadService(db, blobs), gracePeriod: *gracePeriod, dryRun: *dryRun}
+
+	result, err := reconciler.Run(ctx)
+	if err != nil {
+		log.Fatal("Reconciliation failed:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/services"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+// modelPrefix is where model files are stored in the bucket
+const modelPrefix = "models/"
+
+// modelStatuses covers every model record, since the store can only list models by status
+var modelStatuses = []string{
+	models.ModelStatusPending,
+	models.ModelStatusValidated,
+	models.ModelStatusRejected,
+	models.ModelStatusActive,
+}
+
+type Reconciler struct {
+	db          database.ModelStore
+	blobs       storage.BlobStore
+	uploads     *services.ModelUploadService
+	gracePeriod time.Duration
+	dryRun      bool
+}
+
+// Result lists what was (or in a dry run, would be) removed
+type Result struct {
+	DryRun          bool     `json:"dry_run"`
+	ObjectsChecked  int      `json:"objects_checked"`
+	ModelsChecked   int      `json:"models_checked"`
+	OrphanedObjects []string `json:"orphaned_objects"`
+	OrphanedModels  []string `json:"orphaned_models"`
+	InspectedModels []string `json:"inspected_models"`
+	// ReinspectedModels were made active or validated before uploads were inspected
+	ReinspectedModels []string `json:"reinspected_models"`
+	Errors            []string `json:"errors,omitempty"`
+}
+
+// Run compares the models/ prefix with the models table in both directions.
+// Objects are listed before records: a record is always written before its file, so a file
+// uploaded mid-run can't be mistaken for an orphan. Rejected records without a file are
+// intentional and kept. Pending models whose file did arrive but which the API never got
+// to inspect, e.g. because it restarted, are inspected and settled here, as are models that
+// were made active before uploads were inspected at all.
+func (r *Reconciler) Run(ctx context.Context) (*Result, error) {
+	cutoff := time.Now().Add(-r.gracePeriod)
+	result := &Result{DryRun: r.dryRun, OrphanedObjects: []string{}, OrphanedModels: []string{}, InspectedModels: []string{}, ReinspectedModels: []string{}}
+
+	objects, err := r.blobs.List(ctx, modelPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model files: %w", err)
+	}
+	result.ObjectsChecked = len(objects)
+
+	var records []*models.ModelMetadata
+	for _, status := range modelStatuses {
+		byStatus, err := r.db.GetModelsByStatus(ctx, status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s models: %w", status, err)
+		}
+		records = append(records, byStatus...)
+	}
+	result.ModelsChecked = len(records)
+
+	stored := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		stored[object.Key] = true
+	}
+	referenced := make(map[string]bool, len(records))
+	for _, model := range records {
+		if model.S3Key != "" {
+			referenced[model.S3Key] = true
+		}
+	}
+
+	for _, object := range objects {
+		if referenced[object.Key] || object.LastModified.After(cutoff) {
+			continue
+		}
+		result.OrphanedObjects = append(result.OrphanedObjects, object.Key)
+		if r.dryRun {
+			continue
+		}
+		if err := r.blobs.Delete(ctx, object.Key); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("delete file %s: %v", object.Key, err))
+		}
+	}
+
+	for _, model := range records {
+		if model.S3Key == "" || stored[model.S3Key] || model.CreatedAt.After(cutoff) {
+			continue
+		}
+		result.OrphanedModels = append(result.OrphanedModels, model.ModelId)
+		if r.dryRun {
+			continue
+		}
+		if err := r.db.DeleteModel(ctx, model.ModelId, model.UserId); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("delete model %s: %v", model.ModelId, err))
+		}
+	}
+
+	for _, model := range records {
+		if model.Status != models.ModelStatusPending || model.S3Key == "" || !stored[model.S3Key] || model.CreatedAt.After(cutoff) {
+			continue
+		}
+		result.InspectedModels = append(result.InspectedModels, model.ModelId)
+		if r.dryRun {
+			continue
+		}
+		if _, err := r.uploads.InspectStored(ctx, model.ModelId, model.UserId); err != nil && !errors.Is(err, services.ErrModelSettled) {
+			result.Errors = append(result.Errors, fmt.Sprintf("inspect model %s: %v", model.ModelId, err))
+		}
+	}
+
+	for _, model := range records {
+		if !services.Uninspected(model) || model.S3Key == "" || !stored[model.S3Key] {
+			continue
+		}
+		result.ReinspectedModels = append(result.ReinspectedModels, model.ModelId)
+		if r.dryRun {
+			continue
+		}
+		if _, err := r.uploads.Reinspect(ctx, model.ModelId, model.UserId); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("reinspect model %s: %v", model.ModelId, err))
+		}
+	}
+
+	log.Printf("Reconciled %d files and %d models: %d orphaned files, %d orphaned models, %d pending models inspected, %d uninspected models reinspected",
+		result.ObjectsChecked, result.ModelsChecked, len(result.OrphanedObjects), len(result.OrphanedModels),
+		len(result.InspectedModels), len(result.ReinspectedModels))
+
+	return result, nil
+}
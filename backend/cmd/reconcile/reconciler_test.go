@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/services"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+// agedStore backdates the models listed in created, since MemoryDB stamps CreatedAt itself
+type agedStore struct {
+	*database.MemoryDB
+	created map[string]time.Time
+}
+
+func (s agedStore) GetModelsByStatus(ctx context.Context, status string) ([]*models.ModelMetadata, error) {
+	modelList, err := s.MemoryDB.GetModelsByStatus(ctx, status)
+	for _, model := range modelList {
+		if createdAt, ok := s.created[model.ModelId]; ok {
+			model.CreatedAt = createdAt
+		}
+	}
+	return modelList, err
+}
+
+// pickle is PROTO 4, BININT1 1, STOP
+var pickle = []byte{0x80, 0x04, 'K', 0x01, '.'}
+
+// systemPickle loads os.system through GLOBAL
+var systemPickle = []byte("cposix\nsystem\n.")
+
+// newTestReconciler stores, outside a one hour grace period unless noted:
+//   - kept: an active model with its file
+//   - lost: a validated model whose file is gone
+//   - uploading: a pending model made inside the grace period, whose file hasn't arrived yet
+//   - rejected: a rejected model, which never keeps a file
+//   - stuck: a pending model whose file arrived but was never inspected
+//   - legacy, legacy-exploit: active models the old upload handler never inspected, one of them unsafe
+//   - models/u2/orphan.pkl: a file without a model
+//   - models/u2/fresh.pkl: a file without a model, written inside the grace period
+func newTestReconciler(t *testing.T, dryRun bool) (*Reconciler, *database.MemoryDB, *storage.LocalStore) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+	blobs, err := storage.NewLocalStore(dir, "http://localhost", []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := database.NewMemoryDB()
+	old := time.Now().Add(-2 * time.Hour)
+
+	sum := sha256.Sum256(pickle)
+	for _, model := range []models.ModelMetadata{
+		{ModelId: "kept", ModelName: "kept", S3Key: "models/u1/kept.pkl", Status: models.ModelStatusActive},
+		{ModelId: "lost", ModelName: "lost", S3Key: "models/u1/lost.pkl", Status: models.ModelStatusValidated},
+		{ModelId: "uploading", ModelName: "uploading", S3Key: "models/u1/uploading.pkl", Status: models.ModelStatusPending},
+		{ModelId: "rejected", ModelName: "rejected", Status: models.ModelStatusRejected},
+		{ModelId: "stuck", ModelName: "stuck", S3Key: "models/u1/stuck.pkl", Status: models.ModelStatusPending},
+	} {
+		model.UserId = "u1"
+		model.FileName = "model.pkl"
+		model.Framework = models.ModelFrameworkPickle
+		model.Sha256 = hex.EncodeToString(sum[:])
+		model.SizeBytes = int64(len(pickle))
+		model.Version = 1
+		if err := db.CreateModel(ctx, &model); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Before inspection, records had no checksum, size or framework
+	for _, model := range []models.ModelMetadata{
+		{ModelId: "legacy", ModelName: "legacy", S3Key: "models/u1/legacy.pkl"},
+		{ModelId: "legacy-exploit", ModelName: "legacy-exploit", S3Key: "models/u1/legacy-exploit.pkl"},
+	} {
+		model.UserId = "u1"
+		model.FileName = "model.pkl"
+		model.Status = models.ModelStatusActive
+		if err := db.CreateModel(ctx, &model); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := blobs.Put(ctx, "models/u1/legacy-exploit.pkl", bytes.NewReader(systemPickle), "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"models/u1/kept.pkl", "models/u1/stuck.pkl", "models/u1/legacy.pkl", "models/u2/orphan.pkl", "models/u2/fresh.pkl"} {
+		if err := blobs.Put(ctx, key, bytes.NewReader(pickle), "application/octet-stream"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range []string{"models/u1/kept.pkl", "models/u1/stuck.pkl", "models/u1/legacy.pkl", "models/u1/legacy-exploit.pkl", "models/u2/orphan.pkl"} {
+		if err := os.Chtimes(filepath.Join(dir, filepath.FromSlash(key)), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := agedStore{MemoryDB: db, created: map[string]time.Time{"kept": old, "lost": old, "rejected": old, "stuck": old, "legacy": old, "legacy-exploit": old}}
+	reconciler := &Reconciler{
+		db:          store,
+		blobs:       blobs,
+		uploads:     services.NewModelUploadService(db, blobs),
+		gracePeriod: time.Hour,
+		dryRun:      dryRun,
+	}
+	return reconciler, db, blobs
+}
+
+func checkResult(t *testing.T, result *Result) {
+	t.Helper()
+	if result.ObjectsChecked != 6 || result.ModelsChecked != 7 {
+		t.Errorf("checked %d files and %d models, want 6 and 7", result.ObjectsChecked, result.ModelsChecked)
+	}
+	if !slices.Equal(result.OrphanedObjects, []string{"models/u2/orphan.pkl"}) {
+		t.Errorf("OrphanedObjects = %v, want only the old file without a model", result.OrphanedObjects)
+	}
+	if !slices.Equal(result.OrphanedModels, []string{"lost"}) {
+		t.Errorf("OrphanedModels = %v, want [lost]", result.OrphanedModels)
+	}
+	if !slices.Equal(result.InspectedModels, []string{"stuck"}) {
+		t.Errorf("InspectedModels = %v, want [stuck]", result.InspectedModels)
+	}
+	if !slices.Equal(result.ReinspectedModels, []string{"legacy", "legacy-exploit"}) {
+		t.Errorf("ReinspectedModels = %v, want [legacy legacy-exploit]", result.ReinspectedModels)
+	}
+	if len(result.Errors) > 0 {
+		t.Errorf("Errors = %v", result.Errors)
+	}
+}
+
+func modelStatus(t *testing.T, db *database.MemoryDB, modelId string) string {
+	t.Helper()
+	model, err := db.GetModelById(context.Background(), modelId, "u1")
+	if err != nil {
+		return "deleted"
+	}
+	return model.Status
+}
+
+func fileExists(blobs *storage.LocalStore, key string) bool {
+	_, err := blobs.Head(context.Background(), key)
+	return err == nil
+}
+
+func TestReconcilerDryRun(t *testing.T) {
+	reconciler, db, blobs := newTestReconciler(t, true)
+
+	result, err := reconciler.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.DryRun {
+		t.Error("DryRun = false, want true")
+	}
+	checkResult(t, result)
+
+	if !fileExists(blobs, "models/u2/orphan.pkl") {
+		t.Error("dry run deleted the orphaned file")
+	}
+	if got := modelStatus(t, db, "lost"); got != models.ModelStatusValidated {
+		t.Errorf("lost = %s after a dry run, want it left validated", got)
+	}
+	if got := modelStatus(t, db, "stuck"); got != models.ModelStatusPending {
+		t.Errorf("stuck = %s after a dry run, want it left pending", got)
+	}
+	if got := modelStatus(t, db, "legacy-exploit"); got != models.ModelStatusActive {
+		t.Errorf("legacy-exploit = %s after a dry run, want it left active", got)
+	}
+}
+
+func TestReconcilerRun(t *testing.T) {
+	ctx := context.Background()
+	reconciler, db, blobs := newTestReconciler(t, false)
+
+	result, err := reconciler.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	checkResult(t, result)
+
+	for key, want := range map[string]bool{
+		"models/u1/kept.pkl":   true,
+		"models/u1/stuck.pkl":  true,
+		"models/u1/legacy.pkl": true,
+		// Rejected files aren't kept
+		"models/u1/legacy-exploit.pkl": false,
+		"models/u2/orphan.pkl":         false,
+		"models/u2/fresh.pkl":          true,
+	} {
+		if got := fileExists(blobs, key); got != want {
+			t.Errorf("%s exists = %v, want %v", key, got, want)
+		}
+	}
+	for modelId, want := range map[string]string{
+		"kept":      models.ModelStatusActive,
+		"lost":      "deleted",
+		"uploading": models.ModelStatusPending,
+		"rejected":  models.ModelStatusRejected,
+		// The first version of its family, so it goes live once it passes
+		"stuck":          models.ModelStatusActive,
+		"legacy":         models.ModelStatusActive,
+		"legacy-exploit": models.ModelStatusRejected,
+	} {
+		if got := modelStatus(t, db, modelId); got != want {
+			t.Errorf("%s = %s, want %s", modelId, got, want)
+		}
+	}
+
+	// Inspection records the checksum, so the runner will now run it
+	if legacy, err := db.GetModelById(ctx, "legacy", "u1"); err != nil || legacy.Sha256 == "" || legacy.Framework != models.ModelFrameworkPickle {
+		t.Errorf("legacy after reinspection = %+v, %v, want its checksum and framework recorded", legacy, err)
+	}
+
+	// Everything left is accounted for, so a second run finds nothing
+	again, err := reconciler.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() again error = %v", err)
+	}
+	if len(again.OrphanedObjects) > 0 || len(again.OrphanedModels) > 0 || len(again.InspectedModels) > 0 ||
+		len(again.ReinspectedModels) > 0 || len(again.Errors) > 0 {
+		t.Errorf("second Run() = %+v, want nothing left to do", again)
+	}
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+var (
+	ErrContestNotFound       = errors.New("contest not found")
+	ErrAlreadyEnteredContest = errors.New("already entered this contest")
+)
+
+// Contests table GSIs
+const contestLeagueIdIndex = "LeagueIdIndex" // leagueId, on the contests table
+
+// CreateContest stores a new contest
+func (db *DB) CreateContest(ctx context.Context, contest *models.Contest) error {
+	contest.CreatedAt = time.Now()
+
+	item, err := attributevalue.MarshalMap(contest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal contest: %w", err)
+	}
+
+	_, err = db.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(db.contestsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(contestId)"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create contest: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetContest(ctx context.Context, contestId string) (*models.Contest, error) {
+	result, err := db.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(db.contestsTable),
+		Key: map[string]types.AttributeValue{
+			"contestId": &types.AttributeValueMemberS{Value: contestId},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrContestNotFound
+	}
+
+	var contest models.Contest
+	if err := attributevalue.UnmarshalMap(result.Item, &contest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal contest: %w", err)
+	}
+
+	return &contest, nil
+}
+
+// GetLeagueContests retrieves a league's contests, the ones whose entry closes first first
+func (db *DB) GetLeagueContests(ctx context.Context, leagueId string) ([]models.Contest, error) {
+	items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.contestsTable),
+		IndexName:              aws.String(contestLeagueIdIndex),
+		KeyConditionExpression: aws.String("leagueId = :leagueId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":leagueId": &types.AttributeValueMemberS{Value: leagueId},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query league contests: %w", err)
+	}
+
+	contests := make([]models.Contest, 0, len(items))
+	for _, item := range items {
+		var contest models.Contest
+		if err := attributevalue.UnmarshalMap(item, &contest); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal contest: %w", err)
+		}
+		contests = append(contests, contest)
+	}
+
+	sortContests(contests)
+	return contests, nil
+}
+
+// EnterContest records a user's entry, failing if they have already entered
+func (db *DB) EnterContest(ctx context.Context, entry *models.ContestEntry) error {
+	entry.EnteredAt = time.Now()
+
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal contest entry: %w", err)
+	}
+
+	_, err = db.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(db.entriesTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(userId)"),
+	})
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return ErrAlreadyEnteredContest
+		}
+		return fmt.Errorf("failed to enter contest: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetContestEntries(ctx context.Context, contestId string) ([]models.ContestEntry, error) {
+	items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.entriesTable),
+		KeyConditionExpression: aws.String("contestId = :contestId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":contestId": &types.AttributeValueMemberS{Value: contestId},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contest entries: %w", err)
+	}
+
+	entries := make([]models.ContestEntry, 0, len(items))
+	for _, item := range items {
+		var entry models.ContestEntry
+		if err := attributevalue.UnmarshalMap(item, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal contest entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func sortContests(contests []models.Contest) {
+	sort.SliceStable(contests, func(i, j int) bool {
+		if contests[i].EntryCloses.Equal(contests[j].EntryCloses) {
+			return contests[i].ContestId < contests[j].ContestId
+		}
+		return contests[i].EntryCloses.Before(contests[j].EntryCloses)
+	})
+}
+
+// ContestSlate returns a contest's games, ordered by start time: the ones frozen when it was
+// created, or for contests made before slates were frozen, the ones its rules select now
+func ContestSlate(ctx context.Context, db GameStore, contest models.Contest) ([]models.Game, error) {
+	if len(contest.GameIds) == 0 {
+		return ContestGames(ctx, db, contest.Rules)
+	}
+	return db.GetGamesByIds(ctx, contest.GameIds)
+}
+
+// ContestGames resolves the slate rules select, ordered by start time. A date range is read
+// through GetGamesInRange, or per team when the rules name teams.
+func ContestGames(ctx context.Context, db GameStore, rules models.ContestRules) ([]models.Game, error) {
+	games := make([]models.Game, 0)
+	seen := make(map[string]bool)
+	keep := func(candidates []models.Game) {
+		for _, game := range candidates {
+			if !seen[game.GameId] && rules.Matches(game) {
+				seen[game.GameId] = true
+				games = append(games, game)
+			}
+		}
+	}
+
+	switch {
+	case len(rules.GameIds) > 0:
+		for _, gameId := range rules.GameIds {
+			game, err := db.GetGame(ctx, gameId)
+			if err != nil {
+				return nil, err
+			}
+			keep([]models.Game{*game})
+		}
+
+	case len(rules.TeamIds) > 0:
+		for _, teamId := range rules.TeamIds {
+			teamGames, err := db.GetGamesByTeam(ctx, teamId, rules.From, rules.To)
+			if err != nil {
+				return nil, err
+			}
+			keep(teamGames)
+		}
+
+	default:
+		inRange, err := GetGamesInRange(ctx, db, rules.From, rules.To)
+		if err != nil {
+			return nil, err
+		}
+		keep(inRange)
+	}
+
+	sortGamesByDate(games)
+	return games, nil
+}
@@ -16,6 +16,14 @@ type DB struct {
 	predictionsTable string
 	gamesTable       string
 	modelsTable      string
+	uploadsTable     string
+	versionsTable    string
+	teamsTable       string
+	seasonsTable     string
+	leaguesTable     string
+	membersTable     string
+	contestsTable    string
+	entriesTable     string
 }
 
 type DBConfig struct {
@@ -25,6 +33,14 @@ type DBConfig struct {
 	PredictionsTable string
 	GamesTable       string
 	ModelsTable      string
+	UploadsTable     string
+	VersionsTable    string
+	TeamsTable       string
+	SeasonsTable     string
+	LeaguesTable     string
+	MembersTable     string
+	ContestsTable    string
+	EntriesTable     string
 }
 
 // NewDB creates a new database connection
@@ -52,6 +68,14 @@ func NewDB(ctx context.Context, cfg DBConfig) (*DB, error) {
 		predictionsTable: cfg.PredictionsTable,
 		gamesTable:       cfg.GamesTable,
 		modelsTable:      cfg.ModelsTable,
+		uploadsTable:     cfg.UploadsTable,
+		versionsTable:    cfg.VersionsTable,
+		teamsTable:       cfg.TeamsTable,
+		seasonsTable:     cfg.SeasonsTable,
+		leaguesTable:     cfg.LeaguesTable,
+		membersTable:     cfg.MembersTable,
+		contestsTable:    cfg.ContestsTable,
+		entriesTable:     cfg.EntriesTable,
 	}
 
 	return db, nil
@@ -65,6 +89,14 @@ func NewDBFromEnv(ctx context.Context) (*DB, error) {
 		PredictionsTable: getEnv("DYNAMODB_PREDICTIONS_TABLE", "mlb-prediction-pool-predictions"),
 		GamesTable:       getEnv("DYNAMODB_GAMES_TABLE", "mlb-prediction-pool-games"),
 		ModelsTable:      getEnv("DYNAMODB_MODELS_TABLE", "mlb-prediction-pool-models"),
+		UploadsTable:     getEnv("DYNAMODB_MODEL_UPLOADS_TABLE", "mlb-prediction-pool-model-uploads"),
+		VersionsTable:    getEnv("DYNAMODB_MODEL_VERSIONS_TABLE", "mlb-prediction-pool-model-versions"),
+		TeamsTable:       getEnv("DYNAMODB_TEAMS_TABLE", "mlb-prediction-pool-teams"),
+		SeasonsTable:     getEnv("DYNAMODB_SEASONS_TABLE", "mlb-prediction-pool-seasons"),
+		LeaguesTable:     getEnv("DYNAMODB_LEAGUES_TABLE", "mlb-prediction-pool-leagues"),
+		MembersTable:     getEnv("DYNAMODB_LEAGUE_MEMBERS_TABLE", "mlb-prediction-pool-league-members"),
+		ContestsTable:    getEnv("DYNAMODB_CONTESTS_TABLE", "mlb-prediction-pool-contests"),
+		EntriesTable:     getEnv("DYNAMODB_CONTEST_ENTRIES_TABLE", "mlb-prediction-pool-contest-entries"),
 	}
 
 	return NewDB(ctx, cfg)
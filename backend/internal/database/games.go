@@ -2,7 +2,11 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -11,8 +15,25 @@ import (
 	"github.com/bendemouth/mlb-prediction-pool/internal/models"
 )
 
+var ErrGameNotFound = errors.New("game not found")
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// Games table GSIs
+const (
+	statusDateIndex  = "StatusDateIndex"  // status + date
+	gameDayIndex     = "GameDayIndex"     // gameDay + date
+	homeTeamDayIndex = "HomeTeamDayIndex" // homeTeamId + gameDay
+	awayTeamDayIndex = "AwayTeamDayIndex" // awayTeamId + gameDay
+	maxGameRangeDays = 31
+)
+
+// MaxGamesInRangeDays is the longest range GetGamesInRange reads: a season, leap years included
+const MaxGamesInRangeDays = 366
+
 // CreateGame stores a new game
 func (db *DB) CreateGame(ctx context.Context, game *models.Game) error {
+	setGameDefaults(game)
+
 	item, err := attributevalue.MarshalMap(game)
 	if err != nil {
 		return fmt.Errorf("failed to marshal game: %w", err)
@@ -46,7 +67,7 @@ func (db *DB) GetGame(ctx context.Context, gameID string) (*models.Game, error)
 	}
 
 	if result.Item == nil {
-		return nil, fmt.Errorf("game not found")
+		return nil, ErrGameNotFound
 	}
 
 	var game models.Game
@@ -58,11 +79,38 @@ func (db *DB) GetGame(ctx context.Context, gameID string) (*models.Game, error)
 	return &game, nil
 }
 
+// GetGamesByIds retrieves the listed games, ordered by start time. Ids without a game are skipped.
+func (db *DB) GetGamesByIds(ctx context.Context, gameIds []string) ([]models.Game, error) {
+	keys := make([]item, 0, len(gameIds))
+	seen := make(map[string]bool, len(gameIds))
+	for _, gameId := range gameIds {
+		// BatchGetItem rejects a request that names the same key twice
+		if seen[gameId] {
+			continue
+		}
+		seen[gameId] = true
+		keys = append(keys, item{"gameId": &types.AttributeValueMemberS{Value: gameId}})
+	}
+
+	items, err := db.batchGetAll(ctx, db.gamesTable, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get games: %w", err)
+	}
+
+	games, err := unmarshalGames(items)
+	if err != nil {
+		return nil, err
+	}
+	sortGamesByDate(games)
+	return games, nil
+}
+
 // GetUpcomingGames retrieves games with status "upcoming"
 func (db *DB) GetUpcomingGames(ctx context.Context) ([]models.Game, error) {
-	input := &dynamodb.ScanInput{
-		TableName:        aws.String(db.gamesTable),
-		FilterExpression: aws.String("#status = :status"),
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.gamesTable),
+		IndexName:              aws.String(statusDateIndex),
+		KeyConditionExpression: aws.String("#status = :status"),
 		ExpressionAttributeNames: map[string]string{
 			"#status": "status",
 		},
@@ -71,13 +119,104 @@ func (db *DB) GetUpcomingGames(ctx context.Context) ([]models.Game, error) {
 		},
 	}
 
-	result, err := db.client.Scan(ctx, input)
+	items, err := db.queryAll(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query upcoming games: %w", err)
+	}
+
+	return unmarshalGames(items)
+}
+
+// GetGamesByDate retrieves all games scheduled on a day (YYYY-MM-DD), ordered by start time
+func (db *DB) GetGamesByDate(ctx context.Context, day string) ([]models.Game, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.gamesTable),
+		IndexName:              aws.String(gameDayIndex),
+		KeyConditionExpression: aws.String("gameDay = :gameDay"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":gameDay": &types.AttributeValueMemberS{Value: day},
+		},
+	}
+
+	items, err := db.queryAll(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games for %s: %w", day, err)
+	}
+
+	return unmarshalGames(items)
+}
+
+// GetGamesByDateRange retrieves games scheduled between two days, inclusive.
+// GameDayIndex is partitioned by day, so this issues one query per day in the range.
+func (db *DB) GetGamesByDateRange(ctx context.Context, from, to string) ([]models.Game, error) {
+	days, err := gameDaysBetween(from, to)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan games: %w", err)
+		return nil, err
+	}
+
+	games := make([]models.Game, 0)
+	for _, day := range days {
+		dayGames, err := db.GetGamesByDate(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, dayGames...)
+	}
+
+	return games, nil
+}
+
+// GetGamesByTeam retrieves home and away games for a team, optionally bounded by from/to days.
+// Empty bounds are open-ended.
+func (db *DB) GetGamesByTeam(ctx context.Context, teamId, from, to string) ([]models.Game, error) {
+	games := make([]models.Game, 0)
+
+	for _, index := range []struct{ name, attribute string }{
+		{homeTeamDayIndex, "homeTeamId"},
+		{awayTeamDayIndex, "awayTeamId"},
+	} {
+		keyCondition := index.attribute + " = :teamId"
+		values := map[string]types.AttributeValue{
+			":teamId": &types.AttributeValueMemberS{Value: teamId},
+		}
+
+		switch {
+		case from != "" && to != "":
+			keyCondition += " AND gameDay BETWEEN :from AND :to"
+			values[":from"] = &types.AttributeValueMemberS{Value: from}
+			values[":to"] = &types.AttributeValueMemberS{Value: to}
+		case from != "":
+			keyCondition += " AND gameDay >= :from"
+			values[":from"] = &types.AttributeValueMemberS{Value: from}
+		case to != "":
+			keyCondition += " AND gameDay <= :to"
+			values[":to"] = &types.AttributeValueMemberS{Value: to}
+		}
+
+		items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+			TableName:                 aws.String(db.gamesTable),
+			IndexName:                 aws.String(index.name),
+			KeyConditionExpression:    aws.String(keyCondition),
+			ExpressionAttributeValues: values,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query games for team %s: %w", teamId, err)
+		}
+
+		teamGames, err := unmarshalGames(items)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, teamGames...)
 	}
 
-	games := make([]models.Game, 0, len(result.Items))
-	for _, item := range result.Items {
+	sortGamesByDate(games)
+	return games, nil
+}
+
+func unmarshalGames(items []item) ([]models.Game, error) {
+	games := make([]models.Game, 0, len(items))
+	for _, item := range items {
 		var game models.Game
 		if err := attributevalue.UnmarshalMap(item, &game); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal game: %w", err)
@@ -88,6 +227,93 @@ func (db *DB) GetUpcomingGames(ctx context.Context) ([]models.Game, error) {
 	return games, nil
 }
 
+// setGameDefaults fills in GameDay from Date, Season from GameDay and treats games without a type
+// or number as single regular season games, for callers that don't set them explicitly
+func setGameDefaults(game *models.Game) {
+	if game.GameDay == "" && !game.Date.IsZero() {
+		game.GameDay = game.Date.Format(models.GameDayLayout)
+	}
+	if game.Season == 0 && len(game.GameDay) >= 4 {
+		game.Season, _ = strconv.Atoi(game.GameDay[:4])
+	}
+	if game.GameType == "" {
+		game.GameType = models.GameTypeRegular
+	}
+	if game.GameNumber == 0 {
+		game.GameNumber = 1
+	}
+}
+
+// GetGamesInRange returns the games from..to inclusive, ordered by start time, reading
+// maxGameRangeDays at a time so the range can be longer than GetGamesByDateRange allows.
+// The range is capped at MaxGamesInRangeDays since the DynamoDB store queries once per day.
+func GetGamesInRange(ctx context.Context, db GameStore, from, to string) ([]models.Game, error) {
+	start, err := time.Parse(models.GameDayLayout, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid from date %q", ErrInvalidDateRange, from)
+	}
+	end, err := time.Parse(models.GameDayLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid to date %q", ErrInvalidDateRange, to)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidDateRange)
+	}
+	if end.Sub(start) >= MaxGamesInRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range is limited to %d days", ErrInvalidDateRange, MaxGamesInRangeDays)
+	}
+
+	games := make([]models.Game, 0)
+	for chunkStart := start; !chunkStart.After(end); chunkStart = chunkStart.AddDate(0, 0, maxGameRangeDays) {
+		chunkEnd := chunkStart.AddDate(0, 0, maxGameRangeDays-1)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+
+		chunk, err := db.GetGamesByDateRange(ctx, chunkStart.Format(models.GameDayLayout), chunkEnd.Format(models.GameDayLayout))
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, chunk...)
+	}
+
+	return games, nil
+}
+
+// gameDaysBetween lists each day from..to inclusive, capped at maxGameRangeDays
+func gameDaysBetween(from, to string) ([]string, error) {
+	start, err := time.Parse(models.GameDayLayout, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid from date %q", ErrInvalidDateRange, from)
+	}
+	end, err := time.Parse(models.GameDayLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid to date %q", ErrInvalidDateRange, to)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidDateRange)
+	}
+	if end.Sub(start) >= maxGameRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range is limited to %d days", ErrInvalidDateRange, maxGameRangeDays)
+	}
+
+	days := make([]string, 0)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(models.GameDayLayout))
+	}
+
+	return days, nil
+}
+
+func sortGamesByDate(games []models.Game) {
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].Date.Equal(games[j].Date) {
+			return games[i].GameId < games[j].GameId
+		}
+		return games[i].Date.Before(games[j].Date)
+	})
+}
+
 // UpdateGameResult updates a game's result
 func (db *DB) UpdateGameResult(ctx context.Context, gameID, winner string) error {
 	input := &dynamodb.UpdateItemInput{
@@ -133,13 +359,19 @@ func (db *DB) CompleteGame(ctx context.Context, gameId string, homeScore int, aw
 			":awayScore": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", awayScore)},
 			":winner":    &types.AttributeValueMemberS{Value: winnerId},
 		},
+		ReturnValues: types.ReturnValueAllNew,
 	}
 
-	_, err := db.client.UpdateItem(ctx, updateGameInput)
+	updated, err := db.client.UpdateItem(ctx, updateGameInput)
 	if err != nil {
 		return fmt.Errorf("failed to update game: %w", err)
 	}
 
+	var game models.Game
+	if err := attributevalue.UnmarshalMap(updated.Attributes, &game); err != nil {
+		return fmt.Errorf("failed to unmarshal game: %w", err)
+	}
+
 	// Get all predictions for the game
 	predictions, err := db.GetPredictionsByGame(ctx, gameId)
 	if err != nil {
@@ -148,7 +380,7 @@ func (db *DB) CompleteGame(ctx context.Context, gameId string, homeScore int, aw
 
 	// Update each prediction based on the game result
 	for _, prediction := range predictions {
-		if err := db.updatePredictionsWithResult(ctx, prediction.UserId, gameId, winnerId, homeScore, awayScore); err != nil {
+		if err := db.updatePredictionsWithResult(ctx, prediction.UserId, &game, prediction.ModelId); err != nil {
 			return fmt.Errorf("failed to update prediction for user %s: %w", prediction.UserId, err)
 		}
 	}
@@ -156,36 +388,40 @@ func (db *DB) CompleteGame(ctx context.Context, gameId string, homeScore int, aw
 	return nil
 }
 
-func (db *DB) updatePredictionsWithResult(ctx context.Context, userId, gameId, winnerId string, homeScore, awayScore int) error {
-	pred, err := db.GetPredictionByUser(ctx, userId, gameId)
+func (db *DB) updatePredictionsWithResult(ctx context.Context, userId string, game *models.Game, modelId string) error {
+	pred, err := db.GetPredictionByUser(ctx, userId, game.GameId, modelId)
 	if err != nil {
 		return fmt.Errorf("failed to get prediction: %w", err)
 	}
+	if pred == nil {
+		return fmt.Errorf("prediction not found")
+	}
 
-	homeScoreError := abs(pred.HomeScorePredicted - float32(homeScore))
-	awayScoreError := abs(pred.AwayScorePredicted - float32(awayScore))
-	totalScoreError := abs(pred.TotalScorePredicted - float32(homeScore+awayScore))
-	winnerCorrect := pred.PredictedWinnerId == winnerId
+	scorePrediction(pred, game)
 
 	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String(db.predictionsTable),
 		Key: map[string]types.AttributeValue{
-			"userId": &types.AttributeValueMemberS{Value: userId},
-			"gameId": &types.AttributeValueMemberS{Value: gameId},
+			"userId":        &types.AttributeValueMemberS{Value: userId},
+			"predictionKey": &types.AttributeValueMemberS{Value: PredictionSortKey(game.GameId, modelId)},
 		},
 		UpdateExpression: aws.String(
 			"SET actualWinnerId = :actualWinnerId, " +
 				"winnerCorrect = :winnerCorrect, " +
 				"homeScoreError = :homeScoreError, " +
 				"awayScoreError = :awayScoreError, " +
-				"totalScoreError = :totalScoreError",
+				"totalScoreError = :totalScoreError, " +
+				"season = :season, " +
+				"gameType = :gameType",
 		),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":actualWinnerId":  &types.AttributeValueMemberS{Value: winnerId},
-			":winnerCorrect":   &types.AttributeValueMemberBOOL{Value: winnerCorrect},
-			":homeScoreError":  &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", homeScoreError)},
-			":awayScoreError":  &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", awayScoreError)},
-			":totalScoreError": &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", totalScoreError)},
+			":actualWinnerId":  &types.AttributeValueMemberS{Value: pred.ActualWinnerId},
+			":winnerCorrect":   &types.AttributeValueMemberBOOL{Value: *pred.WinnerCorrect},
+			":homeScoreError":  &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", pred.HomeScoreError)},
+			":awayScoreError":  &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", pred.AwayScoreError)},
+			":totalScoreError": &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", pred.TotalScoreError)},
+			":season":          &types.AttributeValueMemberN{Value: strconv.Itoa(pred.Season)},
+			":gameType":        &types.AttributeValueMemberS{Value: pred.GameType},
 		},
 	}
 
@@ -197,6 +433,20 @@ func (db *DB) updatePredictionsWithResult(ctx context.Context, userId, gameId, w
 	return nil
 }
 
+// scorePrediction fills in the result fields of a prediction once the game is final, and copies
+// the game's season and type so leaderboards can be scoped without reading every game
+func scorePrediction(pred *models.Prediction, game *models.Game) {
+	winnerCorrect := pred.PredictedWinnerId == game.Winner
+
+	pred.ActualWinnerId = game.Winner
+	pred.WinnerCorrect = &winnerCorrect
+	pred.HomeScoreError = abs(pred.HomeScorePredicted - float32(game.HomeScore))
+	pred.AwayScoreError = abs(pred.AwayScorePredicted - float32(game.AwayScore))
+	pred.TotalScoreError = abs(pred.TotalScorePredicted - float32(game.HomeScore+game.AwayScore))
+	pred.Season = game.Season
+	pred.GameType = game.GameType
+}
+
 func abs(x float32) float32 {
 	if x < 0 {
 		return -x
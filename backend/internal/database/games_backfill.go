@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// GameBackfillResult counts what BackfillGames did. A dry run counts the games it would update.
+type GameBackfillResult struct {
+	Table   string   `json:"table"`
+	DryRun  bool     `json:"dry_run"`
+	Scanned int      `json:"scanned"`
+	Updated int      `json:"updated"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// BackfillGames fills in the attributes the games table GSIs and the API now rely on for games
+// stored before they existed, the way setGameDefaults does for new ones: gameDay from date,
+// string team ids, season from gameDay, and a regular season single game when gameType or
+// gameNumber are missing. Bare YYYY-MM-DD dates, as the data ingestion Lambda wrote them, are
+// rewritten as RFC 3339 so the game can be read back.
+// Only missing or mistyped attributes are set, so it can be re-run until it updates nothing.
+func (db *DB) BackfillGames(ctx context.Context, dryRun bool) (*GameBackfillResult, error) {
+	result := &GameBackfillResult{Table: db.gamesTable, DryRun: dryRun}
+
+	paginator := dynamodb.NewScanPaginator(db.client, &dynamodb.ScanInput{TableName: aws.String(db.gamesTable)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to scan %s: %w", db.gamesTable, err)
+		}
+
+		for _, item := range page.Items {
+			result.Scanned++
+
+			gameId, _ := item["gameId"].(*types.AttributeValueMemberS)
+			if gameId == nil || gameId.Value == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("item %d has no gameId", result.Scanned))
+				continue
+			}
+
+			updates, err := gameBackfill(item)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("game %s: %v", gameId.Value, err))
+				continue
+			}
+			if len(updates) == 0 {
+				continue
+			}
+			if dryRun {
+				result.Updated++
+				continue
+			}
+
+			if err := db.setGameAttributes(ctx, gameId.Value, updates); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("game %s: %v", gameId.Value, err))
+				continue
+			}
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// gameBackfill works out which attributes of a stored game BackfillGames has to set
+func gameBackfill(game item) (item, error) {
+	updates := make(item)
+
+	date, ok := game["date"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, errors.New("no date")
+	}
+	start, err := time.Parse(time.RFC3339Nano, date.Value)
+	if err != nil {
+		if start, err = time.Parse(models.GameDayLayout, date.Value); err != nil {
+			return nil, fmt.Errorf("unreadable date %q", date.Value)
+		}
+		updates["date"] = &types.AttributeValueMemberS{Value: start.Format(time.RFC3339Nano)}
+	}
+
+	gameDay := ""
+	if existing, ok := game["gameDay"].(*types.AttributeValueMemberS); ok && existing.Value != "" {
+		gameDay = existing.Value
+	} else {
+		gameDay = start.Format(models.GameDayLayout)
+		updates["gameDay"] = &types.AttributeValueMemberS{Value: gameDay}
+	}
+
+	for _, attribute := range []string{"homeTeamId", "awayTeamId"} {
+		switch teamId := game[attribute].(type) {
+		case *types.AttributeValueMemberS:
+		case *types.AttributeValueMemberN:
+			updates[attribute] = &types.AttributeValueMemberS{Value: teamId.Value}
+		default:
+			return nil, fmt.Errorf("no %s", attribute)
+		}
+	}
+
+	if !positiveNumber(game["season"]) {
+		season, err := strconv.Atoi(gameDay[:4])
+		if err != nil {
+			return nil, fmt.Errorf("unreadable gameDay %q", gameDay)
+		}
+		updates["season"] = &types.AttributeValueMemberN{Value: strconv.Itoa(season)}
+	}
+	if gameType, ok := game["gameType"].(*types.AttributeValueMemberS); !ok || gameType.Value == "" {
+		updates["gameType"] = &types.AttributeValueMemberS{Value: models.GameTypeRegular}
+	}
+	if !positiveNumber(game["gameNumber"]) {
+		updates["gameNumber"] = &types.AttributeValueMemberN{Value: "1"}
+	}
+
+	return updates, nil
+}
+
+func positiveNumber(value types.AttributeValue) bool {
+	number, ok := value.(*types.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	parsed, err := strconv.Atoi(number.Value)
+	return err == nil && parsed > 0
+}
+
+// setGameAttributes sets attributes on an existing game, leaving the rest of it alone
+func (db *DB) setGameAttributes(ctx context.Context, gameId string, updates item) error {
+	attributes := make([]string, 0, len(updates))
+	for attribute := range updates {
+		attributes = append(attributes, attribute)
+	}
+	sort.Strings(attributes)
+
+	assignments := make([]string, 0, len(attributes))
+	names := make(map[string]string, len(attributes))
+	values := make(map[string]types.AttributeValue, len(attributes))
+	for _, attribute := range attributes {
+		// date is a reserved word, so every attribute goes through a name placeholder
+		assignments = append(assignments, fmt.Sprintf("#%s = :%s", attribute, attribute))
+		names["#"+attribute] = attribute
+		values[":"+attribute] = updates[attribute]
+	}
+
+	_, err := db.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(db.gamesTable),
+		Key: map[string]types.AttributeValue{
+			"gameId": &types.AttributeValueMemberS{Value: gameId},
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(assignments, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(gameId)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update game: %w", err)
+	}
+
+	return nil
+}
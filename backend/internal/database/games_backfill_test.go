@@ -0,0 +1,63 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestGameBackfill(t *testing.T) {
+	s := func(value string) types.AttributeValue { return &types.AttributeValueMemberS{Value: value} }
+	n := func(value string) types.AttributeValue { return &types.AttributeValueMemberN{Value: value} }
+
+	tests := []struct {
+		name    string
+		game    item
+		want    item
+		wantErr bool
+	}{
+		{
+			name: "written by the data ingestion Lambda",
+			game: item{"gameId": s("777001"), "date": s("2025-06-10"), "homeTeamId": n("111"), "awayTeamId": n("147"), "status": s("Scheduled")},
+			want: item{
+				"date": s("2025-06-10T00:00:00Z"), "gameDay": s("2025-06-10"), "homeTeamId": s("111"), "awayTeamId": s("147"),
+				"season": n("2025"), "gameType": s("regular"), "gameNumber": n("1"),
+			},
+		},
+		{
+			name: "stored before gameDay and season",
+			game: item{"gameId": s("777002"), "date": s("2024-09-29T17:05:00Z"), "homeTeamId": s("111"), "awayTeamId": s("147")},
+			want: item{"gameDay": s("2024-09-29"), "season": n("2024"), "gameType": s("regular"), "gameNumber": n("1")},
+		},
+		{
+			name: "already complete",
+			game: item{
+				"gameId": s("777003"), "date": s("2025-10-01T00:08:00Z"), "gameDay": s("2025-09-30"), "homeTeamId": s("111"), "awayTeamId": s("147"),
+				"season": n("2025"), "gameType": s("wild_card"), "gameNumber": n("2"),
+			},
+			want: item{},
+		},
+		{
+			name:    "no date",
+			game:    item{"gameId": s("777004"), "homeTeamId": s("111"), "awayTeamId": s("147")},
+			wantErr: true,
+		},
+		{
+			name:    "no away team",
+			game:    item{"gameId": s("777005"), "date": s("2025-06-10"), "homeTeamId": s("111")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := gameBackfill(tt.game)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: gameBackfill() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: gameBackfill() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestGetGamesInRange(t *testing.T) {
+	ctx := context.Background()
+	db := NewMemoryDB()
+
+	// A game every ten days from April through June, more than two chunks' worth
+	var all []string
+	for day := time.Date(2025, 4, 1, 23, 0, 0, 0, time.UTC); day.Month() <= time.June; day = day.AddDate(0, 0, 10) {
+		game := models.Game{GameId: day.Format(models.GameDayLayout), Date: day, HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming}
+		if err := db.CreateGame(ctx, &game); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, game.GameId)
+	}
+
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"2025-04-01", "2025-06-30", all},
+		{"2025-04-02", "2025-05-01", []string{"2025-04-11", "2025-04-21", "2025-05-01"}},
+		{"2025-05-01", "2025-05-01", []string{"2025-05-01"}},
+		{"2025-07-01", "2025-12-31", nil},
+	}
+
+	for _, tt := range tests {
+		games, err := GetGamesInRange(ctx, db, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("GetGamesInRange(%s, %s) error = %v", tt.from, tt.to, err)
+		}
+		var got []string
+		for _, game := range games {
+			got = append(got, game.GameId)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GetGamesInRange(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	// A leap year is the longest range allowed
+	if _, err := GetGamesInRange(ctx, db, "2024-01-01", "2024-12-31"); err != nil {
+		t.Errorf("GetGamesInRange() over a leap year error = %v", err)
+	}
+
+	for _, tt := range []struct{ name, from, to string }{
+		{"a bad date", "April", "2025-06-30"},
+		{"a reversed range", "2025-06-30", "2025-04-01"},
+		{"more than a year", "2024-01-01", "2025-01-01"},
+		{"every representable day", "0001-01-01", "9999-12-31"},
+	} {
+		if _, err := GetGamesInRange(ctx, db, tt.from, tt.to); !errors.Is(err, ErrInvalidDateRange) {
+			t.Errorf("GetGamesInRange() with %s error = %v, want ErrInvalidDateRange", tt.name, err)
+		}
+	}
+}
+
+func TestGetGamesByIds(t *testing.T) {
+	ctx := context.Background()
+	for name, db := range map[string]Store{
+		"memory": NewMemoryDB(),
+		"sqlite": openSQLite(t, filepath.Join(t.TempDir(), "pool.db")),
+	} {
+		start := time.Date(2025, 6, 10, 23, 5, 0, 0, time.UTC)
+		for i, gameId := range []string{"g3", "g1", "g2"} {
+			game := models.Game{GameId: gameId, Date: start.Add(time.Duration(i) * time.Hour), HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming}
+			if err := db.CreateGame(ctx, &game); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		games, err := db.GetGamesByIds(ctx, []string{"g2", "missing", "g3", "g2"})
+		if err != nil {
+			t.Fatalf("%s: GetGamesByIds() error = %v", name, err)
+		}
+		var got []string
+		for _, game := range games {
+			got = append(got, game.GameId)
+		}
+		if !slices.Equal(got, []string{"g3", "g2"}) {
+			t.Errorf("%s: GetGamesByIds() = %v, want [g3 g2] by start time", name, got)
+		}
+	}
+}
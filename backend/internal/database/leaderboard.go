@@ -2,16 +2,185 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 
 	"github.com/bendemouth/mlb-prediction-pool/internal/models"
 )
 
+// scoringSource is the subset of Store the leaderboard calculations read from
+type scoringSource interface {
+	GetUser(ctx context.Context, userId string) (*models.User, error)
+	ListUsers(ctx context.Context) ([]*models.User, error)
+	GetUserPredictions(ctx context.Context, userId string) ([]models.Prediction, error)
+	GetModelsByUserId(ctx context.Context, userId string) ([]*models.ModelMetadata, error)
+	GetModelPredictions(ctx context.Context, modelId string) ([]models.Prediction, error)
+}
+
+// LeaderboardScope selects the predictions standings are calculated over
+type LeaderboardScope struct {
+	// Season limits scoring to one season's games; 0 scores all time
+	Season int
+	// RoundWeights weights postseason games by round, as in models.Season
+	RoundWeights map[string]float32
+	// GameTypes limits scoring to these game types; empty scores every game
+	GameTypes []string
+	// Profile is how the leaderboard score weighs its parts, models.ScoringProfileStandard when empty
+	Profile string
+	// UserIds limits standings to these users, such as a league's members; nil ranks everyone
+	UserIds []string
+	// GameIds limits scoring to these games, such as a contest's slate; nil scores every game
+	GameIds map[string]bool
+}
+
+// scoringWeights are how much winner accuracy, team score error and total score error count
+// towards the leaderboard score
+type scoringWeights struct {
+	winnerAccuracy float32
+	teamScore      float32
+	totalScore     float32
+}
+
+var scoringProfiles = map[string]scoringWeights{
+	models.ScoringProfileStandard: {winnerAccuracy: 0.6, teamScore: 0.2, totalScore: 0.2},
+	models.ScoringProfileWinners:  {winnerAccuracy: 1},
+	models.ScoringProfileScores:   {winnerAccuracy: 0.2, teamScore: 0.4, totalScore: 0.4},
+}
+
+// IsScoringProfile reports whether profile names a scoring profile
+func IsScoringProfile(profile string) bool {
+	_, ok := scoringProfiles[profile]
+	return ok
+}
+
+// SeasonScope scores a single season with its round weights
+func SeasonScope(season *models.Season) LeaderboardScope {
+	return LeaderboardScope{Season: season.Year, RoundWeights: season.RoundWeights}
+}
+
+// LeagueScope narrows scope to a league's members, the game types it counts and its scoring profile
+func LeagueScope(scope LeaderboardScope, league *models.League, members []models.LeagueMember) LeaderboardScope {
+	scope.GameTypes = league.Settings.GameTypes
+	scope.Profile = league.Settings.ScoringProfile
+	scope.UserIds = make([]string, 0, len(members))
+	for _, member := range members {
+		scope.UserIds = append(scope.UserIds, member.UserId)
+	}
+	return scope
+}
+
+// ContestScope scores a contest: its entrants' picks on its games, under the league's scoring profile
+func ContestScope(league *models.League, entries []models.ContestEntry, games []models.Game) LeaderboardScope {
+	scope := LeaderboardScope{
+		Profile: league.Settings.ScoringProfile,
+		UserIds: make([]string, 0, len(entries)),
+		GameIds: make(map[string]bool, len(games)),
+	}
+	for _, entry := range entries {
+		scope.UserIds = append(scope.UserIds, entry.UserId)
+	}
+	for _, game := range games {
+		scope.GameIds[game.GameId] = true
+	}
+	return scope
+}
+
+// filter keeps the scope's predictions. Predictions scored before games carried a season
+// fall back to the year they were submitted in, and count as regular season games.
+func (s LeaderboardScope) filter(predictions []models.Prediction) []models.Prediction {
+	if s.Season == 0 && len(s.GameTypes) == 0 && s.GameIds == nil {
+		return predictions
+	}
+
+	scoped := make([]models.Prediction, 0, len(predictions))
+	for _, prediction := range predictions {
+		season := prediction.Season
+		if season == 0 {
+			season = prediction.SubmittedAt.Year()
+		}
+		if s.Season != 0 && season != s.Season {
+			continue
+		}
+		if s.GameIds != nil && !s.GameIds[prediction.GameId] {
+			continue
+		}
+
+		gameType := prediction.GameType
+		if gameType == "" {
+			gameType = models.GameTypeRegular
+		}
+		if len(s.GameTypes) > 0 && !slices.Contains(s.GameTypes, gameType) {
+			continue
+		}
+
+		scoped = append(scoped, prediction)
+	}
+	return scoped
+}
+
+// users are the users the scope ranks. A scoped user who has since been deleted is skipped.
+func (s LeaderboardScope) users(ctx context.Context, db scoringSource) ([]*models.User, error) {
+	if s.UserIds == nil {
+		return db.ListUsers(ctx)
+	}
+
+	users := make([]*models.User, 0, len(s.UserIds))
+	for _, userId := range s.UserIds {
+		user, err := db.GetUser(ctx, userId)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// weights are the scope's scoring profile weights
+func (s LeaderboardScope) weights() scoringWeights {
+	if weights, ok := scoringProfiles[s.Profile]; ok {
+		return weights
+	}
+	return scoringProfiles[models.ScoringProfileStandard]
+}
+
+// weight is how much a prediction counts; unweighted rounds count once
+func (s LeaderboardScope) weight(prediction models.Prediction) float32 {
+	if weight, ok := s.RoundWeights[prediction.GameType]; ok && weight > 0 {
+		return weight
+	}
+	return 1
+}
+
 // CalculateLeaderboard recalculates the leaderboard based on user scores.
-func (db *DB) CalculateLeaderboard(ctx context.Context) ([]models.LeaderboardEntry, error) {
-	users, err := db.ListUsers(ctx)
+func (db *DB) CalculateLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return calculateLeaderboard(ctx, db, scope)
+}
+
+// GetUserStats retrieves statistics for a specific user
+func (db *DB) GetUserStats(ctx context.Context, userId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
+	return getUserStats(ctx, db, userId, scope)
+}
+
+// CalculateModelLeaderboard ranks every uploaded model on the predictions attributed to it
+func (db *DB) CalculateModelLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return calculateModelLeaderboard(ctx, db, scope)
+}
+
+// GetModelStats retrieves statistics for a specific model
+func (db *DB) GetModelStats(ctx context.Context, modelId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
+	return getModelStats(ctx, db, modelId, scope)
+}
+
+// calculateLeaderboard ranks users on their own picks; picks made by their models are ranked
+// separately by calculateModelLeaderboard
+func calculateLeaderboard(ctx context.Context, db scoringSource, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	users, err := scope.users(ctx, db)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -23,15 +192,17 @@ func (db *DB) CalculateLeaderboard(ctx context.Context) ([]models.LeaderboardEnt
 		if err != nil {
 			return nil, fmt.Errorf("failed to get predictions for user %s: %w", user.Id, err)
 		}
+		predictions = scope.filter(ownPredictions(predictions))
 
-		winnerAccuracy, totalWinnersCorrect := calculateWinnerAccuracyAndTotalCorrectWinners(predictions)
-		totalScoreMse := calculateTotalScoreRmse(predictions)
-		teamScoreMse := calculateTeamScoreRmse(predictions)
-		leaderboardScore := getLeaderboardScore(predictions)
+		winnerAccuracy, totalWinnersCorrect := calculateWinnerAccuracyAndTotalCorrectWinners(predictions, scope)
+		totalScoreMse := calculateTotalScoreRmse(predictions, scope)
+		teamScoreMse := calculateTeamScoreRmse(predictions, scope)
+		leaderboardScore := getLeaderboardScore(predictions, scope)
 
 		leaderboard = append(leaderboard, models.LeaderboardEntry{
 			UserId:              user.Id,
 			Username:            user.Username,
+			Season:              scope.Season,
 			TotalWinnersCorrect: totalWinnersCorrect,
 			WinnerAccuracy:      winnerAccuracy,
 			TeamScoreMse:        totalScoreMse,
@@ -50,8 +221,7 @@ func (db *DB) CalculateLeaderboard(ctx context.Context) ([]models.LeaderboardEnt
 	return leaderboard, nil
 }
 
-// GetUserStats retrieves statistics for a specific user
-func (db *DB) GetUserStats(ctx context.Context, userId string) (*models.LeaderboardEntry, error) {
+func getUserStats(ctx context.Context, db scoringSource, userId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
 	user, err := db.GetUser(ctx, userId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -61,12 +231,13 @@ func (db *DB) GetUserStats(ctx context.Context, userId string) (*models.Leaderbo
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user predictions: %w", err)
 	}
+	predictions = scope.filter(ownPredictions(predictions))
 
-	winnerAccuracy, totalWinnersCorrect := calculateWinnerAccuracyAndTotalCorrectWinners(predictions)
-	totalScoreError := calculateTotalScoreRmse(predictions)
-	totalRunsError := calculateTeamScoreRmse(predictions)
+	winnerAccuracy, totalWinnersCorrect := calculateWinnerAccuracyAndTotalCorrectWinners(predictions, scope)
+	totalScoreError := calculateTotalScoreRmse(predictions, scope)
+	totalRunsError := calculateTeamScoreRmse(predictions, scope)
 
-	leaderboard, err := db.CalculateLeaderboard(ctx)
+	leaderboard, err := calculateLeaderboard(ctx, db, scope)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate leaderboard: %w", err)
 	}
@@ -82,6 +253,7 @@ func (db *DB) GetUserStats(ctx context.Context, userId string) (*models.Leaderbo
 	return &models.LeaderboardEntry{
 		UserId:              user.Id,
 		Username:            user.Username,
+		Season:              scope.Season,
 		TotalWinnersCorrect: totalWinnersCorrect,
 		WinnerAccuracy:      winnerAccuracy,
 		TeamScoreMse:        totalScoreError,
@@ -90,66 +262,184 @@ func (db *DB) GetUserStats(ctx context.Context, userId string) (*models.Leaderbo
 	}, nil
 }
 
-func calculateWinnerAccuracyAndTotalCorrectWinners(predictions []models.Prediction) (winnerAccuracy float32, totalWinnersCorrect int) {
-	var totalPredictions int
+func calculateModelLeaderboard(ctx context.Context, db scoringSource, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	users, err := scope.users(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	leaderboard := make([]models.LeaderboardEntry, 0)
+
+	for _, user := range users {
+		userModels, err := db.GetModelsByUserId(ctx, user.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get models for user %s: %w", user.Id, err)
+		}
+		if len(userModels) == 0 {
+			continue
+		}
+
+		predictions, err := db.GetUserPredictions(ctx, user.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get predictions for user %s: %w", user.Id, err)
+		}
+
+		byModel := make(map[string][]models.Prediction)
+		for _, prediction := range scope.filter(predictions) {
+			if prediction.ModelId != "" {
+				byModel[prediction.ModelId] = append(byModel[prediction.ModelId], prediction)
+			}
+		}
+
+		for _, model := range userModels {
+			leaderboard = append(leaderboard, modelLeaderboardEntry(user, model, byModel[model.ModelId], scope))
+		}
+	}
+
+	sortLeaderboardEntries(leaderboard)
+
+	for i := range leaderboard {
+		leaderboard[i].Rank = i + 1
+	}
+
+	return leaderboard, nil
+}
+
+func getModelStats(ctx context.Context, db scoringSource, modelId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
+	leaderboard, err := calculateModelLeaderboard(ctx, db, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate model leaderboard: %w", err)
+	}
+
+	var ranked *models.LeaderboardEntry
+	for i := range leaderboard {
+		if leaderboard[i].ModelId == modelId {
+			ranked = &leaderboard[i]
+			break
+		}
+	}
+	if ranked == nil {
+		return nil, ErrModelNotFound
+	}
+
+	predictions, err := db.GetModelPredictions(ctx, modelId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model predictions: %w", err)
+	}
+	predictions = scope.filter(predictions)
+
+	winnerAccuracy, totalWinnersCorrect := calculateWinnerAccuracyAndTotalCorrectWinners(predictions, scope)
+
+	return &models.LeaderboardEntry{
+		UserId:              ranked.UserId,
+		Username:            ranked.Username,
+		ModelId:             ranked.ModelId,
+		ModelName:           ranked.ModelName,
+		Season:              scope.Season,
+		TotalWinnersCorrect: totalWinnersCorrect,
+		WinnerAccuracy:      winnerAccuracy,
+		TeamScoreMse:        calculateTotalScoreRmse(predictions, scope),
+		TotalRunsMse:        calculateTeamScoreRmse(predictions, scope),
+		LeaderboardScore:    getLeaderboardScore(predictions, scope),
+		Rank:                ranked.Rank,
+	}, nil
+}
+
+func modelLeaderboardEntry(user *models.User, model *models.ModelMetadata, predictions []models.Prediction, scope LeaderboardScope) models.LeaderboardEntry {
+	winnerAccuracy, totalWinnersCorrect := calculateWinnerAccuracyAndTotalCorrectWinners(predictions, scope)
+
+	return models.LeaderboardEntry{
+		UserId:              user.Id,
+		Username:            user.Username,
+		ModelId:             model.ModelId,
+		ModelName:           model.ModelName,
+		Season:              scope.Season,
+		TotalWinnersCorrect: totalWinnersCorrect,
+		WinnerAccuracy:      winnerAccuracy,
+		TeamScoreMse:        calculateTotalScoreRmse(predictions, scope),
+		TotalRunsMse:        calculateTeamScoreRmse(predictions, scope),
+		LeaderboardScore:    getLeaderboardScore(predictions, scope),
+	}
+}
+
+// ownPredictions drops picks made by the user's models
+func ownPredictions(predictions []models.Prediction) []models.Prediction {
+	own := make([]models.Prediction, 0, len(predictions))
+	for _, prediction := range predictions {
+		if prediction.ModelId == "" {
+			own = append(own, prediction)
+		}
+	}
+	return own
+}
+
+// calculateWinnerAccuracyAndTotalCorrectWinners weights accuracy by the scope's round weights;
+// totalWinnersCorrect is always a plain count
+func calculateWinnerAccuracyAndTotalCorrectWinners(predictions []models.Prediction, scope LeaderboardScope) (winnerAccuracy float32, totalWinnersCorrect int) {
+	var totalWeight, correctWeight float32
 	for _, pred := range predictions {
 		if pred.WinnerCorrect != nil {
-			totalPredictions++
+			weight := scope.weight(pred)
+			totalWeight += weight
 			if *pred.WinnerCorrect {
 				totalWinnersCorrect++
+				correctWeight += weight
 			}
 		}
 	}
-	if totalPredictions > 0 {
-		winnerAccuracy = float32(totalWinnersCorrect) / float32(totalPredictions)
+	if totalWeight > 0 {
+		winnerAccuracy = correctWeight / totalWeight
 	}
 	return winnerAccuracy, totalWinnersCorrect
 }
 
-func calculateTeamScoreRmse(predictions []models.Prediction) float32 {
-	var totalPredictions int
+func calculateTeamScoreRmse(predictions []models.Prediction, scope LeaderboardScope) float32 {
+	var totalWeight float32
 	var sumSquaredErrors float32
 
 	for _, pred := range predictions {
 		if pred.WinnerCorrect == nil {
 			continue
 		}
-		sumSquaredErrors += (pred.HomeScoreError * pred.HomeScoreError) + (pred.AwayScoreError * pred.AwayScoreError)
-		totalPredictions++
+		weight := scope.weight(pred)
+		sumSquaredErrors += weight * ((pred.HomeScoreError * pred.HomeScoreError) + (pred.AwayScoreError * pred.AwayScoreError))
+		totalWeight += weight
 	}
-	if totalPredictions == 0 {
+	if totalWeight == 0 {
 		return 0
 	}
-	mse := float64(sumSquaredErrors / float32(2*totalPredictions))
+	mse := float64(sumSquaredErrors / (2 * totalWeight))
 	return float32(math.Sqrt(mse))
 }
 
-func calculateTotalScoreRmse(predictions []models.Prediction) float32 {
-	var totalPredictions int
+func calculateTotalScoreRmse(predictions []models.Prediction, scope LeaderboardScope) float32 {
+	var totalWeight float32
 	var sumSquaredErrors float32
 
 	for _, pred := range predictions {
 		if pred.WinnerCorrect == nil {
 			continue
 		}
-		sumSquaredErrors += pred.TotalScoreError * pred.TotalScoreError
-		totalPredictions++
+		weight := scope.weight(pred)
+		sumSquaredErrors += weight * pred.TotalScoreError * pred.TotalScoreError
+		totalWeight += weight
 	}
-	if totalPredictions == 0 {
+	if totalWeight == 0 {
 		return 0
 	}
-	mse := float64(sumSquaredErrors / float32(totalPredictions))
+	mse := float64(sumSquaredErrors / totalWeight)
 	return float32(math.Sqrt(mse))
 }
 
-func getLeaderboardScore(predictions []models.Prediction) (leaderboardScore float32) {
-	winnerAccuracyWeight := float32(0.6)
-	teamScoreMseWeight := float32(0.2)
-	totalScoreMseWeight := float32(0.2)
+func getLeaderboardScore(predictions []models.Prediction, scope LeaderboardScope) (leaderboardScore float32) {
+	weights := scope.weights()
+	winnerAccuracyWeight := weights.winnerAccuracy
+	teamScoreMseWeight := weights.teamScore
+	totalScoreMseWeight := weights.totalScore
 
-	winnerAccuracy, _ := calculateWinnerAccuracyAndTotalCorrectWinners(predictions)
-	teamScoreRmse := calculateTeamScoreRmse(predictions)
-	totalScoreRmse := calculateTotalScoreRmse(predictions)
+	winnerAccuracy, _ := calculateWinnerAccuracyAndTotalCorrectWinners(predictions, scope)
+	teamScoreRmse := calculateTeamScoreRmse(predictions, scope)
+	totalScoreRmse := calculateTotalScoreRmse(predictions, scope)
 
 	// Decay constants are set to guesstimated RMSE ranges:
 	// teamScoreRmse expected range: 3-10 runs per team
@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// seedStandings stores three users, two scored seasons and their picks:
+//   - u1 calls both 2025 regular season games exactly and misses the World Series game
+//   - u2 misses the first game, and calls the second and the World Series game
+//   - u3 only calls the 2024 game, which u2 misses
+//
+// u1's model misses the first game and u2's model calls the second.
+func seedStandings(t *testing.T, db Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, user := range []models.User{{Id: "u1", Username: "alice"}, {Id: "u2", Username: "bob"}, {Id: "u3", Username: "carol"}} {
+		if err := db.CreateUser(ctx, &user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, model := range []models.ModelMetadata{
+		{ModelId: "m1", UserId: "u1", ModelName: "coin-flip", Status: models.ModelStatusActive},
+		{ModelId: "m2", UserId: "u2", ModelName: "elo", Status: models.ModelStatusActive},
+	} {
+		if err := db.CreateModel(ctx, &model); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	games := []models.Game{
+		{GameId: "g1", Date: time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC), HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming},
+		{GameId: "g2", Date: time.Date(2025, 6, 2, 23, 0, 0, 0, time.UTC), HomeTeamId: "121", AwayTeamId: "139", Status: models.GameStatusUpcoming},
+		{GameId: "g3", Date: time.Date(2025, 10, 25, 0, 0, 0, 0, time.UTC), HomeTeamId: "111", AwayTeamId: "119", GameType: models.GameTypeWorldSeries, Status: models.GameStatusUpcoming},
+		{GameId: "g4", Date: time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC), HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming},
+	}
+	for _, game := range games {
+		if err := db.CreateGame(ctx, &game); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, prediction := range []models.Prediction{
+		{UserId: "u1", GameId: "g1", PredictedWinnerId: "111", HomeScorePredicted: 5, AwayScorePredicted: 3, TotalScorePredicted: 8},
+		{UserId: "u1", GameId: "g2", PredictedWinnerId: "139", HomeScorePredicted: 2, AwayScorePredicted: 4, TotalScorePredicted: 6},
+		{UserId: "u1", GameId: "g3", PredictedWinnerId: "119", HomeScorePredicted: 0, AwayScorePredicted: 1, TotalScorePredicted: 1},
+		{UserId: "u1", GameId: "g1", ModelId: "m1", PredictedWinnerId: "147", HomeScorePredicted: 3, AwayScorePredicted: 5, TotalScorePredicted: 8},
+		{UserId: "u2", GameId: "g1", PredictedWinnerId: "147", HomeScorePredicted: 3, AwayScorePredicted: 5, TotalScorePredicted: 8},
+		{UserId: "u2", GameId: "g2", PredictedWinnerId: "139", HomeScorePredicted: 3, AwayScorePredicted: 4, TotalScorePredicted: 7},
+		{UserId: "u2", GameId: "g3", PredictedWinnerId: "111", HomeScorePredicted: 1, AwayScorePredicted: 0, TotalScorePredicted: 1},
+		{UserId: "u2", GameId: "g4", PredictedWinnerId: "147", HomeScorePredicted: 2, AwayScorePredicted: 6, TotalScorePredicted: 8},
+		{UserId: "u2", GameId: "g2", ModelId: "m2", PredictedWinnerId: "139", HomeScorePredicted: 2, AwayScorePredicted: 4, TotalScorePredicted: 6},
+		{UserId: "u3", GameId: "g4", PredictedWinnerId: "111", HomeScorePredicted: 6, AwayScorePredicted: 2, TotalScorePredicted: 8},
+	} {
+		if err := db.CreatePrediction(ctx, &prediction); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, result := range []struct {
+		gameId     string
+		home, away int
+		winnerId   string
+	}{
+		{"g1", 5, 3, "111"},
+		{"g2", 2, 4, "139"},
+		{"g3", 1, 0, "111"},
+		{"g4", 6, 2, "111"},
+	} {
+		if err := db.CompleteGame(ctx, result.gameId, result.home, result.away, result.winnerId); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func rankedIds(entries []models.LeaderboardEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Rank != i+1 {
+			ids = append(ids, "misranked")
+			continue
+		}
+		if entry.ModelId != "" {
+			ids = append(ids, entry.ModelId)
+			continue
+		}
+		ids = append(ids, entry.UserId)
+	}
+	return ids
+}
+
+func approx(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestCompleteGameScoresPredictions(t *testing.T) {
+	ctx := context.Background()
+	db := NewMemoryDB()
+	seedStandings(t, db)
+
+	tests := []struct {
+		userId, gameId string
+		want           models.Prediction
+	}{
+		{"u1", "g1", models.Prediction{ActualWinnerId: "111", Season: 2025, GameType: models.GameTypeRegular}},
+		{"u2", "g1", models.Prediction{ActualWinnerId: "111", HomeScoreError: 2, AwayScoreError: 2, Season: 2025, GameType: models.GameTypeRegular}},
+		{"u2", "g2", models.Prediction{ActualWinnerId: "139", HomeScoreError: 1, TotalScoreError: 1, Season: 2025, GameType: models.GameTypeRegular}},
+		{"u1", "g3", models.Prediction{ActualWinnerId: "111", HomeScoreError: 1, AwayScoreError: 1, Season: 2025, GameType: models.GameTypeWorldSeries}},
+		{"u3", "g4", models.Prediction{ActualWinnerId: "111", Season: 2024, GameType: models.GameTypeRegular}},
+	}
+
+	for _, tt := range tests {
+		got, err := db.GetPredictionByUser(ctx, tt.userId, tt.gameId, "")
+		if err != nil || got == nil {
+			t.Fatalf("GetPredictionByUser(%s, %s) = %v, %v", tt.userId, tt.gameId, got, err)
+		}
+		if got.WinnerCorrect == nil {
+			t.Errorf("%s/%s: WinnerCorrect not set", tt.userId, tt.gameId)
+			continue
+		}
+		if wantCorrect := got.PredictedWinnerId == got.ActualWinnerId; *got.WinnerCorrect != wantCorrect {
+			t.Errorf("%s/%s: WinnerCorrect = %v, want %v", tt.userId, tt.gameId, *got.WinnerCorrect, wantCorrect)
+		}
+		if got.ActualWinnerId != tt.want.ActualWinnerId || got.Season != tt.want.Season || got.GameType != tt.want.GameType {
+			t.Errorf("%s/%s: winner, season, type = %s, %d, %s, want %s, %d, %s", tt.userId, tt.gameId,
+				got.ActualWinnerId, got.Season, got.GameType, tt.want.ActualWinnerId, tt.want.Season, tt.want.GameType)
+		}
+		if got.HomeScoreError != tt.want.HomeScoreError || got.AwayScoreError != tt.want.AwayScoreError || got.TotalScoreError != tt.want.TotalScoreError {
+			t.Errorf("%s/%s: errors = %v, %v, %v, want %v, %v, %v", tt.userId, tt.gameId,
+				got.HomeScoreError, got.AwayScoreError, got.TotalScoreError,
+				tt.want.HomeScoreError, tt.want.AwayScoreError, tt.want.TotalScoreError)
+		}
+	}
+}
+
+func TestCalculateLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	db := NewMemoryDB()
+	seedStandings(t, db)
+
+	tests := []struct {
+		name  string
+		scope LeaderboardScope
+		want  []string
+	}{
+		// Both call two of three; u1's scores are closer. u3 has no 2025 picks.
+		{name: "season", scope: LeaderboardScope{Season: 2025}, want: []string{"u1", "u2", "u3"}},
+		{name: "earlier season", scope: LeaderboardScope{Season: 2024}, want: []string{"u3", "u1", "u2"}},
+		{name: "regular season only", scope: LeaderboardScope{Season: 2025, GameTypes: []string{models.GameTypeRegular}}, want: []string{"u1", "u2", "u3"}},
+		// Weighting the World Series game puts u2's call of it ahead of u1's regular season
+		{name: "round weights", scope: LeaderboardScope{Season: 2025, RoundWeights: map[string]float32{models.GameTypeWorldSeries: 4}}, want: []string{"u2", "u1", "u3"}},
+		{name: "league members", scope: LeaderboardScope{Season: 2025, UserIds: []string{"u2", "u3", "deleted"}}, want: []string{"u2", "u3"}},
+		// A wrong pick scores below no pick at all
+		{name: "contest slate", scope: LeaderboardScope{GameIds: map[string]bool{"g3": true}}, want: []string{"u2", "u3", "u1"}},
+		// Equal accuracy falls back to total score error
+		{name: "winners profile", scope: LeaderboardScope{Season: 2025, Profile: models.ScoringProfileWinners}, want: []string{"u1", "u2", "u3"}},
+		{name: "scores profile", scope: LeaderboardScope{Season: 2025, Profile: models.ScoringProfileScores}, want: []string{"u1", "u3", "u2"}},
+	}
+
+	for _, tt := range tests {
+		leaderboard, err := db.CalculateLeaderboard(ctx, tt.scope)
+		if err != nil {
+			t.Fatalf("%s: CalculateLeaderboard() error = %v", tt.name, err)
+		}
+		if got := rankedIds(leaderboard); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: CalculateLeaderboard() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateLeaderboardEntry(t *testing.T) {
+	ctx := context.Background()
+	db := NewMemoryDB()
+	seedStandings(t, db)
+
+	leaderboard, err := db.CalculateLeaderboard(ctx, LeaderboardScope{Season: 2025})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := leaderboard[0]
+
+	// u1's model missed g1, but only u1's own picks count
+	if got.UserId != "u1" || got.Username != "alice" || got.Season != 2025 || got.ModelId != "" {
+		t.Errorf("leader = %+v, want alice's own picks in 2025", got)
+	}
+	if got.TotalWinnersCorrect != 2 || !approx(got.WinnerAccuracy, 2.0/3) {
+		t.Errorf("winners = %d (%v), want 2 (0.667)", got.TotalWinnersCorrect, got.WinnerAccuracy)
+	}
+
+	// Only g3 missed, by a run each side and none in total
+	teamScoreRmse := float32(math.Sqrt(2.0 / 6))
+	if !approx(got.TotalRunsMse, teamScoreRmse) || got.TeamScoreMse != 0 {
+		t.Errorf("errors = %v, %v, want %v, 0", got.TotalRunsMse, got.TeamScoreMse, teamScoreRmse)
+	}
+	wantScore := 0.6*(2.0/3) + 0.2*float32(math.Exp(float64(-teamScoreRmse)/5)) + 0.2
+	if !approx(got.LeaderboardScore, wantScore) {
+		t.Errorf("LeaderboardScore = %v, want %v", got.LeaderboardScore, wantScore)
+	}
+
+	stats, err := db.GetUserStats(ctx, "u2", LeaderboardScope{Season: 2025})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Rank != 2 || stats.TotalWinnersCorrect != 2 {
+		t.Errorf("GetUserStats(u2) rank, correct = %d, %d, want 2, 2", stats.Rank, stats.TotalWinnersCorrect)
+	}
+}
+
+func TestLeaderboardScoreProfiles(t *testing.T) {
+	correct, wrong := true, false
+	exact := []models.Prediction{{WinnerCorrect: &correct}, {WinnerCorrect: &correct}}
+	wrongWinners := []models.Prediction{{WinnerCorrect: &wrong}, {WinnerCorrect: &wrong}}
+
+	tests := []struct {
+		profile     string
+		predictions []models.Prediction
+		want        float32
+	}{
+		{models.ScoringProfileStandard, exact, 1},
+		{models.ScoringProfileWinners, exact, 1},
+		{models.ScoringProfileScores, exact, 1},
+		{models.ScoringProfileStandard, wrongWinners, 0.4},
+		{models.ScoringProfileWinners, wrongWinners, 0},
+		{models.ScoringProfileScores, wrongWinners, 0.8},
+		// An unknown profile scores as standard
+		{"unknown", wrongWinners, 0.4},
+	}
+
+	for _, tt := range tests {
+		if got := getLeaderboardScore(tt.predictions, LeaderboardScope{Profile: tt.profile}); !approx(got, tt.want) {
+			t.Errorf("getLeaderboardScore(%s) = %v, want %v", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateModelLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	db := NewMemoryDB()
+	seedStandings(t, db)
+	scope := LeaderboardScope{Season: 2025}
+
+	leaderboard, err := db.CalculateModelLeaderboard(ctx, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rankedIds(leaderboard); !slices.Equal(got, []string{"m2", "m1"}) {
+		t.Fatalf("CalculateModelLeaderboard() = %v, want [m2 m1]", got)
+	}
+	if leaderboard[0].UserId != "u2" || leaderboard[0].ModelName != "elo" || leaderboard[0].TotalWinnersCorrect != 1 {
+		t.Errorf("leader = %+v, want u2's elo with one winner", leaderboard[0])
+	}
+
+	stats, err := db.GetModelStats(ctx, "m1", scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Rank != 2 || stats.TotalWinnersCorrect != 0 || stats.WinnerAccuracy != 0 {
+		t.Errorf("GetModelStats(m1) = %+v, want rank 2 without a winner", stats)
+	}
+
+	if _, err := db.GetModelStats(ctx, "missing", scope); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("GetModelStats(missing) error = %v, want ErrModelNotFound", err)
+	}
+}
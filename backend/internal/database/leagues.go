@@ -0,0 +1,273 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+var (
+	ErrLeagueNotFound       = errors.New("league not found")
+	ErrLeagueMemberNotFound = errors.New("league member not found")
+	ErrAlreadyLeagueMember  = errors.New("already a member of this league")
+)
+
+// League tables GSIs
+const (
+	inviteCodeIndex   = "InviteCodeIndex" // inviteCode, on the leagues table
+	memberUserIdIndex = "UserIdIndex"     // userId + leagueId, on the league members table
+)
+
+// CreateLeague stores a new league and makes its owner the first member, in one transaction
+func (db *DB) CreateLeague(ctx context.Context, league *models.League) error {
+	league.CreatedAt = time.Now()
+
+	leagueItem, err := attributevalue.MarshalMap(league)
+	if err != nil {
+		return fmt.Errorf("failed to marshal league: %w", err)
+	}
+
+	memberItem, err := attributevalue.MarshalMap(ownerMember(league))
+	if err != nil {
+		return fmt.Errorf("failed to marshal league member: %w", err)
+	}
+
+	_, err = db.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           aws.String(db.leaguesTable),
+				Item:                leagueItem,
+				ConditionExpression: aws.String("attribute_not_exists(leagueId)"),
+			}},
+			{Put: &types.Put{
+				TableName: aws.String(db.membersTable),
+				Item:      memberItem,
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create league: %w", err)
+	}
+
+	return nil
+}
+
+// ownerMember is the membership the owner of a new league starts with
+func ownerMember(league *models.League) models.LeagueMember {
+	return models.LeagueMember{
+		LeagueId: league.LeagueId,
+		UserId:   league.OwnerId,
+		Role:     models.LeagueRoleOwner,
+		JoinedAt: league.CreatedAt,
+	}
+}
+
+func (db *DB) GetLeague(ctx context.Context, leagueId string) (*models.League, error) {
+	result, err := db.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(db.leaguesTable),
+		Key: map[string]types.AttributeValue{
+			"leagueId": &types.AttributeValueMemberS{Value: leagueId},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrLeagueNotFound
+	}
+
+	var league models.League
+	if err := attributevalue.UnmarshalMap(result.Item, &league); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal league: %w", err)
+	}
+
+	return &league, nil
+}
+
+func (db *DB) GetLeagueByInviteCode(ctx context.Context, inviteCode string) (*models.League, error) {
+	items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.leaguesTable),
+		IndexName:              aws.String(inviteCodeIndex),
+		KeyConditionExpression: aws.String("inviteCode = :inviteCode"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":inviteCode": &types.AttributeValueMemberS{Value: inviteCode},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leagues by invite code: %w", err)
+	}
+
+	if len(items) == 0 {
+		return nil, ErrLeagueNotFound
+	}
+
+	var league models.League
+	if err := attributevalue.UnmarshalMap(items[0], &league); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal league: %w", err)
+	}
+
+	return &league, nil
+}
+
+// UpdateLeague replaces an existing league's name, invite code and settings
+func (db *DB) UpdateLeague(ctx context.Context, league *models.League) error {
+	item, err := attributevalue.MarshalMap(league)
+	if err != nil {
+		return fmt.Errorf("failed to marshal league: %w", err)
+	}
+
+	_, err = db.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(db.leaguesTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(leagueId)"),
+	})
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return ErrLeagueNotFound
+		}
+		return fmt.Errorf("failed to update league: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserLeagues retrieves every league userId is a member of
+func (db *DB) GetUserLeagues(ctx context.Context, userId string) ([]models.League, error) {
+	items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.membersTable),
+		IndexName:              aws.String(memberUserIdIndex),
+		KeyConditionExpression: aws.String("userId = :userId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userId},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user leagues: %w", err)
+	}
+
+	members, err := unmarshalLeagueMembers(items)
+	if err != nil {
+		return nil, err
+	}
+
+	leagues := make([]models.League, 0, len(members))
+	for _, member := range members {
+		league, err := db.GetLeague(ctx, member.LeagueId)
+		if err != nil {
+			if errors.Is(err, ErrLeagueNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		leagues = append(leagues, *league)
+	}
+
+	return leagues, nil
+}
+
+// AddLeagueMember adds a user to a league, failing if they are already a member
+func (db *DB) AddLeagueMember(ctx context.Context, member *models.LeagueMember) error {
+	member.JoinedAt = time.Now()
+
+	item, err := attributevalue.MarshalMap(member)
+	if err != nil {
+		return fmt.Errorf("failed to marshal league member: %w", err)
+	}
+
+	_, err = db.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(db.membersTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(userId)"),
+	})
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return ErrAlreadyLeagueMember
+		}
+		return fmt.Errorf("failed to add league member: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetLeagueMember(ctx context.Context, leagueId, userId string) (*models.LeagueMember, error) {
+	result, err := db.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(db.membersTable),
+		Key:       leagueMemberItemKey(leagueId, userId),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get league member: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrLeagueMemberNotFound
+	}
+
+	var member models.LeagueMember
+	if err := attributevalue.UnmarshalMap(result.Item, &member); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal league member: %w", err)
+	}
+
+	return &member, nil
+}
+
+func (db *DB) GetLeagueMembers(ctx context.Context, leagueId string) ([]models.LeagueMember, error) {
+	items, err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(db.membersTable),
+		KeyConditionExpression: aws.String("leagueId = :leagueId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":leagueId": &types.AttributeValueMemberS{Value: leagueId},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query league members: %w", err)
+	}
+
+	return unmarshalLeagueMembers(items)
+}
+
+func (db *DB) RemoveLeagueMember(ctx context.Context, leagueId, userId string) error {
+	_, err := db.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(db.membersTable),
+		Key:                 leagueMemberItemKey(leagueId, userId),
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+	})
+	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return ErrLeagueMemberNotFound
+		}
+		return fmt.Errorf("failed to remove league member: %w", err)
+	}
+
+	return nil
+}
+
+func leagueMemberItemKey(leagueId, userId string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"leagueId": &types.AttributeValueMemberS{Value: leagueId},
+		"userId":   &types.AttributeValueMemberS{Value: userId},
+	}
+}
+
+func unmarshalLeagueMembers(items []item) ([]models.LeagueMember, error) {
+	members := make([]models.LeagueMember, 0, len(items))
+	for _, item := range items {
+		var member models.LeagueMember
+		if err := attributevalue.UnmarshalMap(item, &member); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal league member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
@@ -0,0 +1,803 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+type leagueMemberKey struct {
+	leagueId string
+	userId   string
+}
+
+type contestEntryKey struct {
+	contestId string
+	userId    string
+}
+
+type modelUploadKey struct {
+	userId string
+	day    string
+}
+
+type predictionKey struct {
+	userId  string
+	gameId  string
+	modelId string
+}
+
+// MemoryDB is an in-memory Store for tests and local development.
+// It mirrors the DynamoDB implementation's key schema and conditional writes,
+// and hands out copies so callers can't mutate stored items.
+type MemoryDB struct {
+	mu          sync.RWMutex
+	users       map[string]models.User
+	games       map[string]models.Game
+	teams       map[string]models.Team
+	seasons     map[int]models.Season
+	leagues     map[string]models.League
+	members     map[leagueMemberKey]models.LeagueMember
+	contests    map[string]models.Contest
+	entries     map[contestEntryKey]models.ContestEntry
+	predictions map[predictionKey]models.Prediction
+	models      map[string]models.ModelMetadata
+	uploads     map[modelUploadKey]int
+}
+
+// NewMemoryDB creates an empty in-memory store
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		users:       make(map[string]models.User),
+		games:       make(map[string]models.Game),
+		teams:       make(map[string]models.Team),
+		seasons:     make(map[int]models.Season),
+		leagues:     make(map[string]models.League),
+		members:     make(map[leagueMemberKey]models.LeagueMember),
+		contests:    make(map[string]models.Contest),
+		entries:     make(map[contestEntryKey]models.ContestEntry),
+		predictions: make(map[predictionKey]models.Prediction),
+		uploads:     make(map[modelUploadKey]int),
+		models:      make(map[string]models.ModelMetadata),
+	}
+}
+
+func (m *MemoryDB) Close() error {
+	return nil
+}
+
+func (m *MemoryDB) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// CreateUser adds a new user, failing if the userId is already taken
+func (m *MemoryDB) CreateUser(ctx context.Context, user *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.users[user.Id]; exists {
+		return ErrUserAlreadyExists
+	}
+
+	user.CreatedAt = time.Now()
+	m.users[user.Id] = *user
+	return nil
+}
+
+func (m *MemoryDB) GetUser(ctx context.Context, userId string) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[userId]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (m *MemoryDB) GetUsersByIds(ctx context.Context, userIds []string) ([]*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(userIds))
+	for userId, user := range m.users {
+		if slices.Contains(userIds, userId) {
+			user := user
+			users = append(users, &user)
+		}
+	}
+	sortUsers(users)
+	return users, nil
+}
+
+func (m *MemoryDB) ListUsers(ctx context.Context) ([]*models.User, error) {
+	users, _, err := m.ListUsersPage(ctx, PageRequest{})
+	return users, err
+}
+
+func (m *MemoryDB) ListUsersPage(ctx context.Context, page PageRequest) ([]*models.User, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(m.users))
+	for _, user := range m.users {
+		user := user
+		users = append(users, &user)
+	}
+
+	return pageSlice(users, func(u *models.User) string { return u.Id }, page)
+}
+
+// CreateGame stores a game, replacing any existing game with the same ID
+func (m *MemoryDB) CreateGame(ctx context.Context, game *models.Game) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setGameDefaults(game)
+	m.games[game.GameId] = *game
+	return nil
+}
+
+func (m *MemoryDB) GetGame(ctx context.Context, gameID string) (*models.Game, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	game, ok := m.games[gameID]
+	if !ok {
+		return nil, ErrGameNotFound
+	}
+	return &game, nil
+}
+
+func (m *MemoryDB) GetGamesByIds(ctx context.Context, gameIds []string) ([]models.Game, error) {
+	return m.filterGames(func(game models.Game) bool { return slices.Contains(gameIds, game.GameId) }), nil
+}
+
+func (m *MemoryDB) GetUpcomingGames(ctx context.Context) ([]models.Game, error) {
+	return m.filterGames(func(game models.Game) bool { return game.Status == "upcoming" }), nil
+}
+
+func (m *MemoryDB) GetGamesByDate(ctx context.Context, day string) ([]models.Game, error) {
+	return m.filterGames(func(game models.Game) bool { return game.GameDay == day }), nil
+}
+
+func (m *MemoryDB) GetGamesByDateRange(ctx context.Context, from, to string) ([]models.Game, error) {
+	if _, err := gameDaysBetween(from, to); err != nil {
+		return nil, err
+	}
+	return m.filterGames(func(game models.Game) bool { return game.GameDay >= from && game.GameDay <= to }), nil
+}
+
+func (m *MemoryDB) GetGamesByTeam(ctx context.Context, teamId, from, to string) ([]models.Game, error) {
+	return m.filterGames(func(game models.Game) bool {
+		if game.HomeTeamId != teamId && game.AwayTeamId != teamId {
+			return false
+		}
+		return (from == "" || game.GameDay >= from) && (to == "" || game.GameDay <= to)
+	}), nil
+}
+
+func (m *MemoryDB) filterGames(match func(game models.Game) bool) []models.Game {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	games := make([]models.Game, 0)
+	for _, game := range m.games {
+		if match(game) {
+			games = append(games, game)
+		}
+	}
+	sortGamesByDate(games)
+
+	return games
+}
+
+// UpdateGameResult behaves like DynamoDB UpdateItem and creates the game if it is missing
+func (m *MemoryDB) UpdateGameResult(ctx context.Context, gameID, winner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game := m.games[gameID]
+	game.GameId = gameID
+	game.Status = "completed"
+	game.Winner = winner
+	m.games[gameID] = game
+	return nil
+}
+
+// CompleteGame marks a game as completed and scores every prediction for it
+func (m *MemoryDB) CompleteGame(ctx context.Context, gameId string, homeScore int, awayScore int, winnerId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game := m.games[gameId]
+	game.GameId = gameId
+	game.Status = "completed"
+	game.HomeScore = homeScore
+	game.AwayScore = awayScore
+	game.Winner = winnerId
+	m.games[gameId] = game
+
+	for key, prediction := range m.predictions {
+		if key.gameId != gameId {
+			continue
+		}
+		scorePrediction(&prediction, &game)
+		m.predictions[key] = prediction
+	}
+
+	return nil
+}
+
+// PutTeams stores teams, replacing any existing team with the same ID
+func (m *MemoryDB) PutTeams(ctx context.Context, teams []models.Team) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, team := range teams {
+		m.teams[team.Id] = team
+	}
+	return nil
+}
+
+func (m *MemoryDB) GetTeam(ctx context.Context, teamId string) (*models.Team, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	team, ok := m.teams[teamId]
+	if !ok {
+		return nil, ErrTeamNotFound
+	}
+	return &team, nil
+}
+
+func (m *MemoryDB) ListTeams(ctx context.Context) ([]models.Team, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	teams := make([]models.Team, 0, len(m.teams))
+	for _, team := range m.teams {
+		teams = append(teams, team)
+	}
+	sortTeams(teams)
+
+	return teams, nil
+}
+
+// PutSeason stores a season, replacing any existing season for the same year
+func (m *MemoryDB) PutSeason(ctx context.Context, season *models.Season) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seasons[season.Year] = *season
+	return nil
+}
+
+func (m *MemoryDB) GetSeason(ctx context.Context, year int) (*models.Season, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	season, ok := m.seasons[year]
+	if !ok {
+		return nil, ErrSeasonNotFound
+	}
+	return &season, nil
+}
+
+func (m *MemoryDB) ListSeasons(ctx context.Context) ([]models.Season, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seasons := make([]models.Season, 0, len(m.seasons))
+	for _, season := range m.seasons {
+		seasons = append(seasons, season)
+	}
+	sortSeasons(seasons)
+
+	return seasons, nil
+}
+
+// CreateLeague stores a new league and makes its owner the first member
+func (m *MemoryDB) CreateLeague(ctx context.Context, league *models.League) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.leagues[league.LeagueId]; exists {
+		return fmt.Errorf("league %s already exists", league.LeagueId)
+	}
+
+	league.CreatedAt = time.Now()
+	m.putLeague(*league)
+
+	owner := ownerMember(league)
+	m.members[leagueMemberKey{owner.LeagueId, owner.UserId}] = owner
+	return nil
+}
+
+// putLeague stores a copy of league, so the caller's settings slice isn't shared
+func (m *MemoryDB) putLeague(league models.League) {
+	league.Settings.GameTypes = slices.Clone(league.Settings.GameTypes)
+	m.leagues[league.LeagueId] = league
+}
+
+func (m *MemoryDB) GetLeague(ctx context.Context, leagueId string) (*models.League, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	league, ok := m.leagues[leagueId]
+	if !ok {
+		return nil, ErrLeagueNotFound
+	}
+	return &league, nil
+}
+
+func (m *MemoryDB) GetLeagueByInviteCode(ctx context.Context, inviteCode string) (*models.League, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, league := range m.leagues {
+		if league.InviteCode == inviteCode {
+			return &league, nil
+		}
+	}
+	return nil, ErrLeagueNotFound
+}
+
+func (m *MemoryDB) UpdateLeague(ctx context.Context, league *models.League) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.leagues[league.LeagueId]; !exists {
+		return ErrLeagueNotFound
+	}
+	m.putLeague(*league)
+	return nil
+}
+
+func (m *MemoryDB) GetUserLeagues(ctx context.Context, userId string) ([]models.League, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	leagues := make([]models.League, 0)
+	for key := range m.members {
+		if league, ok := m.leagues[key.leagueId]; ok && key.userId == userId {
+			leagues = append(leagues, league)
+		}
+	}
+	sort.Slice(leagues, func(i, j int) bool { return leagues[i].LeagueId < leagues[j].LeagueId })
+
+	return leagues, nil
+}
+
+// AddLeagueMember adds a user to a league, failing if they are already a member
+func (m *MemoryDB) AddLeagueMember(ctx context.Context, member *models.LeagueMember) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := leagueMemberKey{member.LeagueId, member.UserId}
+	if _, exists := m.members[key]; exists {
+		return ErrAlreadyLeagueMember
+	}
+
+	member.JoinedAt = time.Now()
+	m.members[key] = *member
+	return nil
+}
+
+func (m *MemoryDB) GetLeagueMember(ctx context.Context, leagueId, userId string) (*models.LeagueMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	member, ok := m.members[leagueMemberKey{leagueId, userId}]
+	if !ok {
+		return nil, ErrLeagueMemberNotFound
+	}
+	return &member, nil
+}
+
+func (m *MemoryDB) GetLeagueMembers(ctx context.Context, leagueId string) ([]models.LeagueMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	members := make([]models.LeagueMember, 0)
+	for key, member := range m.members {
+		if key.leagueId == leagueId {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserId < members[j].UserId })
+
+	return members, nil
+}
+
+func (m *MemoryDB) RemoveLeagueMember(ctx context.Context, leagueId, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := leagueMemberKey{leagueId, userId}
+	if _, exists := m.members[key]; !exists {
+		return ErrLeagueMemberNotFound
+	}
+	delete(m.members, key)
+	return nil
+}
+
+// CreateContest stores a new contest
+func (m *MemoryDB) CreateContest(ctx context.Context, contest *models.Contest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.contests[contest.ContestId]; exists {
+		return fmt.Errorf("contest %s already exists", contest.ContestId)
+	}
+
+	contest.CreatedAt = time.Now()
+	stored := *contest
+	stored.Rules.GameIds = slices.Clone(contest.Rules.GameIds)
+	stored.Rules.TeamIds = slices.Clone(contest.Rules.TeamIds)
+	stored.Rules.GameTypes = slices.Clone(contest.Rules.GameTypes)
+	m.contests[contest.ContestId] = stored
+	return nil
+}
+
+func (m *MemoryDB) GetContest(ctx context.Context, contestId string) (*models.Contest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	contest, ok := m.contests[contestId]
+	if !ok {
+		return nil, ErrContestNotFound
+	}
+	return &contest, nil
+}
+
+func (m *MemoryDB) GetLeagueContests(ctx context.Context, leagueId string) ([]models.Contest, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	contests := make([]models.Contest, 0)
+	for _, contest := range m.contests {
+		if contest.LeagueId == leagueId {
+			contests = append(contests, contest)
+		}
+	}
+	sortContests(contests)
+
+	return contests, nil
+}
+
+// EnterContest records a user's entry, failing if they have already entered
+func (m *MemoryDB) EnterContest(ctx context.Context, entry *models.ContestEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := contestEntryKey{entry.ContestId, entry.UserId}
+	if _, exists := m.entries[key]; exists {
+		return ErrAlreadyEnteredContest
+	}
+
+	entry.EnteredAt = time.Now()
+	m.entries[key] = *entry
+	return nil
+}
+
+func (m *MemoryDB) GetContestEntries(ctx context.Context, contestId string) ([]models.ContestEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := make([]models.ContestEntry, 0)
+	for key, entry := range m.entries {
+		if key.contestId == contestId {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].UserId < entries[j].UserId })
+
+	return entries, nil
+}
+
+// CreatePrediction stores a prediction, replacing any earlier one for the same user, game and model
+func (m *MemoryDB) CreatePrediction(ctx context.Context, prediction *models.Prediction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prediction.SubmittedAt = time.Now()
+	prediction.PredictionKey = PredictionSortKey(prediction.GameId, prediction.ModelId)
+	m.predictions[predictionKey{prediction.UserId, prediction.GameId, prediction.ModelId}] = *prediction
+	return nil
+}
+
+func (m *MemoryDB) BatchCreatePredictions(ctx context.Context, predictions []models.Prediction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, prediction := range predictions {
+		prediction.SubmittedAt = now
+		prediction.PredictionKey = PredictionSortKey(prediction.GameId, prediction.ModelId)
+		m.predictions[predictionKey{prediction.UserId, prediction.GameId, prediction.ModelId}] = prediction
+	}
+	return nil
+}
+
+// GetPredictionByUser returns nil without an error when the prediction doesn't exist
+func (m *MemoryDB) GetPredictionByUser(ctx context.Context, userId, gameId, modelId string) (*models.Prediction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prediction, ok := m.predictions[predictionKey{userId, gameId, modelId}]
+	if !ok {
+		return nil, nil
+	}
+	return &prediction, nil
+}
+
+func (m *MemoryDB) GetUserPredictions(ctx context.Context, userId string) ([]models.Prediction, error) {
+	predictions, _, err := m.GetUserPredictionsPage(ctx, userId, PageRequest{})
+	return predictions, err
+}
+
+func (m *MemoryDB) GetUserPredictionsPage(ctx context.Context, userId string, page PageRequest) ([]models.Prediction, string, error) {
+	predictions := m.filterPredictions(func(key predictionKey) bool { return key.userId == userId })
+	return pageSlice(predictions, func(p models.Prediction) string { return p.PredictionKey }, page)
+}
+
+func (m *MemoryDB) GetPredictionsByGame(ctx context.Context, gameId string) ([]models.Prediction, error) {
+	predictions, _, err := m.GetPredictionsByGamePage(ctx, gameId, PageRequest{})
+	return predictions, err
+}
+
+func (m *MemoryDB) GetPredictionsByGamePage(ctx context.Context, gameId string, page PageRequest) ([]models.Prediction, string, error) {
+	predictions := m.filterPredictions(func(key predictionKey) bool { return key.gameId == gameId })
+	return pageSlice(predictions, func(p models.Prediction) string { return p.UserId + "#" + p.ModelId }, page)
+}
+
+func (m *MemoryDB) GetPredictionsByGames(ctx context.Context, gameIds []string) (map[string][]models.Prediction, error) {
+	wanted := make(map[string]bool, len(gameIds))
+	for _, gameId := range gameIds {
+		wanted[gameId] = true
+	}
+
+	results := make(map[string][]models.Prediction, len(gameIds))
+	for _, prediction := range m.filterPredictions(func(key predictionKey) bool { return wanted[key.gameId] }) {
+		results[prediction.GameId] = append(results[prediction.GameId], prediction)
+	}
+
+	return results, nil
+}
+
+func (m *MemoryDB) GetModelPredictions(ctx context.Context, modelId string) ([]models.Prediction, error) {
+	predictions := m.filterPredictions(func(key predictionKey) bool { return key.modelId == modelId })
+	sort.Slice(predictions, func(i, j int) bool { return predictions[i].GameId < predictions[j].GameId })
+	return predictions, nil
+}
+
+func (m *MemoryDB) filterPredictions(match func(key predictionKey) bool) []models.Prediction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	predictions := make([]models.Prediction, 0)
+	for key, prediction := range m.predictions {
+		if match(key) {
+			predictions = append(predictions, prediction)
+		}
+	}
+	return predictions
+}
+
+func (m *MemoryDB) CreateModel(ctx context.Context, model *models.ModelMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.models {
+		if existing.UserId == model.UserId && existing.ModelName == model.ModelName && existing.Version == model.Version {
+			return ErrModelVersionTaken
+		}
+	}
+
+	model.CreatedAt = time.Now()
+	model.UpdatedAt = time.Now()
+	m.models[model.ModelId] = *model
+	return nil
+}
+
+func (m *MemoryDB) GetModelById(ctx context.Context, modelId string, userId string) (*models.ModelMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	model, ok := m.models[modelId]
+	if !ok || model.UserId != userId {
+		return nil, ErrModelNotFound
+	}
+	return &model, nil
+}
+
+func (m *MemoryDB) GetModelsByUserId(ctx context.Context, userId string) ([]*models.ModelMetadata, error) {
+	modelList, _, err := m.GetModelsByUserIdPage(ctx, userId, PageRequest{})
+	return modelList, err
+}
+
+func (m *MemoryDB) GetModelsByUserIdPage(ctx context.Context, userId string, page PageRequest) ([]*models.ModelMetadata, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	modelList := make([]*models.ModelMetadata, 0)
+	for _, model := range m.models {
+		if model.UserId == userId {
+			model := model
+			modelList = append(modelList, &model)
+		}
+	}
+
+	return pageSlice(modelList, func(model *models.ModelMetadata) string { return model.ModelId }, page)
+}
+
+func (m *MemoryDB) GetModelsByStatus(ctx context.Context, status string) ([]*models.ModelMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	modelList := make([]*models.ModelMetadata, 0)
+	for _, model := range m.models {
+		if model.Status == status {
+			model := model
+			modelList = append(modelList, &model)
+		}
+	}
+	sort.Slice(modelList, func(i, j int) bool { return modelList[i].ModelId < modelList[j].ModelId })
+
+	return modelList, nil
+}
+
+// DeleteModel removes a model, failing like the DynamoDB condition if the user doesn't own it
+func (m *MemoryDB) DeleteModel(ctx context.Context, modelId string, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	model, ok := m.models[modelId]
+	if !ok || model.UserId != userId {
+		return ErrModelNotFound
+	}
+	delete(m.models, modelId)
+	return nil
+}
+
+func (m *MemoryDB) UpdateModel(ctx context.Context, model *models.ModelMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.models[model.ModelId]
+	if !ok || existing.UserId != model.UserId {
+		return ErrModelNotFound
+	}
+
+	model.CreatedAt = existing.CreatedAt
+	model.UpdatedAt = time.Now()
+	m.models[model.ModelId] = *model
+	return nil
+}
+
+func (m *MemoryDB) UpdateModelStatus(ctx context.Context, modelId string, userId string, status string, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	model, ok := m.models[modelId]
+	if !ok || model.UserId != userId {
+		return ErrModelNotFound
+	}
+	model.Status = status
+	model.StatusReason = reason
+	model.UpdatedAt = time.Now()
+	m.models[modelId] = model
+	return nil
+}
+
+func (m *MemoryDB) GetModelVersions(ctx context.Context, userId string, modelName string) ([]*models.ModelMetadata, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.modelVersions(userId, modelName), nil
+}
+
+func (m *MemoryDB) modelVersions(userId string, modelName string) []*models.ModelMetadata {
+	versions := make([]*models.ModelMetadata, 0)
+	for _, model := range m.models {
+		if model.UserId == userId && model.ModelName == modelName {
+			model := model
+			versions = append(versions, &model)
+		}
+	}
+
+	sortModelVersions(versions)
+	return versions
+}
+
+func (m *MemoryDB) PromoteModel(ctx context.Context, modelId string, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	target, ok := m.models[modelId]
+	if !ok || target.UserId != userId {
+		return ErrModelNotFound
+	}
+
+	demoted, err := promotionPlan(&target, m.modelVersions(userId, target.ModelName))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, model := range demoted {
+		model.Status = models.ModelStatusValidated
+		model.UpdatedAt = now
+		m.models[model.ModelId] = *model
+	}
+
+	target.Status = models.ModelStatusActive
+	target.StatusReason = ""
+	target.PromotedAt = &now
+	target.UpdatedAt = now
+	m.models[modelId] = target
+	return nil
+}
+
+func (m *MemoryDB) RecordModelUpload(ctx context.Context, userId string, day string, limit int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := modelUploadKey{userId, day}
+	if limit > 0 && m.uploads[key] >= limit {
+		return ErrUploadLimitReached
+	}
+	m.uploads[key]++
+	return nil
+}
+
+func (m *MemoryDB) GetModelUploadCount(ctx context.Context, userId string, day string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.uploads[modelUploadKey{userId, day}], nil
+}
+
+func (m *MemoryDB) CalculateLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return calculateLeaderboard(ctx, m, scope)
+}
+
+func (m *MemoryDB) GetUserStats(ctx context.Context, userId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
+	return getUserStats(ctx, m, userId, scope)
+}
+
+func (m *MemoryDB) CalculateModelLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return calculateModelLeaderboard(ctx, m, scope)
+}
+
+func (m *MemoryDB) GetModelStats(ctx context.Context, modelId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
+	return getModelStats(ctx, m, modelId, scope)
+}
+
+// pageSlice orders items by key and returns the page after the cursor.
+// A zero Limit returns everything, which is how the non-paged reads share this path.
+func pageSlice[T any](items []T, key func(T) string, page PageRequest) ([]T, string, error) {
+	sort.Slice(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+
+	if page.Cursor != "" {
+		after, err := decodeKeyCursor(page.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := sort.Search(len(items), func(i int) bool { return key(items[i]) > after })
+		items = items[start:]
+	}
+
+	if page.Limit <= 0 || int(page.Limit) >= len(items) {
+		return items, "", nil
+	}
+
+	items = items[:page.Limit]
+	return items, encodeKeyCursor(key(items[len(items)-1])), nil
+}
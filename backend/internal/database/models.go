@@ -2,7 +2,10 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,7 +15,15 @@ import (
 	"github.com/bendemouth/mlb-prediction-pool/internal/models"
 )
 
-// CreateModel adds a new model to the Models table
+var (
+	ErrModelNotFound      = errors.New("model not found")
+	ErrModelNotPromotable = errors.New("only validated model versions can be promoted")
+	ErrUploadLimitReached = errors.New("daily upload limit reached")
+	ErrModelVersionTaken  = errors.New("model version already exists")
+)
+
+// CreateModel adds a new model to the Models table, claiming its version in the model versions
+// table in the same transaction
 func (db *DB) CreateModel(ctx context.Context, model *models.ModelMetadata) error {
 	model.CreatedAt = time.Now()
 	model.UpdatedAt = time.Now()
@@ -22,44 +33,121 @@ func (db *DB) CreateModel(ctx context.Context, model *models.ModelMetadata) erro
 		return fmt.Errorf("failed to marshal model entity: %w", err)
 	}
 
-	input := &dynamodb.PutItemInput{
-		TableName: aws.String(db.modelsTable),
-		Item:      item,
-	}
-
-	_, err = db.client.PutItem(ctx, input)
+	_, err = db.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName: aws.String(db.versionsTable),
+					Item: map[string]types.AttributeValue{
+						"userId":        &types.AttributeValueMemberS{Value: model.UserId},
+						"familyVersion": modelFamilyVersion(model),
+						"modelId":       &types.AttributeValueMemberS{Value: model.ModelId},
+					},
+					ConditionExpression: aws.String("attribute_not_exists(userId)"),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(db.modelsTable),
+					Item:      item,
+				},
+			},
+		},
+	})
 	if err != nil {
+		if conditionFailed(err, 0) {
+			return ErrModelVersionTaken
+		}
 		return fmt.Errorf("failed to put model item in DynamoDB: %w", err)
 	}
 
 	return nil
 }
 
+// modelFamilyVersion keys a model's version claim. The version comes last and is a number,
+// so a model name containing # can't be mistaken for another family's.
+func modelFamilyVersion(model *models.ModelMetadata) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#%d", model.ModelName, model.Version)}
+}
+
+// conditionFailed reports whether a transaction was canceled by the condition on its item'th write
+func conditionFailed(err error, item int) bool {
+	var canceled *types.TransactionCanceledException
+	return errors.As(err, &canceled) && item < len(canceled.CancellationReasons) &&
+		aws.ToString(canceled.CancellationReasons[item].Code) == "ConditionalCheckFailed"
+}
+
 // GetModelsByUserId retrieves all models for a specific user
 func (db *DB) GetModelsByUserId(ctx context.Context, userId string) ([]*models.ModelMetadata, error) {
+	items, err := db.scanAll(ctx, modelsByUserIdInput(db.modelsTable, userId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan models from DynamoDB: %w", err)
+	}
+
+	return unmarshalModels(items)
+}
+
+// GetModelsByUserIdPage retrieves one page of models for a specific user
+func (db *DB) GetModelsByUserIdPage(ctx context.Context, userId string, page PageRequest) ([]*models.ModelMetadata, string, error) {
+	items, next, err := db.scanPage(ctx, modelsByUserIdInput(db.modelsTable, userId), page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scan models from DynamoDB: %w", err)
+	}
+
+	modelList, err := unmarshalModels(items)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return modelList, next, nil
+}
+
+// GetModelsByStatus retrieves every model with the given status across all users
+func (db *DB) GetModelsByStatus(ctx context.Context, status string) ([]*models.ModelMetadata, error) {
 	input := &dynamodb.ScanInput{
 		TableName:        aws.String(db.modelsTable),
-		FilterExpression: aws.String("#userId = :userId"),
+		FilterExpression: aws.String("#status = :status"),
 		ExpressionAttributeNames: map[string]string{
-			"#userId": "userId",
+			"#status": "status",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":userId": &types.AttributeValueMemberS{Value: userId},
+			":status": &types.AttributeValueMemberS{Value: status},
 		},
 	}
 
-	result, err := db.client.Scan(ctx, input)
+	items, err := db.scanAll(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan models from DynamoDB: %w", err)
 	}
 
-	modelList := make([]*models.ModelMetadata, 0, len(result.Items))
-	for _, item := range result.Items {
+	return unmarshalModels(items)
+}
+
+func modelsByUserIdInput(table string, userId string) *dynamodb.ScanInput {
+	return &dynamodb.ScanInput{
+		TableName:        aws.String(table),
+		FilterExpression: aws.String("#userId = :userId"),
+		ExpressionAttributeNames: map[string]string{
+			"#userId": "userId",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userId},
+		},
+	}
+}
+
+func unmarshalModels(items []item) ([]*models.ModelMetadata, error) {
+	modelList := make([]*models.ModelMetadata, 0, len(items))
+	for _, item := range items {
 		var model models.ModelMetadata
-		err = attributevalue.UnmarshalMap(item, &model)
+		err := attributevalue.UnmarshalMap(item, &model)
 		if err != nil {
 			return nil, fmt.Errorf("failed to unmarshal model: %w", err)
 		}
+		// Models uploaded before versioning are the first version of their family
+		if model.Version == 0 {
+			model.Version = 1
+		}
 		modelList = append(modelList, &model)
 	}
 
@@ -81,7 +169,7 @@ func (db *DB) GetModelById(ctx context.Context, modelId string, userId string) (
 	}
 
 	if result.Item == nil {
-		return nil, fmt.Errorf("model not found")
+		return nil, ErrModelNotFound
 	}
 
 	var model models.ModelMetadata
@@ -91,44 +179,107 @@ func (db *DB) GetModelById(ctx context.Context, modelId string, userId string) (
 	}
 
 	if model.UserId != userId {
-		return nil, fmt.Errorf("model not found")
+		return nil, ErrModelNotFound
+	}
+	if model.Version == 0 {
+		model.Version = 1
 	}
 
 	return &model, nil
 }
 
-// DeleteModel removes a model from the Models table
+// DeleteModel removes a model from the Models table and releases its version claim
 func (db *DB) DeleteModel(ctx context.Context, modelId string, userId string) error {
-	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String(db.modelsTable),
-		Key: map[string]types.AttributeValue{
-			"modelId": &types.AttributeValueMemberS{Value: modelId},
+	model, err := db.GetModelById(ctx, modelId, userId)
+	if err != nil {
+		return err
+	}
+
+	// Models stored before versions were claimed have no claim to release, which DynamoDB allows
+	_, err = db.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(db.modelsTable),
+					Key: map[string]types.AttributeValue{
+						"modelId": &types.AttributeValueMemberS{Value: modelId},
+					},
+					ConditionExpression: aws.String("#userId = :userId"),
+					ExpressionAttributeNames: map[string]string{
+						"#userId": "userId",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":userId": &types.AttributeValueMemberS{Value: userId},
+					},
+				},
+			},
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(db.versionsTable),
+					Key: map[string]types.AttributeValue{
+						"userId":        &types.AttributeValueMemberS{Value: userId},
+						"familyVersion": modelFamilyVersion(model),
+					},
+				},
+			},
 		},
+	})
+	if err != nil {
+		if conditionFailed(err, 0) {
+			return ErrModelNotFound
+		}
+		return fmt.Errorf("failed to delete model from DynamoDB: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateModel replaces an existing model's metadata, keeping its creation time
+func (db *DB) UpdateModel(ctx context.Context, model *models.ModelMetadata) error {
+	model.UpdatedAt = time.Now()
+
+	item, err := attributevalue.MarshalMap(model)
+	if err != nil {
+		return fmt.Errorf("failed to marshal model entity: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(db.modelsTable),
+		Item:                item,
 		ConditionExpression: aws.String("#userId = :userId"),
 		ExpressionAttributeNames: map[string]string{
 			"#userId": "userId",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":userId": &types.AttributeValueMemberS{Value: userId},
+			":userId": &types.AttributeValueMemberS{Value: model.UserId},
 		},
 	}
 
-	_, err := db.client.DeleteItem(ctx, input)
+	_, err = db.client.PutItem(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to delete model from DynamoDB: %w", err)
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return ErrModelNotFound
+		}
+		return fmt.Errorf("failed to update model in DynamoDB: %w", err)
 	}
 
 	return nil
 }
 
 // UpdateModelStatus updates the status of a model
-func (db *DB) UpdateModelStatus(ctx context.Context, modelId string, userId string, status string) error {
+func (db *DB) UpdateModelStatus(ctx context.Context, modelId string, userId string, status string, reason string) error {
+	updatedAt, err := attributevalue.Marshal(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to marshal update time: %w", err)
+	}
+
 	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String(db.modelsTable),
 		Key: map[string]types.AttributeValue{
 			"modelId": &types.AttributeValueMemberS{Value: modelId},
 		},
-		UpdateExpression: aws.String("SET #status = :status, updatedAt = :updatedAt"),
+		UpdateExpression:    aws.String("SET #status = :status, statusReason = :reason, updatedAt = :updatedAt"),
 		ConditionExpression: aws.String("#userId = :userId"),
 		ExpressionAttributeNames: map[string]string{
 			"#status": "status",
@@ -137,14 +288,192 @@ func (db *DB) UpdateModelStatus(ctx context.Context, modelId string, userId stri
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":userId":    &types.AttributeValueMemberS{Value: userId},
 			":status":    &types.AttributeValueMemberS{Value: status},
-			":updatedAt": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", time.Now().UnixMilli())},
+			":reason":    &types.AttributeValueMemberS{Value: reason},
+			":updatedAt": updatedAt,
 		},
 	}
 
-	_, err := db.client.UpdateItem(ctx, input)
+	_, err = db.client.UpdateItem(ctx, input)
 	if err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return ErrModelNotFound
+		}
 		return fmt.Errorf("failed to update model status in DynamoDB: %w", err)
 	}
 
 	return nil
 }
+
+// GetModelVersions retrieves every version in a user's model family, oldest first
+func (db *DB) GetModelVersions(ctx context.Context, userId string, modelName string) ([]*models.ModelMetadata, error) {
+	input := modelsByUserIdInput(db.modelsTable, userId)
+	input.FilterExpression = aws.String("#userId = :userId AND modelName = :modelName")
+	input.ExpressionAttributeValues[":modelName"] = &types.AttributeValueMemberS{Value: modelName}
+
+	items, err := db.scanAll(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan models from DynamoDB: %w", err)
+	}
+
+	versions, err := unmarshalModels(items)
+	if err != nil {
+		return nil, err
+	}
+
+	sortModelVersions(versions)
+	return versions, nil
+}
+
+// PromoteModel makes a validated version its family's active version and demotes the previous
+// active version back to validated, in one transaction
+func (db *DB) PromoteModel(ctx context.Context, modelId string, userId string) error {
+	target, err := db.GetModelById(ctx, modelId, userId)
+	if err != nil {
+		return err
+	}
+
+	versions, err := db.GetModelVersions(ctx, userId, target.ModelName)
+	if err != nil {
+		return err
+	}
+
+	demoted, err := promotionPlan(target, versions)
+	if err != nil {
+		return err
+	}
+
+	now, err := attributevalue.Marshal(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to marshal promotion time: %w", err)
+	}
+
+	items := []types.TransactWriteItem{{
+		Update: &types.Update{
+			TableName: aws.String(db.modelsTable),
+			Key: map[string]types.AttributeValue{
+				"modelId": &types.AttributeValueMemberS{Value: target.ModelId},
+			},
+			UpdateExpression:    aws.String("SET #status = :active, statusReason = :empty, promotedAt = :now, updatedAt = :now"),
+			ConditionExpression: aws.String("#userId = :userId AND #status IN (:active, :validated)"),
+			ExpressionAttributeNames: map[string]string{
+				"#status": "status",
+				"#userId": "userId",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":userId":    &types.AttributeValueMemberS{Value: userId},
+				":active":    &types.AttributeValueMemberS{Value: models.ModelStatusActive},
+				":validated": &types.AttributeValueMemberS{Value: models.ModelStatusValidated},
+				":empty":     &types.AttributeValueMemberS{Value: ""},
+				":now":       now,
+			},
+		},
+	}}
+
+	for _, model := range demoted {
+		items = append(items, types.TransactWriteItem{
+			Update: &types.Update{
+				TableName: aws.String(db.modelsTable),
+				Key: map[string]types.AttributeValue{
+					"modelId": &types.AttributeValueMemberS{Value: model.ModelId},
+				},
+				UpdateExpression:    aws.String("SET #status = :validated, updatedAt = :now"),
+				ConditionExpression: aws.String("#status = :active"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":active":    &types.AttributeValueMemberS{Value: models.ModelStatusActive},
+					":validated": &types.AttributeValueMemberS{Value: models.ModelStatusValidated},
+					":now":       now,
+				},
+			},
+		})
+	}
+
+	_, err = db.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		return fmt.Errorf("failed to promote model in DynamoDB: %w", err)
+	}
+
+	return nil
+}
+
+// RecordModelUpload adds one to the user's upload count for day, unless it has already reached limit
+func (db *DB) RecordModelUpload(ctx context.Context, userId string, day string, limit int) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(db.uploadsTable),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userId},
+			"day":    &types.AttributeValueMemberS{Value: day},
+		},
+		UpdateExpression: aws.String("ADD uploads :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+	}
+	if limit > 0 {
+		// The day's first upload creates the item, so there's no count to compare yet
+		input.ConditionExpression = aws.String("attribute_not_exists(uploads) OR uploads < :limit")
+		input.ExpressionAttributeValues[":limit"] = &types.AttributeValueMemberN{Value: strconv.Itoa(limit)}
+	}
+
+	if _, err := db.client.UpdateItem(ctx, input); err != nil {
+		var conditionalCheckFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionalCheckFailed) {
+			return ErrUploadLimitReached
+		}
+		return fmt.Errorf("failed to record model upload: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) GetModelUploadCount(ctx context.Context, userId string, day string) (int, error) {
+	result, err := db.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(db.uploadsTable),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userId},
+			"day":    &types.AttributeValueMemberS{Value: day},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get model upload count: %w", err)
+	}
+	if result.Item == nil {
+		return 0, nil
+	}
+
+	var count struct {
+		Uploads int `dynamodbav:"uploads"`
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, &count); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal model upload count: %w", err)
+	}
+	return count.Uploads, nil
+}
+
+// promotionPlan checks that target can be promoted and returns the family's other active versions,
+// which get demoted to validated
+func promotionPlan(target *models.ModelMetadata, versions []*models.ModelMetadata) ([]*models.ModelMetadata, error) {
+	if target.Status != models.ModelStatusValidated && target.Status != models.ModelStatusActive {
+		return nil, ErrModelNotPromotable
+	}
+
+	demoted := make([]*models.ModelMetadata, 0, 1)
+	for _, version := range versions {
+		if version.ModelId != target.ModelId && version.Status == models.ModelStatusActive {
+			demoted = append(demoted, version)
+		}
+	}
+
+	return demoted, nil
+}
+
+func sortModelVersions(versions []*models.ModelMetadata) {
+	sort.Slice(versions, func(i, j int) bool {
+		if versions[i].Version != versions[j].Version {
+			return versions[i].Version < versions[j].Version
+		}
+		return versions[i].CreatedAt.Before(versions[j].CreatedAt)
+	})
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestCreateModelClaimsVersion(t *testing.T) {
+	ctx := context.Background()
+	for name, db := range map[string]Store{
+		"memory": NewMemoryDB(),
+		"sqlite": openSQLite(t, filepath.Join(t.TempDir(), "pool.db")),
+	} {
+		first := &models.ModelMetadata{ModelId: "m1", ModelName: "elo", UserId: "user1", Status: models.ModelStatusPending, Version: 1}
+		if err := db.CreateModel(ctx, first); err != nil {
+			t.Fatalf("%s: CreateModel() error = %v", name, err)
+		}
+
+		clash := &models.ModelMetadata{ModelId: "m2", ModelName: "elo", UserId: "user1", Status: models.ModelStatusPending, Version: 1}
+		if err := db.CreateModel(ctx, clash); !errors.Is(err, ErrModelVersionTaken) {
+			t.Errorf("%s: CreateModel() with a taken version error = %v, want ErrModelVersionTaken", name, err)
+		}
+		if _, err := db.GetModelById(ctx, "m2", "user1"); !errors.Is(err, ErrModelNotFound) {
+			t.Errorf("%s: the clashing model was stored: %v", name, err)
+		}
+
+		// Versions belong to a family, and deleting a model frees its version
+		for _, other := range []*models.ModelMetadata{
+			{ModelId: "m3", ModelName: "poisson", UserId: "user1", Status: models.ModelStatusPending, Version: 1},
+			{ModelId: "m4", ModelName: "elo", UserId: "user2", Status: models.ModelStatusPending, Version: 1},
+		} {
+			if err := db.CreateModel(ctx, other); err != nil {
+				t.Errorf("%s: CreateModel(%s) error = %v", name, other.ModelId, err)
+			}
+		}
+		if err := db.DeleteModel(ctx, "m1", "user1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.CreateModel(ctx, clash); err != nil {
+			t.Errorf("%s: CreateModel() after deleting the version error = %v", name, err)
+		}
+	}
+}
+
+func TestSQLiteRenumbersDuplicateVersions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "pool.db")
+
+	// Step back to before versions were unique and store what concurrent uploads could leave behind
+	db := openSQLite(t, path)
+	_, err := db.conn.ExecContext(ctx, `DROP INDEX models_family_idx;
+		CREATE INDEX models_family_idx ON models (user_id, model_name, version);
+		DELETE FROM schema_migrations WHERE version = ?`, len(sqliteMigrations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i, model := range []struct {
+		id, name string
+		version  int
+	}{
+		{"a", "elo", 1}, {"b", "elo", 2}, {"c", "elo", 2}, {"d", "elo", 2}, {"e", "poisson", 1}, {"f", "poisson", 1},
+	} {
+		_, err := db.conn.ExecContext(ctx,
+			`INSERT INTO models (model_id, model_name, user_id, file_name, s3_key, status, version, created_at, updated_at)
+			VALUES (?, ?, 'user1', 'model.onnx', '', ?, ?, ?, ?)`,
+			model.id, model.name, models.ModelStatusValidated, model.version, created.Add(time.Duration(i)*time.Minute), created,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	reopened := openSQLite(t, path)
+	for family, want := range map[string][]string{
+		// The first copy of a version keeps it, the rest follow the family's latest version
+		"elo":     {"a:1", "b:2", "c:3", "d:4"},
+		"poisson": {"e:1", "f:2"},
+	} {
+		versions, err := reopened.GetModelVersions(ctx, "user1", family)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, version := range versions {
+			got = append(got, version.ModelId+":"+strconv.Itoa(version.Version))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s versions = %v, want %v", family, got, want)
+		}
+	}
+}
+
+func TestRecordModelUploadStopsAtLimit(t *testing.T) {
+	ctx := context.Background()
+	for name, db := range map[string]Store{
+		"memory": NewMemoryDB(),
+		"sqlite": openSQLite(t, filepath.Join(t.TempDir(), "pool.db")),
+	} {
+		for i := 0; i < 3; i++ {
+			if err := db.RecordModelUpload(ctx, "user1", "2025-06-10", 3); err != nil {
+				t.Fatalf("%s: upload %d error = %v", name, i+1, err)
+			}
+		}
+		if err := db.RecordModelUpload(ctx, "user1", "2025-06-10", 3); !errors.Is(err, ErrUploadLimitReached) {
+			t.Errorf("%s: upload over the limit error = %v, want ErrUploadLimitReached", name, err)
+		}
+		if count, _ := db.GetModelUploadCount(ctx, "user1", "2025-06-10"); count != 3 {
+			t.Errorf("%s: count = %d after a refused upload, want 3", name, count)
+		}
+
+		// Each day and user has its own count, and 0 turns the limit off
+		if err := db.RecordModelUpload(ctx, "user1", "2025-06-11", 3); err != nil {
+			t.Errorf("%s: next day's upload error = %v", name, err)
+		}
+		if err := db.RecordModelUpload(ctx, "user2", "2025-06-10", 3); err != nil {
+			t.Errorf("%s: another user's upload error = %v", name, err)
+		}
+		if err := db.RecordModelUpload(ctx, "user1", "2025-06-10", 0); err != nil {
+			t.Errorf("%s: unlimited upload error = %v", name, err)
+		}
+	}
+}
+
+func TestRecordModelUploadConcurrently(t *testing.T) {
+	ctx := context.Background()
+	for name, db := range map[string]Store{
+		"memory": NewMemoryDB(),
+		"sqlite": openSQLite(t, filepath.Join(t.TempDir(), "pool.db")),
+	} {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		counted := 0
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := db.RecordModelUpload(ctx, "user1", "2025-06-10", 4)
+				if err != nil && !errors.Is(err, ErrUploadLimitReached) {
+					t.Errorf("%s: RecordModelUpload() error = %v", name, err)
+				}
+				if err == nil {
+					mu.Lock()
+					counted++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if counted != 4 {
+			t.Errorf("%s: %d of 10 concurrent uploads were counted, want 4", name, counted)
+		}
+		if count, _ := db.GetModelUploadCount(ctx, "user1", "2025-06-10"); count != 4 {
+			t.Errorf("%s: count = %d, want 4", name, count)
+		}
+	}
+}
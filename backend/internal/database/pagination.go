@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// PageRequest describes a single page of a paginated read.
+// Cursor is the opaque token returned with the previous page, empty for the first page.
+type PageRequest struct {
+	Limit  int32
+	Cursor string
+}
+
+type item = map[string]types.AttributeValue
+
+// scanAll runs a Scan and follows LastEvaluatedKey until the table is exhausted
+func (db *DB) scanAll(ctx context.Context, input *dynamodb.ScanInput) ([]item, error) {
+	var items []item
+
+	paginator := dynamodb.NewScanPaginator(db.client, input)
+	for paginator.HasMorePages() {
+		result, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, result.Items...)
+	}
+
+	return items, nil
+}
+
+// queryAll runs a Query and follows LastEvaluatedKey until all matching items are read
+func (db *DB) queryAll(ctx context.Context, input *dynamodb.QueryInput) ([]item, error) {
+	var items []item
+
+	paginator := dynamodb.NewQueryPaginator(db.client, input)
+	for paginator.HasMorePages() {
+		result, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, result.Items...)
+	}
+
+	return items, nil
+}
+
+// maxBatchGetKeys is the most keys one BatchGetItem request may ask for
+const maxBatchGetKeys = 100
+
+// batchGetAll reads keys from table maxBatchGetKeys at a time, retrying whatever DynamoDB leaves
+// unprocessed. Keys without an item are skipped, and items come back in no particular order.
+func (db *DB) batchGetAll(ctx context.Context, table string, keys []item) ([]item, error) {
+	var items []item
+
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		end := min(start+maxBatchGetKeys, len(keys))
+		request := map[string]types.KeysAndAttributes{table: {Keys: keys[start:end]}}
+		for len(request) > 0 {
+			result, err := db.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, result.Responses[table]...)
+			request = result.UnprocessedKeys
+		}
+	}
+
+	return items, nil
+}
+
+// scanPage reads up to page.Limit items starting at page.Cursor.
+// Filtered scans can return short pages, so keep reading until the page is full or the table ends.
+func (db *DB) scanPage(ctx context.Context, input *dynamodb.ScanInput, page PageRequest) ([]item, string, error) {
+	return collectPage(page, func(limit int32, startKey item) ([]item, item, error) {
+		input.Limit = aws.Int32(limit)
+		input.ExclusiveStartKey = startKey
+		result, err := db.client.Scan(ctx, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result.Items, result.LastEvaluatedKey, nil
+	})
+}
+
+// queryPage reads up to page.Limit items starting at page.Cursor
+func (db *DB) queryPage(ctx context.Context, input *dynamodb.QueryInput, page PageRequest) ([]item, string, error) {
+	return collectPage(page, func(limit int32, startKey item) ([]item, item, error) {
+		input.Limit = aws.Int32(limit)
+		input.ExclusiveStartKey = startKey
+		result, err := db.client.Query(ctx, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result.Items, result.LastEvaluatedKey, nil
+	})
+}
+
+func collectPage(page PageRequest, fetch func(limit int32, startKey item) ([]item, item, error)) ([]item, string, error) {
+	if page.Limit <= 0 {
+		return nil, "", fmt.Errorf("page limit must be positive")
+	}
+
+	startKey, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := make([]item, 0, page.Limit)
+	for {
+		result, lastKey, err := fetch(page.Limit-int32(len(items)), startKey)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, result...)
+		startKey = lastKey
+
+		if len(lastKey) == 0 || int32(len(items)) >= page.Limit {
+			break
+		}
+	}
+
+	next, err := encodeCursor(startKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return items, next, nil
+}
+
+// encodeCursor turns a LastEvaluatedKey into an opaque, URL-safe token
+func encodeCursor(key item) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	var plain map[string]interface{}
+	if err := attributevalue.UnmarshalMap(key, &plain); err != nil {
+		return "", fmt.Errorf("failed to unmarshal pagination key: %w", err)
+	}
+
+	data, err := json.Marshal(plain)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode pagination key: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (item, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var plain map[string]interface{}
+	if err := json.Unmarshal(data, &plain); err != nil || len(plain) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	key, err := attributevalue.MarshalMap(plain)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return key, nil
+}
+
+// encodeKeyCursor is the cursor format for backends that page by a single ordered key
+func encodeKeyCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeKeyCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(key) == 0 {
+		return "", ErrInvalidCursor
+	}
+	return string(key), nil
+}
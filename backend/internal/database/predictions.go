@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,9 +13,19 @@ import (
 	"github.com/bendemouth/mlb-prediction-pool/internal/models"
 )
 
+// PredictionSortKey builds the predictions table range key. A user's own pick is keyed by
+// gameId alone and each model's pick by gameId#modelId, so one user can hold a pick per model per game.
+func PredictionSortKey(gameId, modelId string) string {
+	if modelId == "" {
+		return gameId
+	}
+	return gameId + "#" + modelId
+}
+
 // CreatePrediction stores a new prediction
 func (db *DB) CreatePrediction(ctx context.Context, prediction *models.Prediction) error {
 	prediction.SubmittedAt = time.Now()
+	prediction.PredictionKey = PredictionSortKey(prediction.GameId, prediction.ModelId)
 
 	item, err := attributevalue.MarshalMap(prediction)
 	if err != nil {
@@ -36,39 +47,46 @@ func (db *DB) CreatePrediction(ctx context.Context, prediction *models.Predictio
 
 // GetUserPredictions retrieves all predictions for a user
 func (db *DB) GetUserPredictions(ctx context.Context, userId string) ([]models.Prediction, error) {
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String(db.predictionsTable),
-		KeyConditionExpression: aws.String("userId = :userId"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":userId": &types.AttributeValueMemberS{Value: userId},
-		},
-	}
-
-	result, err := db.client.Query(ctx, input)
+	items, err := db.queryAll(ctx, userPredictionsInput(db.predictionsTable, userId))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query predictions: %w", err)
 	}
 
-	predictions := make([]models.Prediction, 0, len(result.Items))
+	return unmarshalPredictions(items)
+}
 
-	for _, item := range result.Items {
-		var prediction models.Prediction
-		if err := attributevalue.UnmarshalMap(item, &prediction); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal prediction: %w", err)
-		}
-		predictions = append(predictions, prediction)
+// GetUserPredictionsPage retrieves one page of predictions for a user
+func (db *DB) GetUserPredictionsPage(ctx context.Context, userId string, page PageRequest) ([]models.Prediction, string, error) {
+	items, next, err := db.queryPage(ctx, userPredictionsInput(db.predictionsTable, userId), page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query predictions: %w", err)
 	}
 
-	return predictions, nil
+	predictions, err := unmarshalPredictions(items)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return predictions, next, nil
 }
 
-// GetPredictionByUser retrieves a specific prediction by userId and gameId
-func (db *DB) GetPredictionByUser(ctx context.Context, userId, gameId string) (*models.Prediction, error) {
+func userPredictionsInput(table string, userId string) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		KeyConditionExpression: aws.String("userId = :userId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userId},
+		},
+	}
+}
+
+// GetPredictionByUser retrieves a specific prediction by userId, gameId and modelId (empty for the user's own pick)
+func (db *DB) GetPredictionByUser(ctx context.Context, userId, gameId, modelId string) (*models.Prediction, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(db.predictionsTable),
 		Key: map[string]types.AttributeValue{
-			"userId": &types.AttributeValueMemberS{Value: userId},
-			"gameId": &types.AttributeValueMemberS{Value: gameId},
+			"userId":        &types.AttributeValueMemberS{Value: userId},
+			"predictionKey": &types.AttributeValueMemberS{Value: PredictionSortKey(gameId, modelId)},
 		},
 	}
 
@@ -91,22 +109,110 @@ func (db *DB) GetPredictionByUser(ctx context.Context, userId, gameId string) (*
 
 // GetPredictionsByGame retrieves all predictions for a specific game
 func (db *DB) GetPredictionsByGame(ctx context.Context, gameId string) ([]models.Prediction, error) {
+	items, err := db.queryAll(ctx, gamePredictionsInput(db.predictionsTable, gameId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query predictions: %w", err)
+	}
+
+	return unmarshalPredictions(items)
+}
+
+// GetPredictionsByGamePage retrieves one page of predictions for a specific game
+func (db *DB) GetPredictionsByGamePage(ctx context.Context, gameId string, page PageRequest) ([]models.Prediction, string, error) {
+	items, next, err := db.queryPage(ctx, gamePredictionsInput(db.predictionsTable, gameId), page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query predictions: %w", err)
+	}
+
+	predictions, err := unmarshalPredictions(items)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return predictions, next, nil
+}
+
+// GetModelPredictions retrieves every prediction made by a model
+func (db *DB) GetModelPredictions(ctx context.Context, modelId string) ([]models.Prediction, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(db.predictionsTable),
-		IndexName:              aws.String("GameIdIndex"), // Your GSI name
-		KeyConditionExpression: aws.String("gameId = :gameId"),
+		IndexName:              aws.String("ModelIdIndex"),
+		KeyConditionExpression: aws.String("modelId = :modelId"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":gameId": &types.AttributeValueMemberS{Value: gameId},
+			":modelId": &types.AttributeValueMemberS{Value: modelId},
 		},
 	}
 
-	result, err := db.client.Query(ctx, input)
+	items, err := db.queryAll(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query predictions: %w", err)
 	}
 
-	predictions := make([]models.Prediction, 0, len(result.Items))
-	for _, item := range result.Items {
+	return unmarshalPredictions(items)
+}
+
+// maxConcurrentGameQueries bounds the parallel GameIdIndex queries issued by GetPredictionsByGames
+const maxConcurrentGameQueries = 16
+
+// GetPredictionsByGames retrieves predictions for several games at once, keyed by gameId.
+// Queries run concurrently so a full slate costs a few round trips of latency rather than one per game.
+func (db *DB) GetPredictionsByGames(ctx context.Context, gameIds []string) (map[string][]models.Prediction, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	results := make(map[string][]models.Prediction, len(gameIds))
+	sem := make(chan struct{}, maxConcurrentGameQueries)
+
+	for _, gameId := range gameIds {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(gameId string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			predictions, err := db.GetPredictionsByGame(ctx, gameId)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to get predictions for game %s: %w", gameId, err)
+					cancel()
+				}
+				return
+			}
+			results[gameId] = predictions
+		}(gameId)
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return results, nil
+}
+
+func gamePredictionsInput(table string, gameId string) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		IndexName:              aws.String("GameIdIndex"),
+		KeyConditionExpression: aws.String("gameId = :gameId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":gameId": &types.AttributeValueMemberS{Value: gameId},
+		},
+	}
+}
+
+func unmarshalPredictions(items []item) ([]models.Prediction, error) {
+	predictions := make([]models.Prediction, 0, len(items))
+	for _, item := range items {
 		var prediction models.Prediction
 		if err := attributevalue.UnmarshalMap(item, &prediction); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal prediction: %w", err)
@@ -134,6 +240,7 @@ func (db *DB) BatchCreatePredictions(ctx context.Context, predictions []models.P
 
 		for _, prediction := range batch {
 			prediction.SubmittedAt = now
+			prediction.PredictionKey = PredictionSortKey(prediction.GameId, prediction.ModelId)
 
 			item, err := attributevalue.MarshalMap(prediction)
 			if err != nil {
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PredictionCopyResult counts what CopyPredictions did. A dry run doesn't look at the
+// target, so every readable item counts as copied.
+type PredictionCopyResult struct {
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	DryRun  bool   `json:"dry_run"`
+	Scanned int    `json:"scanned"`
+	Copied  int    `json:"copied"`
+	// Existing items were already in the target at least as recent, from an earlier run or written there since the switch
+	Existing int      `json:"existing"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// CopyPredictions copies a predictions table keyed by userId and gameId, as it was before
+// model picks, into this DB's predictions table, which is keyed by userId and predictionKey.
+// predictionKey is filled in from gameId and modelId on the way. An item already in the target
+// is only replaced by a pick submitted later, so picks made after the API switched tables win,
+// picks changed on the old table during the switch are carried over, and the copy can be re-run
+// until it copies nothing.
+func (db *DB) CopyPredictions(ctx context.Context, sourceTable string, dryRun bool) (*PredictionCopyResult, error) {
+	if sourceTable == db.predictionsTable {
+		return nil, fmt.Errorf("source and target are both %s", sourceTable)
+	}
+	result := &PredictionCopyResult{Source: sourceTable, Target: db.predictionsTable, DryRun: dryRun}
+
+	paginator := dynamodb.NewScanPaginator(db.client, &dynamodb.ScanInput{TableName: aws.String(sourceTable)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to scan %s: %w", sourceTable, err)
+		}
+
+		for _, item := range page.Items {
+			result.Scanned++
+
+			var key struct {
+				UserId  string `dynamodbav:"userId"`
+				GameId  string `dynamodbav:"gameId"`
+				ModelId string `dynamodbav:"modelId"`
+			}
+			if err := attributevalue.UnmarshalMap(item, &key); err != nil || key.UserId == "" || key.GameId == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("item %d has no userId or gameId", result.Scanned))
+				continue
+			}
+			item["predictionKey"] = &types.AttributeValueMemberS{Value: PredictionSortKey(key.GameId, key.ModelId)}
+
+			if dryRun {
+				result.Copied++
+				continue
+			}
+
+			input := &dynamodb.PutItemInput{
+				TableName:           aws.String(db.predictionsTable),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(userId)"),
+			}
+			// submittedAt is stored as RFC 3339, which orders as a string while the servers share a time zone
+			if submittedAt, ok := item["submittedAt"].(*types.AttributeValueMemberS); ok {
+				input.ConditionExpression = aws.String("attribute_not_exists(userId) OR submittedAt < :submittedAt")
+				input.ExpressionAttributeValues = map[string]types.AttributeValue{":submittedAt": submittedAt}
+			}
+
+			_, err := db.client.PutItem(ctx, input)
+			if err != nil {
+				var conditionalCheckFailed *types.ConditionalCheckFailedException
+				if errors.As(err, &conditionalCheckFailed) {
+					result.Existing++
+					continue
+				}
+				result.Errors = append(result.Errors, fmt.Sprintf("copy %s/%s: %v", key.UserId, key.GameId, err))
+				continue
+			}
+			result.Copied++
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+var ErrSeasonNotFound = errors.New("season not found")
+
+// PutSeason stores a season, replacing any existing season for the same year
+func (db *DB) PutSeason(ctx context.Context, season *models.Season) error {
+	item, err := attributevalue.MarshalMap(season)
+	if err != nil {
+		return fmt.Errorf("failed to marshal season: %w", err)
+	}
+
+	_, err = db.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(db.seasonsTable),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put season: %w", err)
+	}
+
+	return nil
+}
+
+// GetSeason retrieves a season by year
+func (db *DB) GetSeason(ctx context.Context, year int) (*models.Season, error) {
+	result, err := db.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(db.seasonsTable),
+		Key: map[string]types.AttributeValue{
+			"year": &types.AttributeValueMemberN{Value: strconv.Itoa(year)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get season: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrSeasonNotFound
+	}
+
+	var season models.Season
+	if err := attributevalue.UnmarshalMap(result.Item, &season); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal season: %w", err)
+	}
+
+	return &season, nil
+}
+
+// ListSeasons retrieves every season, oldest first. There is one item per year, so a scan is fine.
+func (db *DB) ListSeasons(ctx context.Context) ([]models.Season, error) {
+	items, err := db.scanAll(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(db.seasonsTable),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan seasons: %w", err)
+	}
+
+	seasons := make([]models.Season, 0, len(items))
+	for _, item := range items {
+		var season models.Season
+		if err := attributevalue.UnmarshalMap(item, &season); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal season: %w", err)
+		}
+		seasons = append(seasons, season)
+	}
+
+	sortSeasons(seasons)
+	return seasons, nil
+}
+
+func sortSeasons(seasons []models.Season) {
+	sort.Slice(seasons, func(i, j int) bool { return seasons[i].Year < seasons[j].Year })
+}
+
+// CurrentSeason picks the season day (YYYY-MM-DD) falls in. Between seasons it is the latest
+// season that has started, and with no seasons stored it is the calendar year of day.
+func CurrentSeason(seasons []models.Season, day string) int {
+	current := 0
+	for _, season := range seasons {
+		if season.Contains(day) {
+			return season.Year
+		}
+		if start := season.Start(); start != "" && start <= day && season.Year > current {
+			current = season.Year
+		}
+	}
+
+	if current == 0 {
+		current, _ = strconv.Atoi(day[:min(4, len(day))])
+	}
+	return current
+}
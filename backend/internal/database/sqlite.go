@@ -0,0 +1,351 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteDB is a Store backed by a single SQLite file, for running the pool without AWS
+type SQLiteDB struct {
+	conn *sql.DB
+}
+
+// sqliteMigrations are applied in order and recorded in schema_migrations.
+// Never edit an entry once it has shipped; append a new one instead.
+var sqliteMigrations = []string{
+	`CREATE TABLE users (
+		user_id    TEXT PRIMARY KEY,
+		username   TEXT NOT NULL,
+		email      TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE games (
+		game_id      TEXT PRIMARY KEY,
+		date         DATETIME NOT NULL,
+		home_team    TEXT NOT NULL,
+		home_team_id TEXT NOT NULL,
+		away_team_id TEXT NOT NULL,
+		away_team    TEXT NOT NULL,
+		home_score   INTEGER NOT NULL DEFAULT 0,
+		away_score   INTEGER NOT NULL DEFAULT 0,
+		status       TEXT NOT NULL,
+		winner       TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX games_status_idx ON games (status);
+
+	CREATE TABLE predictions (
+		user_id               TEXT NOT NULL,
+		game_id               TEXT NOT NULL,
+		home_score_predicted  REAL NOT NULL,
+		away_score_predicted  REAL NOT NULL,
+		total_score_predicted REAL NOT NULL,
+		confidence            REAL NOT NULL,
+		predicted_winner_id   TEXT NOT NULL,
+		actual_winner_id      TEXT NOT NULL DEFAULT '',
+		winner_correct        BOOLEAN,
+		home_score_error      REAL NOT NULL DEFAULT 0,
+		away_score_error      REAL NOT NULL DEFAULT 0,
+		total_score_error     REAL NOT NULL DEFAULT 0,
+		submitted_at          DATETIME NOT NULL,
+		PRIMARY KEY (user_id, game_id)
+	);
+	CREATE INDEX predictions_game_id_idx ON predictions (game_id, user_id);
+
+	CREATE TABLE models (
+		model_id   TEXT PRIMARY KEY,
+		model_name TEXT NOT NULL,
+		user_id    TEXT NOT NULL,
+		file_name  TEXT NOT NULL,
+		s3_key     TEXT NOT NULL,
+		status     TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	CREATE INDEX models_user_id_idx ON models (user_id, model_id);`,
+
+	`ALTER TABLE games ADD COLUMN game_day TEXT NOT NULL DEFAULT '';
+	UPDATE games SET game_day = substr(date, 1, 10);
+	CREATE INDEX games_game_day_idx ON games (game_day, date);
+	CREATE INDEX games_home_team_idx ON games (home_team_id, game_day);
+	CREATE INDEX games_away_team_idx ON games (away_team_id, game_day);`,
+
+	// Predictions gain a model_id ('' for the user's own picks) that joins the primary key
+	`CREATE TABLE predictions_new (
+		user_id               TEXT NOT NULL,
+		game_id               TEXT NOT NULL,
+		model_id              TEXT NOT NULL DEFAULT '',
+		home_score_predicted  REAL NOT NULL,
+		away_score_predicted  REAL NOT NULL,
+		total_score_predicted REAL NOT NULL,
+		confidence            REAL NOT NULL,
+		predicted_winner_id   TEXT NOT NULL,
+		actual_winner_id      TEXT NOT NULL DEFAULT '',
+		winner_correct        BOOLEAN,
+		home_score_error      REAL NOT NULL DEFAULT 0,
+		away_score_error      REAL NOT NULL DEFAULT 0,
+		total_score_error     REAL NOT NULL DEFAULT 0,
+		submitted_at          DATETIME NOT NULL,
+		PRIMARY KEY (user_id, game_id, model_id)
+	);
+	INSERT INTO predictions_new (user_id, game_id, home_score_predicted, away_score_predicted, total_score_predicted,
+		confidence, predicted_winner_id, actual_winner_id, winner_correct,
+		home_score_error, away_score_error, total_score_error, submitted_at)
+	SELECT user_id, game_id, home_score_predicted, away_score_predicted, total_score_predicted,
+		confidence, predicted_winner_id, actual_winner_id, winner_correct,
+		home_score_error, away_score_error, total_score_error, submitted_at
+	FROM predictions;
+	DROP TABLE predictions;
+	ALTER TABLE predictions_new RENAME TO predictions;
+	CREATE INDEX predictions_game_id_idx ON predictions (game_id, user_id, model_id);
+	CREATE INDEX predictions_model_id_idx ON predictions (model_id, game_id);`,
+
+	`ALTER TABLE models ADD COLUMN framework TEXT NOT NULL DEFAULT '';
+	ALTER TABLE models ADD COLUMN signature TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE models ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';
+	ALTER TABLE models ADD COLUMN sha256 TEXT NOT NULL DEFAULT '';
+	CREATE INDEX models_status_idx ON models (status, model_id);`,
+
+	`ALTER TABLE models ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+	ALTER TABLE models ADD COLUMN changelog TEXT NOT NULL DEFAULT '';
+	ALTER TABLE models ADD COLUMN promoted_at DATETIME;
+	CREATE INDEX models_family_idx ON models (user_id, model_name, version);`,
+
+	`ALTER TABLE models ADD COLUMN size_bytes INTEGER NOT NULL DEFAULT 0;`,
+
+	`ALTER TABLE models ADD COLUMN content_type TEXT NOT NULL DEFAULT '';
+	ALTER TABLE models ADD COLUMN features TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE games ADD COLUMN innings INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE games ADD COLUMN line_score TEXT NOT NULL DEFAULT '';`,
+
+	`CREATE TABLE teams (
+		team_id         TEXT PRIMARY KEY,
+		name            TEXT NOT NULL,
+		abbreviation    TEXT NOT NULL,
+		league          TEXT NOT NULL,
+		division        TEXT NOT NULL,
+		venue_id        TEXT NOT NULL,
+		venue_name      TEXT NOT NULL,
+		venue_city      TEXT NOT NULL,
+		venue_time_zone TEXT NOT NULL
+	);`,
+
+	// Games and scored predictions gain their season and game type; existing rows are
+	// backfilled as regular season games of the year they were played in
+	`ALTER TABLE games ADD COLUMN season INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE games ADD COLUMN game_type TEXT NOT NULL DEFAULT '';
+	UPDATE games SET season = CAST(substr(game_day, 1, 4) AS INTEGER), game_type = 'regular' WHERE game_day != '';
+	CREATE INDEX games_season_idx ON games (season, game_day);
+
+	ALTER TABLE predictions ADD COLUMN season INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE predictions ADD COLUMN game_type TEXT NOT NULL DEFAULT '';
+	UPDATE predictions SET
+		season = COALESCE((SELECT games.season FROM games WHERE games.game_id = predictions.game_id), 0),
+		game_type = COALESCE((SELECT games.game_type FROM games WHERE games.game_id = predictions.game_id), '')
+	WHERE winner_correct IS NOT NULL;
+
+	CREATE TABLE seasons (
+		year          INTEGER PRIMARY KEY,
+		phases        TEXT NOT NULL,
+		round_weights TEXT NOT NULL DEFAULT ''
+	);`,
+
+	`ALTER TABLE games ADD COLUMN game_number INTEGER NOT NULL DEFAULT 1;
+	ALTER TABLE games ADD COLUMN doubleheader TEXT NOT NULL DEFAULT '';
+	ALTER TABLE games ADD COLUMN start_time_tbd BOOLEAN NOT NULL DEFAULT 0;
+	ALTER TABLE games ADD COLUMN start_time_zone TEXT NOT NULL DEFAULT '';`,
+
+	`CREATE TABLE leagues (
+		league_id   TEXT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		owner_id    TEXT NOT NULL,
+		invite_code TEXT NOT NULL UNIQUE,
+		settings    TEXT NOT NULL,
+		created_at  DATETIME NOT NULL
+	);
+
+	CREATE TABLE league_members (
+		league_id TEXT NOT NULL,
+		user_id   TEXT NOT NULL,
+		role      TEXT NOT NULL,
+		joined_at DATETIME NOT NULL,
+		PRIMARY KEY (league_id, user_id)
+	);
+	CREATE INDEX league_members_user_id_idx ON league_members (user_id, league_id);`,
+
+	`CREATE TABLE contests (
+		contest_id   TEXT PRIMARY KEY,
+		league_id    TEXT NOT NULL,
+		name         TEXT NOT NULL,
+		rules        TEXT NOT NULL,
+		entry_opens  DATETIME NOT NULL,
+		entry_closes DATETIME NOT NULL,
+		created_by   TEXT NOT NULL,
+		created_at   DATETIME NOT NULL
+	);
+	CREATE INDEX contests_league_id_idx ON contests (league_id, entry_closes);
+
+	CREATE TABLE contest_entries (
+		contest_id TEXT NOT NULL,
+		user_id    TEXT NOT NULL,
+		entered_at DATETIME NOT NULL,
+		PRIMARY KEY (contest_id, user_id)
+	);`,
+
+	`CREATE TABLE model_uploads (
+		user_id TEXT NOT NULL,
+		day     TEXT NOT NULL,
+		uploads INTEGER NOT NULL,
+		PRIMARY KEY (user_id, day)
+	);`,
+
+	`ALTER TABLE contests ADD COLUMN game_ids TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE teams ADD COLUMN logo_url TEXT NOT NULL DEFAULT '';`,
+
+	// A model family's versions become unique. Concurrent uploads could share a version before,
+	// so every copy after the first is renumbered past the family's latest version.
+	`WITH copies AS (
+		SELECT model_id, user_id, model_name,
+			ROW_NUMBER() OVER (PARTITION BY user_id, model_name, version ORDER BY created_at, model_id) AS copy
+		FROM models
+	), renumbered AS (
+		SELECT model_id,
+			(SELECT MAX(version) FROM models m WHERE m.user_id = copies.user_id AND m.model_name = copies.model_name)
+				+ ROW_NUMBER() OVER (PARTITION BY user_id, model_name ORDER BY model_id) AS version
+		FROM copies WHERE copy > 1
+	)
+	UPDATE models SET version = (SELECT version FROM renumbered WHERE renumbered.model_id = models.model_id)
+	WHERE model_id IN (SELECT model_id FROM renumbered);
+	DROP INDEX models_family_idx;
+	CREATE UNIQUE INDEX models_family_idx ON models (user_id, model_name, version);`,
+}
+
+// NewSQLiteDB opens (creating if needed) the SQLite database at path and migrates it to the latest schema
+func NewSQLiteDB(ctx context.Context, path string) (*SQLiteDB, error) {
+	conn, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	// SQLite allows a single writer; serialising through one connection avoids SQLITE_BUSY under load
+	conn.SetMaxOpenConns(1)
+
+	db := &SQLiteDB{conn: conn}
+	if err := db.migrate(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func (db *SQLiteDB) migrate(ctx context.Context) error {
+	_, err := db.conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var current int
+	err = db.conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(sqliteMigrations); i++ {
+		version := i + 1
+
+		err := db.withTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (db *SQLiteDB) Close() error {
+	return db.conn.Close()
+}
+
+func (db *SQLiteDB) HealthCheck(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
+func (db *SQLiteDB) CalculateLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return calculateLeaderboard(ctx, db, scope)
+}
+
+func (db *SQLiteDB) GetUserStats(ctx context.Context, userId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
+	return getUserStats(ctx, db, userId, scope)
+}
+
+func (db *SQLiteDB) CalculateModelLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error) {
+	return calculateModelLeaderboard(ctx, db, scope)
+}
+
+func (db *SQLiteDB) GetModelStats(ctx context.Context, modelId string, scope LeaderboardScope) (*models.LeaderboardEntry, error) {
+	return getModelStats(ctx, db, modelId, scope)
+}
+
+// keysetPage appends the cursor condition and limit for a query ordered by keyColumn.
+// A zero Limit leaves the query unbounded so the non-paged reads can share it.
+func keysetPage(query string, args []interface{}, keyColumn string, page PageRequest) (string, []interface{}, error) {
+	if page.Cursor != "" {
+		after, err := decodeKeyCursor(page.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " AND " + keyColumn + " > ?"
+		args = append(args, after)
+	}
+
+	query += " ORDER BY " + keyColumn
+	if page.Limit > 0 {
+		// Fetch one extra row to know whether another page exists
+		query += " LIMIT ?"
+		args = append(args, page.Limit+1)
+	}
+
+	return query, args, nil
+}
+
+// trimPage drops the look-ahead row fetched by keysetPage and builds the next cursor
+func trimPage[T any](items []T, key func(T) string, page PageRequest) ([]T, string) {
+	if page.Limit <= 0 || len(items) <= int(page.Limit) {
+		return items, ""
+	}
+
+	items = items[:page.Limit]
+	return items, encodeKeyCursor(key(items[len(items)-1]))
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const (
+	sqliteContestColumns      = `contest_id, league_id, name, rules, game_ids, entry_opens, entry_closes, created_by, created_at`
+	sqliteContestEntryColumns = `contest_id, user_id, entered_at`
+)
+
+func scanContest(row rowScanner) (models.Contest, error) {
+	var contest models.Contest
+	var rules, gameIds string
+	err := row.Scan(
+		&contest.ContestId, &contest.LeagueId, &contest.Name, &rules, &gameIds,
+		&contest.EntryOpens, &contest.EntryCloses, &contest.CreatedBy, &contest.CreatedAt,
+	)
+	if err != nil {
+		return contest, err
+	}
+
+	if err := json.Unmarshal([]byte(rules), &contest.Rules); err != nil {
+		return contest, fmt.Errorf("failed to decode rules for contest %s: %w", contest.ContestId, err)
+	}
+	if gameIds != "" {
+		contest.GameIds = strings.Split(gameIds, ",")
+	}
+
+	return contest, nil
+}
+
+// CreateContest stores a new contest
+func (db *SQLiteDB) CreateContest(ctx context.Context, contest *models.Contest) error {
+	contest.CreatedAt = time.Now()
+
+	rules, err := json.Marshal(contest.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode contest rules: %w", err)
+	}
+
+	_, err = db.conn.ExecContext(ctx,
+		`INSERT INTO contests (`+sqliteContestColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		contest.ContestId, contest.LeagueId, contest.Name, string(rules), strings.Join(contest.GameIds, ","),
+		contest.EntryOpens, contest.EntryCloses, contest.CreatedBy, contest.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create contest: %w", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetContest(ctx context.Context, contestId string) (*models.Contest, error) {
+	contest, err := scanContest(db.conn.QueryRowContext(ctx, `SELECT `+sqliteContestColumns+` FROM contests WHERE contest_id = ?`, contestId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrContestNotFound
+		}
+		return nil, fmt.Errorf("failed to get contest: %w", err)
+	}
+
+	return &contest, nil
+}
+
+// GetLeagueContests retrieves a league's contests, the ones whose entry closes first first
+func (db *SQLiteDB) GetLeagueContests(ctx context.Context, leagueId string) ([]models.Contest, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT `+sqliteContestColumns+` FROM contests WHERE league_id = ? ORDER BY entry_closes, contest_id`,
+		leagueId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query league contests: %w", err)
+	}
+	defer rows.Close()
+
+	contests := make([]models.Contest, 0)
+	for rows.Next() {
+		contest, err := scanContest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contest: %w", err)
+		}
+		contests = append(contests, contest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query league contests: %w", err)
+	}
+
+	return contests, nil
+}
+
+// EnterContest records a user's entry, failing if they have already entered
+func (db *SQLiteDB) EnterContest(ctx context.Context, entry *models.ContestEntry) error {
+	entry.EnteredAt = time.Now()
+
+	result, err := db.conn.ExecContext(ctx,
+		`INSERT INTO contest_entries (`+sqliteContestEntryColumns+`) VALUES (?, ?, ?) ON CONFLICT (contest_id, user_id) DO NOTHING`,
+		entry.ContestId, entry.UserId, entry.EnteredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enter contest: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to enter contest: %w", err)
+	}
+	if inserted == 0 {
+		return ErrAlreadyEnteredContest
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetContestEntries(ctx context.Context, contestId string) ([]models.ContestEntry, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT `+sqliteContestEntryColumns+` FROM contest_entries WHERE contest_id = ? ORDER BY user_id`,
+		contestId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query contest entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.ContestEntry, 0)
+	for rows.Next() {
+		var entry models.ContestEntry
+		if err := rows.Scan(&entry.ContestId, &entry.UserId, &entry.EnteredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contest entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query contest entries: %w", err)
+	}
+
+	return entries, nil
+}
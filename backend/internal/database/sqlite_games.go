@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const sqliteGameColumns = `game_id, date, game_day, home_team, home_team_id, away_team_id, away_team, home_score, away_score, status, winner,
+	innings, line_score, season, game_type, game_number, doubleheader, start_time_tbd, start_time_zone`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGame(row rowScanner) (models.Game, error) {
+	var game models.Game
+	var lineScore string
+	err := row.Scan(
+		&game.GameId, &game.Date, &game.GameDay, &game.HomeTeam, &game.HomeTeamId, &game.AwayTeamId, &game.AwayTeam,
+		&game.HomeScore, &game.AwayScore, &game.Status, &game.Winner, &game.Innings, &lineScore,
+		&game.Season, &game.GameType, &game.GameNumber, &game.Doubleheader, &game.StartTimeTBD, &game.StartTimeZone,
+	)
+	if err != nil {
+		return game, err
+	}
+
+	if lineScore != "" {
+		game.LineScore = &models.LineScore{}
+		if err := json.Unmarshal([]byte(lineScore), game.LineScore); err != nil {
+			return game, fmt.Errorf("failed to decode line score for game %s: %w", game.GameId, err)
+		}
+	}
+
+	return game, nil
+}
+
+// encodeLineScore stores the line score as JSON, or an empty string for games without one
+func encodeLineScore(lineScore *models.LineScore) (string, error) {
+	if lineScore == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(lineScore)
+	return string(data), err
+}
+
+// CreateGame stores a game, replacing any existing game with the same ID
+func (db *SQLiteDB) CreateGame(ctx context.Context, game *models.Game) error {
+	setGameDefaults(game)
+
+	lineScore, err := encodeLineScore(game.LineScore)
+	if err != nil {
+		return fmt.Errorf("failed to encode line score: %w", err)
+	}
+
+	_, err = db.conn.ExecContext(ctx,
+		`INSERT OR REPLACE INTO games (`+sqliteGameColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		game.GameId, game.Date, game.GameDay, game.HomeTeam, game.HomeTeamId, game.AwayTeamId, game.AwayTeam,
+		game.HomeScore, game.AwayScore, game.Status, game.Winner, game.Innings, lineScore,
+		game.Season, game.GameType, game.GameNumber, game.Doubleheader, game.StartTimeTBD, game.StartTimeZone,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create game: %w", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetGame(ctx context.Context, gameID string) (*models.Game, error) {
+	game, err := scanGame(db.conn.QueryRowContext(ctx, `SELECT `+sqliteGameColumns+` FROM games WHERE game_id = ?`, gameID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrGameNotFound
+		}
+		return nil, fmt.Errorf("failed to get game: %w", err)
+	}
+
+	return &game, nil
+}
+
+func (db *SQLiteDB) GetGamesByIds(ctx context.Context, gameIds []string) ([]models.Game, error) {
+	games := make([]models.Game, 0, len(gameIds))
+	// Stay well under SQLite's limit on bound parameters
+	for start := 0; start < len(gameIds); start += 500 {
+		chunk := gameIds[start:min(start+500, len(gameIds))]
+		args := make([]interface{}, len(chunk))
+		for i, gameId := range chunk {
+			args[i] = gameId
+		}
+
+		chunkGames, err := db.queryGames(ctx,
+			`SELECT `+sqliteGameColumns+` FROM games WHERE game_id IN (?`+strings.Repeat(`, ?`, len(chunk)-1)+`)`, args...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, chunkGames...)
+	}
+
+	sortGamesByDate(games)
+	return games, nil
+}
+
+func (db *SQLiteDB) GetUpcomingGames(ctx context.Context) ([]models.Game, error) {
+	return db.queryGames(ctx, `SELECT `+sqliteGameColumns+` FROM games WHERE status = ? ORDER BY date, game_id`, "upcoming")
+}
+
+func (db *SQLiteDB) GetGamesByDate(ctx context.Context, day string) ([]models.Game, error) {
+	return db.queryGames(ctx, `SELECT `+sqliteGameColumns+` FROM games WHERE game_day = ? ORDER BY date, game_id`, day)
+}
+
+func (db *SQLiteDB) GetGamesByDateRange(ctx context.Context, from, to string) ([]models.Game, error) {
+	if _, err := gameDaysBetween(from, to); err != nil {
+		return nil, err
+	}
+	return db.queryGames(ctx,
+		`SELECT `+sqliteGameColumns+` FROM games WHERE game_day BETWEEN ? AND ? ORDER BY date, game_id`, from, to,
+	)
+}
+
+func (db *SQLiteDB) GetGamesByTeam(ctx context.Context, teamId, from, to string) ([]models.Game, error) {
+	query := `SELECT ` + sqliteGameColumns + ` FROM games WHERE (home_team_id = ? OR away_team_id = ?)`
+	args := []interface{}{teamId, teamId}
+	if from != "" {
+		query += ` AND game_day >= ?`
+		args = append(args, from)
+	}
+	if to != "" {
+		query += ` AND game_day <= ?`
+		args = append(args, to)
+	}
+
+	return db.queryGames(ctx, query+` ORDER BY date, game_id`, args...)
+}
+
+func (db *SQLiteDB) queryGames(ctx context.Context, query string, args ...interface{}) ([]models.Game, error) {
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+	defer rows.Close()
+
+	games := make([]models.Game, 0)
+	for rows.Next() {
+		game, err := scanGame(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+		games = append(games, game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query games: %w", err)
+	}
+
+	return games, nil
+}
+
+func (db *SQLiteDB) UpdateGameResult(ctx context.Context, gameID, winner string) error {
+	result, err := db.conn.ExecContext(ctx,
+		`UPDATE games SET status = 'completed', winner = ? WHERE game_id = ?`, winner, gameID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update game: %w", err)
+	}
+
+	return requireRow(result, ErrGameNotFound)
+}
+
+// CompleteGame marks a game as completed and scores its predictions in a single transaction
+func (db *SQLiteDB) CompleteGame(ctx context.Context, gameId string, homeScore int, awayScore int, winnerId string) error {
+	return db.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE games SET status = 'completed', home_score = ?, away_score = ?, winner = ? WHERE game_id = ?`,
+			homeScore, awayScore, winnerId, gameId,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update game: %w", err)
+		}
+		if err := requireRow(result, ErrGameNotFound); err != nil {
+			return err
+		}
+
+		game, err := scanGame(tx.QueryRowContext(ctx, `SELECT `+sqliteGameColumns+` FROM games WHERE game_id = ?`, gameId))
+		if err != nil {
+			return fmt.Errorf("failed to get game: %w", err)
+		}
+
+		predictions, err := queryPredictions(ctx, tx, `SELECT `+sqlitePredictionColumns+` FROM predictions WHERE game_id = ?`, gameId)
+		if err != nil {
+			return fmt.Errorf("failed to get predictions: %w", err)
+		}
+
+		for _, prediction := range predictions {
+			scorePrediction(&prediction, &game)
+
+			_, err := tx.ExecContext(ctx,
+				`UPDATE predictions
+				SET actual_winner_id = ?, winner_correct = ?, home_score_error = ?, away_score_error = ?, total_score_error = ?,
+					season = ?, game_type = ?
+				WHERE user_id = ? AND game_id = ? AND model_id = ?`,
+				prediction.ActualWinnerId, *prediction.WinnerCorrect,
+				prediction.HomeScoreError, prediction.AwayScoreError, prediction.TotalScoreError,
+				prediction.Season, prediction.GameType,
+				prediction.UserId, prediction.GameId, prediction.ModelId,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to update prediction for user %s: %w", prediction.UserId, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// requireRow returns notFound when an UPDATE or DELETE matched nothing
+func requireRow(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
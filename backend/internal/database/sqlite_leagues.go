@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const (
+	sqliteLeagueColumns       = `league_id, name, owner_id, invite_code, settings, created_at`
+	sqliteLeagueMemberColumns = `league_id, user_id, role, joined_at`
+)
+
+func scanLeague(row rowScanner) (models.League, error) {
+	var league models.League
+	var settings string
+	if err := row.Scan(&league.LeagueId, &league.Name, &league.OwnerId, &league.InviteCode, &settings, &league.CreatedAt); err != nil {
+		return league, err
+	}
+
+	if err := json.Unmarshal([]byte(settings), &league.Settings); err != nil {
+		return league, fmt.Errorf("failed to decode settings for league %s: %w", league.LeagueId, err)
+	}
+
+	return league, nil
+}
+
+func scanLeagueMember(row rowScanner) (models.LeagueMember, error) {
+	var member models.LeagueMember
+	err := row.Scan(&member.LeagueId, &member.UserId, &member.Role, &member.JoinedAt)
+	return member, err
+}
+
+// CreateLeague stores a new league and makes its owner the first member, in one transaction
+func (db *SQLiteDB) CreateLeague(ctx context.Context, league *models.League) error {
+	league.CreatedAt = time.Now()
+
+	settings, err := json.Marshal(league.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode league settings: %w", err)
+	}
+
+	return db.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO leagues (`+sqliteLeagueColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+			league.LeagueId, league.Name, league.OwnerId, league.InviteCode, string(settings), league.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create league: %w", err)
+		}
+
+		owner := ownerMember(league)
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO league_members (`+sqliteLeagueMemberColumns+`) VALUES (?, ?, ?, ?)`,
+			owner.LeagueId, owner.UserId, owner.Role, owner.JoinedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add league owner: %w", err)
+		}
+		return nil
+	})
+}
+
+func (db *SQLiteDB) GetLeague(ctx context.Context, leagueId string) (*models.League, error) {
+	return db.getLeague(ctx, `league_id = ?`, leagueId)
+}
+
+func (db *SQLiteDB) GetLeagueByInviteCode(ctx context.Context, inviteCode string) (*models.League, error) {
+	return db.getLeague(ctx, `invite_code = ?`, inviteCode)
+}
+
+func (db *SQLiteDB) getLeague(ctx context.Context, where string, arg string) (*models.League, error) {
+	league, err := scanLeague(db.conn.QueryRowContext(ctx, `SELECT `+sqliteLeagueColumns+` FROM leagues WHERE `+where, arg))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLeagueNotFound
+		}
+		return nil, fmt.Errorf("failed to get league: %w", err)
+	}
+
+	return &league, nil
+}
+
+// UpdateLeague replaces an existing league's name, invite code and settings
+func (db *SQLiteDB) UpdateLeague(ctx context.Context, league *models.League) error {
+	settings, err := json.Marshal(league.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode league settings: %w", err)
+	}
+
+	result, err := db.conn.ExecContext(ctx,
+		`UPDATE leagues SET name = ?, invite_code = ?, settings = ? WHERE league_id = ?`,
+		league.Name, league.InviteCode, string(settings), league.LeagueId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update league: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update league: %w", err)
+	}
+	if updated == 0 {
+		return ErrLeagueNotFound
+	}
+
+	return nil
+}
+
+// GetUserLeagues retrieves every league userId is a member of
+func (db *SQLiteDB) GetUserLeagues(ctx context.Context, userId string) ([]models.League, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT `+sqliteLeagueColumns+` FROM leagues
+		WHERE league_id IN (SELECT league_id FROM league_members WHERE user_id = ?)
+		ORDER BY league_id`,
+		userId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user leagues: %w", err)
+	}
+	defer rows.Close()
+
+	leagues := make([]models.League, 0)
+	for rows.Next() {
+		league, err := scanLeague(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan league: %w", err)
+		}
+		leagues = append(leagues, league)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query user leagues: %w", err)
+	}
+
+	return leagues, nil
+}
+
+// AddLeagueMember adds a user to a league, failing if they are already a member
+func (db *SQLiteDB) AddLeagueMember(ctx context.Context, member *models.LeagueMember) error {
+	member.JoinedAt = time.Now()
+
+	result, err := db.conn.ExecContext(ctx,
+		`INSERT INTO league_members (`+sqliteLeagueMemberColumns+`) VALUES (?, ?, ?, ?) ON CONFLICT (league_id, user_id) DO NOTHING`,
+		member.LeagueId, member.UserId, member.Role, member.JoinedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add league member: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to add league member: %w", err)
+	}
+	if inserted == 0 {
+		return ErrAlreadyLeagueMember
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetLeagueMember(ctx context.Context, leagueId, userId string) (*models.LeagueMember, error) {
+	member, err := scanLeagueMember(db.conn.QueryRowContext(ctx,
+		`SELECT `+sqliteLeagueMemberColumns+` FROM league_members WHERE league_id = ? AND user_id = ?`,
+		leagueId, userId,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLeagueMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to get league member: %w", err)
+	}
+
+	return &member, nil
+}
+
+func (db *SQLiteDB) GetLeagueMembers(ctx context.Context, leagueId string) ([]models.LeagueMember, error) {
+	rows, err := db.conn.QueryContext(ctx,
+		`SELECT `+sqliteLeagueMemberColumns+` FROM league_members WHERE league_id = ? ORDER BY user_id`,
+		leagueId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query league members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]models.LeagueMember, 0)
+	for rows.Next() {
+		member, err := scanLeagueMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan league member: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query league members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (db *SQLiteDB) RemoveLeagueMember(ctx context.Context, leagueId, userId string) error {
+	result, err := db.conn.ExecContext(ctx,
+		`DELETE FROM league_members WHERE league_id = ? AND user_id = ?`,
+		leagueId, userId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove league member: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to remove league member: %w", err)
+	}
+	if removed == 0 {
+		return ErrLeagueMemberNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const sqliteModelColumns = `model_id, model_name, user_id, file_name, s3_key, status, status_reason, sha256,
+	size_bytes, content_type, framework, signature, features, version, changelog, promoted_at, created_at, updated_at`
+
+func scanModel(row rowScanner) (*models.ModelMetadata, error) {
+	var model models.ModelMetadata
+	var signature, features string
+	var promotedAt sql.NullTime
+	err := row.Scan(
+		&model.ModelId, &model.ModelName, &model.UserId, &model.FileName, &model.S3Key,
+		&model.Status, &model.StatusReason, &model.Sha256, &model.SizeBytes, &model.ContentType, &model.Framework, &signature,
+		&features, &model.Version, &model.Changelog, &promotedAt, &model.CreatedAt, &model.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if promotedAt.Valid {
+		model.PromotedAt = &promotedAt.Time
+	}
+
+	// Feature names are identifiers, so a comma-separated list is enough
+	if features != "" {
+		model.Features = strings.Split(features, ",")
+	}
+
+	if signature != "" {
+		model.Signature = &models.ModelSignature{}
+		if err := json.Unmarshal([]byte(signature), model.Signature); err != nil {
+			return nil, fmt.Errorf("failed to decode signature for model %s: %w", model.ModelId, err)
+		}
+	}
+
+	return &model, nil
+}
+
+// encodeSignature stores the ONNX signature as JSON, or an empty string for models without one
+func encodeSignature(signature *models.ModelSignature) (string, error) {
+	if signature == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(signature)
+	return string(data), err
+}
+
+func (db *SQLiteDB) CreateModel(ctx context.Context, model *models.ModelMetadata) error {
+	model.CreatedAt = time.Now()
+	model.UpdatedAt = time.Now()
+
+	signature, err := encodeSignature(model.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to encode model signature: %w", err)
+	}
+
+	result, err := db.conn.ExecContext(ctx,
+		`INSERT INTO models (`+sqliteModelColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, model_name, version) DO NOTHING`,
+		model.ModelId, model.ModelName, model.UserId, model.FileName, model.S3Key,
+		model.Status, model.StatusReason, model.Sha256, model.SizeBytes, model.ContentType, model.Framework, signature,
+		strings.Join(model.Features, ","), model.Version, model.Changelog, model.PromotedAt, model.CreatedAt, model.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert model: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to insert model: %w", err)
+	}
+	if inserted == 0 {
+		return ErrModelVersionTaken
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetModelById(ctx context.Context, modelId string, userId string) (*models.ModelMetadata, error) {
+	row := db.conn.QueryRowContext(ctx,
+		`SELECT `+sqliteModelColumns+` FROM models WHERE model_id = ? AND user_id = ?`, modelId, userId,
+	)
+
+	model, err := scanModel(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrModelNotFound
+		}
+		return nil, fmt.Errorf("failed to get model: %w", err)
+	}
+
+	return model, nil
+}
+
+func (db *SQLiteDB) GetModelsByUserId(ctx context.Context, userId string) ([]*models.ModelMetadata, error) {
+	modelList, _, err := db.GetModelsByUserIdPage(ctx, userId, PageRequest{})
+	return modelList, err
+}
+
+func (db *SQLiteDB) GetModelsByUserIdPage(ctx context.Context, userId string, page PageRequest) ([]*models.ModelMetadata, string, error) {
+	query, args, err := keysetPage(
+		`SELECT `+sqliteModelColumns+` FROM models WHERE user_id = ?`, []interface{}{userId}, "model_id", page,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	modelList, err := queryModels(ctx, db.conn, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	modelList, next := trimPage(modelList, func(model *models.ModelMetadata) string { return model.ModelId }, page)
+	return modelList, next, nil
+}
+
+func (db *SQLiteDB) GetModelsByStatus(ctx context.Context, status string) ([]*models.ModelMetadata, error) {
+	return queryModels(ctx, db.conn, `SELECT `+sqliteModelColumns+` FROM models WHERE status = ? ORDER BY model_id`, status)
+}
+
+func queryModels(ctx context.Context, q queryer, query string, args ...interface{}) ([]*models.ModelMetadata, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query models: %w", err)
+	}
+	defer rows.Close()
+
+	modelList := make([]*models.ModelMetadata, 0)
+	for rows.Next() {
+		model, err := scanModel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan model: %w", err)
+		}
+		modelList = append(modelList, model)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query models: %w", err)
+	}
+
+	return modelList, nil
+}
+
+func (db *SQLiteDB) DeleteModel(ctx context.Context, modelId string, userId string) error {
+	result, err := db.conn.ExecContext(ctx, `DELETE FROM models WHERE model_id = ? AND user_id = ?`, modelId, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete model: %w", err)
+	}
+
+	return requireRow(result, ErrModelNotFound)
+}
+
+// UpdateModel replaces an existing model's metadata, keeping its creation time
+func (db *SQLiteDB) UpdateModel(ctx context.Context, model *models.ModelMetadata) error {
+	model.UpdatedAt = time.Now()
+
+	signature, err := encodeSignature(model.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to encode model signature: %w", err)
+	}
+
+	result, err := db.conn.ExecContext(ctx,
+		`UPDATE models SET model_name = ?, file_name = ?, s3_key = ?, status = ?, status_reason = ?, sha256 = ?,
+			size_bytes = ?, content_type = ?, framework = ?, signature = ?, features = ?, version = ?, changelog = ?,
+			promoted_at = ?, updated_at = ?
+		WHERE model_id = ? AND user_id = ?`,
+		model.ModelName, model.FileName, model.S3Key, model.Status, model.StatusReason, model.Sha256,
+		model.SizeBytes, model.ContentType, model.Framework, signature, strings.Join(model.Features, ","), model.Version, model.Changelog, model.PromotedAt, model.UpdatedAt,
+		model.ModelId, model.UserId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update model: %w", err)
+	}
+
+	return requireRow(result, ErrModelNotFound)
+}
+
+func (db *SQLiteDB) UpdateModelStatus(ctx context.Context, modelId string, userId string, status string, reason string) error {
+	result, err := db.conn.ExecContext(ctx,
+		`UPDATE models SET status = ?, status_reason = ?, updated_at = ? WHERE model_id = ? AND user_id = ?`,
+		status, reason, time.Now(), modelId, userId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update model status: %w", err)
+	}
+
+	return requireRow(result, ErrModelNotFound)
+}
+
+func (db *SQLiteDB) GetModelVersions(ctx context.Context, userId string, modelName string) ([]*models.ModelMetadata, error) {
+	return queryModels(ctx, db.conn, modelVersionsQuery, userId, modelName)
+}
+
+const modelVersionsQuery = `SELECT ` + sqliteModelColumns + ` FROM models
+	WHERE user_id = ? AND model_name = ? ORDER BY version, created_at`
+
+// PromoteModel makes a validated version its family's active version and demotes the previous
+// active version back to validated, in one transaction
+func (db *SQLiteDB) PromoteModel(ctx context.Context, modelId string, userId string) error {
+	return db.withTx(ctx, func(tx *sql.Tx) error {
+		target, err := scanModel(tx.QueryRowContext(ctx,
+			`SELECT `+sqliteModelColumns+` FROM models WHERE model_id = ? AND user_id = ?`, modelId, userId,
+		))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrModelNotFound
+			}
+			return fmt.Errorf("failed to get model: %w", err)
+		}
+
+		versions, err := queryModels(ctx, tx, modelVersionsQuery, userId, target.ModelName)
+		if err != nil {
+			return err
+		}
+
+		demoted, err := promotionPlan(target, versions)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, model := range demoted {
+			_, err := tx.ExecContext(ctx,
+				`UPDATE models SET status = ?, updated_at = ? WHERE model_id = ?`,
+				models.ModelStatusValidated, now, model.ModelId,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to demote model %s: %w", model.ModelId, err)
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE models SET status = ?, status_reason = '', promoted_at = ?, updated_at = ? WHERE model_id = ?`,
+			models.ModelStatusActive, now, now, modelId,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to promote model: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (db *SQLiteDB) RecordModelUpload(ctx context.Context, userId string, day string, limit int) error {
+	result, err := db.conn.ExecContext(ctx,
+		`INSERT INTO model_uploads (user_id, day, uploads) VALUES (?, ?, 1)
+		ON CONFLICT (user_id, day) DO UPDATE SET uploads = uploads + 1 WHERE ? = 0 OR uploads < ?`,
+		userId, day, limit, limit,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record model upload: %w", err)
+	}
+
+	counted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record model upload: %w", err)
+	}
+	if counted == 0 {
+		return ErrUploadLimitReached
+	}
+	return nil
+}
+
+func (db *SQLiteDB) GetModelUploadCount(ctx context.Context, userId string, day string) (int, error) {
+	var count int
+	err := db.conn.QueryRowContext(ctx,
+		`SELECT uploads FROM model_uploads WHERE user_id = ? AND day = ?`, userId, day,
+	).Scan(&count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get model upload count: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const sqlitePredictionColumns = `user_id, game_id, model_id, home_score_predicted, away_score_predicted, total_score_predicted,
+	confidence, predicted_winner_id, actual_winner_id, winner_correct,
+	home_score_error, away_score_error, total_score_error, submitted_at, season, game_type`
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func scanPrediction(row rowScanner) (models.Prediction, error) {
+	var prediction models.Prediction
+	var winnerCorrect sql.NullBool
+
+	err := row.Scan(
+		&prediction.UserId, &prediction.GameId, &prediction.ModelId,
+		&prediction.HomeScorePredicted, &prediction.AwayScorePredicted, &prediction.TotalScorePredicted,
+		&prediction.Confidence, &prediction.PredictedWinnerId, &prediction.ActualWinnerId, &winnerCorrect,
+		&prediction.HomeScoreError, &prediction.AwayScoreError, &prediction.TotalScoreError, &prediction.SubmittedAt,
+		&prediction.Season, &prediction.GameType,
+	)
+	if winnerCorrect.Valid {
+		prediction.WinnerCorrect = &winnerCorrect.Bool
+	}
+	prediction.PredictionKey = PredictionSortKey(prediction.GameId, prediction.ModelId)
+
+	return prediction, err
+}
+
+func queryPredictions(ctx context.Context, q queryer, query string, args ...interface{}) ([]models.Prediction, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	predictions := make([]models.Prediction, 0)
+	for rows.Next() {
+		prediction, err := scanPrediction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prediction: %w", err)
+		}
+		predictions = append(predictions, prediction)
+	}
+
+	return predictions, rows.Err()
+}
+
+// upsertPrediction replaces any earlier prediction for the same user, game and model, like a DynamoDB PutItem
+func upsertPrediction(ctx context.Context, tx *sql.Tx, prediction models.Prediction) error {
+	var winnerCorrect sql.NullBool
+	if prediction.WinnerCorrect != nil {
+		winnerCorrect = sql.NullBool{Bool: *prediction.WinnerCorrect, Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO predictions (`+sqlitePredictionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		prediction.UserId, prediction.GameId, prediction.ModelId,
+		prediction.HomeScorePredicted, prediction.AwayScorePredicted, prediction.TotalScorePredicted,
+		prediction.Confidence, prediction.PredictedWinnerId, prediction.ActualWinnerId, winnerCorrect,
+		prediction.HomeScoreError, prediction.AwayScoreError, prediction.TotalScoreError, prediction.SubmittedAt,
+		prediction.Season, prediction.GameType,
+	)
+	return err
+}
+
+func (db *SQLiteDB) CreatePrediction(ctx context.Context, prediction *models.Prediction) error {
+	prediction.SubmittedAt = time.Now()
+
+	err := db.withTx(ctx, func(tx *sql.Tx) error {
+		return upsertPrediction(ctx, tx, *prediction)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create prediction: %w", err)
+	}
+
+	return nil
+}
+
+// BatchCreatePredictions writes all predictions atomically
+func (db *SQLiteDB) BatchCreatePredictions(ctx context.Context, predictions []models.Prediction) error {
+	now := time.Now()
+
+	err := db.withTx(ctx, func(tx *sql.Tx) error {
+		for _, prediction := range predictions {
+			prediction.SubmittedAt = now
+			if err := upsertPrediction(ctx, tx, prediction); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to batch write predictions: %w", err)
+	}
+
+	return nil
+}
+
+// GetPredictionByUser returns nil without an error when the prediction doesn't exist
+func (db *SQLiteDB) GetPredictionByUser(ctx context.Context, userId, gameId, modelId string) (*models.Prediction, error) {
+	row := db.conn.QueryRowContext(ctx,
+		`SELECT `+sqlitePredictionColumns+` FROM predictions WHERE user_id = ? AND game_id = ? AND model_id = ?`,
+		userId, gameId, modelId,
+	)
+
+	prediction, err := scanPrediction(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get prediction: %w", err)
+	}
+
+	return &prediction, nil
+}
+
+func (db *SQLiteDB) GetUserPredictions(ctx context.Context, userId string) ([]models.Prediction, error) {
+	predictions, _, err := db.GetUserPredictionsPage(ctx, userId, PageRequest{})
+	return predictions, err
+}
+
+func (db *SQLiteDB) GetUserPredictionsPage(ctx context.Context, userId string, page PageRequest) ([]models.Prediction, string, error) {
+	query, args, err := keysetPage(
+		`SELECT `+sqlitePredictionColumns+` FROM predictions WHERE user_id = ?`, []interface{}{userId}, "game_id || '#' || model_id", page,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	predictions, err := queryPredictions(ctx, db.conn, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query predictions: %w", err)
+	}
+
+	predictions, next := trimPage(predictions, func(p models.Prediction) string { return p.GameId + "#" + p.ModelId }, page)
+	return predictions, next, nil
+}
+
+func (db *SQLiteDB) GetPredictionsByGame(ctx context.Context, gameId string) ([]models.Prediction, error) {
+	predictions, _, err := db.GetPredictionsByGamePage(ctx, gameId, PageRequest{})
+	return predictions, err
+}
+
+func (db *SQLiteDB) GetPredictionsByGamePage(ctx context.Context, gameId string, page PageRequest) ([]models.Prediction, string, error) {
+	query, args, err := keysetPage(
+		`SELECT `+sqlitePredictionColumns+` FROM predictions WHERE game_id = ?`, []interface{}{gameId}, "user_id || '#' || model_id", page,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	predictions, err := queryPredictions(ctx, db.conn, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query predictions: %w", err)
+	}
+
+	predictions, next := trimPage(predictions, func(p models.Prediction) string { return p.UserId + "#" + p.ModelId }, page)
+	return predictions, next, nil
+}
+
+// GetPredictionsByGames reads predictions for several games in a single query, keyed by gameId
+func (db *SQLiteDB) GetPredictionsByGames(ctx context.Context, gameIds []string) (map[string][]models.Prediction, error) {
+	results := make(map[string][]models.Prediction, len(gameIds))
+	if len(gameIds) == 0 {
+		return results, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(gameIds)), ", ")
+	args := make([]interface{}, len(gameIds))
+	for i, gameId := range gameIds {
+		args[i] = gameId
+	}
+
+	predictions, err := queryPredictions(ctx, db.conn,
+		`SELECT `+sqlitePredictionColumns+` FROM predictions WHERE game_id IN (`+placeholders+`) ORDER BY game_id, user_id, model_id`, args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query predictions: %w", err)
+	}
+
+	for _, prediction := range predictions {
+		results[prediction.GameId] = append(results[prediction.GameId], prediction)
+	}
+
+	return results, nil
+}
+
+// GetModelPredictions retrieves every prediction made by a model
+func (db *SQLiteDB) GetModelPredictions(ctx context.Context, modelId string) ([]models.Prediction, error) {
+	predictions, err := queryPredictions(ctx, db.conn,
+		`SELECT `+sqlitePredictionColumns+` FROM predictions WHERE model_id = ? ORDER BY game_id`, modelId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query model predictions: %w", err)
+	}
+
+	return predictions, nil
+}
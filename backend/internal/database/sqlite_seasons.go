@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const sqliteSeasonColumns = `year, phases, round_weights`
+
+func scanSeason(row rowScanner) (models.Season, error) {
+	var season models.Season
+	var phases, roundWeights string
+	if err := row.Scan(&season.Year, &phases, &roundWeights); err != nil {
+		return season, err
+	}
+
+	if err := json.Unmarshal([]byte(phases), &season.Phases); err != nil {
+		return season, fmt.Errorf("failed to decode phases for season %d: %w", season.Year, err)
+	}
+	if roundWeights != "" {
+		if err := json.Unmarshal([]byte(roundWeights), &season.RoundWeights); err != nil {
+			return season, fmt.Errorf("failed to decode round weights for season %d: %w", season.Year, err)
+		}
+	}
+
+	return season, nil
+}
+
+// PutSeason stores a season, replacing any existing season for the same year
+func (db *SQLiteDB) PutSeason(ctx context.Context, season *models.Season) error {
+	phases, err := json.Marshal(season.Phases)
+	if err != nil {
+		return fmt.Errorf("failed to encode phases: %w", err)
+	}
+
+	roundWeights := ""
+	if len(season.RoundWeights) > 0 {
+		data, err := json.Marshal(season.RoundWeights)
+		if err != nil {
+			return fmt.Errorf("failed to encode round weights: %w", err)
+		}
+		roundWeights = string(data)
+	}
+
+	_, err = db.conn.ExecContext(ctx,
+		`INSERT OR REPLACE INTO seasons (`+sqliteSeasonColumns+`) VALUES (?, ?, ?)`,
+		season.Year, string(phases), roundWeights,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to put season: %w", err)
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetSeason(ctx context.Context, year int) (*models.Season, error) {
+	season, err := scanSeason(db.conn.QueryRowContext(ctx, `SELECT `+sqliteSeasonColumns+` FROM seasons WHERE year = ?`, year))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSeasonNotFound
+		}
+		return nil, fmt.Errorf("failed to get season: %w", err)
+	}
+
+	return &season, nil
+}
+
+func (db *SQLiteDB) ListSeasons(ctx context.Context) ([]models.Season, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+sqliteSeasonColumns+` FROM seasons ORDER BY year`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seasons: %w", err)
+	}
+	defer rows.Close()
+
+	seasons := make([]models.Season, 0)
+	for rows.Next() {
+		season, err := scanSeason(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan season: %w", err)
+		}
+		seasons = append(seasons, season)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query seasons: %w", err)
+	}
+
+	return seasons, nil
+}
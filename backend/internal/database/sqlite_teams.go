@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const sqliteTeamColumns = `team_id, name, abbreviation, league, division, logo_url, venue_id, venue_name, venue_city, venue_time_zone`
+
+func scanTeam(row rowScanner) (models.Team, error) {
+	var team models.Team
+	err := row.Scan(
+		&team.Id, &team.Name, &team.Abbreviation, &team.League, &team.Division, &team.LogoURL,
+		&team.Venue.Id, &team.Venue.Name, &team.Venue.City, &team.Venue.TimeZone,
+	)
+	return team, err
+}
+
+// PutTeams stores teams, replacing any existing team with the same ID
+func (db *SQLiteDB) PutTeams(ctx context.Context, teams []models.Team) error {
+	return db.withTx(ctx, func(tx *sql.Tx) error {
+		for _, team := range teams {
+			_, err := tx.ExecContext(ctx,
+				`INSERT OR REPLACE INTO teams (`+sqliteTeamColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				team.Id, team.Name, team.Abbreviation, team.League, team.Division, team.LogoURL,
+				team.Venue.Id, team.Venue.Name, team.Venue.City, team.Venue.TimeZone,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to store team %s: %w", team.Id, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (db *SQLiteDB) GetTeam(ctx context.Context, teamId string) (*models.Team, error) {
+	team, err := scanTeam(db.conn.QueryRowContext(ctx, `SELECT `+sqliteTeamColumns+` FROM teams WHERE team_id = ?`, teamId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	return &team, nil
+}
+
+func (db *SQLiteDB) ListTeams(ctx context.Context) ([]models.Team, error) {
+	rows, err := db.conn.QueryContext(ctx, `SELECT `+sqliteTeamColumns+` FROM teams ORDER BY division, name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make([]models.Team, 0)
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+
+	return teams, nil
+}
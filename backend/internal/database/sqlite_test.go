@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// openSQLite opens the SQLite database at path, closing it when the test ends
+func openSQLite(t *testing.T, path string) *SQLiteDB {
+	t.Helper()
+	db, err := NewSQLiteDB(context.Background(), path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func schemaVersions(t *testing.T, db *SQLiteDB) []int {
+	t.Helper()
+	rows, err := db.conn.Query(`SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+func TestSQLiteMigrationsRunOnce(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "pool.db")
+
+	db := openSQLite(t, path)
+	want := make([]int, len(sqliteMigrations))
+	for i := range want {
+		want[i] = i + 1
+	}
+	if got := schemaVersions(t, db); !slices.Equal(got, want) {
+		t.Fatalf("schema versions = %v, want %v", got, want)
+	}
+	if err := db.CreateUser(ctx, &models.User{Id: "u1", Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// Reopening finds every migration applied, so none runs again and the data survives
+	reopened := openSQLite(t, path)
+	if got := schemaVersions(t, reopened); !slices.Equal(got, want) {
+		t.Errorf("schema versions after reopening = %v, want %v", got, want)
+	}
+	user, err := reopened.GetUser(ctx, "u1")
+	if err != nil || user.Username != "alice" {
+		t.Errorf("GetUser() after reopening = %+v, %v, want alice", user, err)
+	}
+}
+
+// TestSQLiteMatchesMemory runs the standings fixture through both stores and expects the same games,
+// models, scored predictions and leaderboards back
+func TestSQLiteMatchesMemory(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t, filepath.Join(t.TempDir(), "pool.db"))
+	seedStandings(t, db)
+	memory := NewMemoryDB()
+	seedStandings(t, memory)
+
+	for _, gameId := range []string{"g1", "g2", "g3", "g4"} {
+		got, err := db.GetGame(ctx, gameId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := memory.GetGame(ctx, gameId)
+		if !got.Date.Equal(want.Date) {
+			t.Errorf("game %s date = %v, want %v", gameId, got.Date, want.Date)
+		}
+		got.Date = want.Date
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("game %s = %+v, want %+v", gameId, got, want)
+		}
+	}
+
+	model, err := db.GetModelById(ctx, "m2", "u2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.ModelName != "elo" || model.Status != models.ModelStatusActive || model.CreatedAt.IsZero() {
+		t.Errorf("GetModelById(m2) = %+v, want u2's active elo", model)
+	}
+
+	for _, userId := range []string{"u1", "u2", "u3"} {
+		got, err := db.GetUserPredictions(ctx, userId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := memory.GetUserPredictions(ctx, userId)
+		if len(got) != len(want) {
+			t.Fatalf("%s has %d predictions, want %d", userId, len(got), len(want))
+		}
+
+		byKey := make(map[string]models.Prediction, len(want))
+		for _, prediction := range want {
+			byKey[prediction.PredictionKey] = prediction
+		}
+		for _, prediction := range got {
+			expected := byKey[prediction.PredictionKey]
+			if prediction.SubmittedAt.IsZero() || prediction.WinnerCorrect == nil {
+				t.Errorf("%s/%s wasn't submitted and scored: %+v", userId, prediction.PredictionKey, prediction)
+				continue
+			}
+			prediction.SubmittedAt, expected.SubmittedAt = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(prediction, expected) {
+				t.Errorf("%s/%s = %+v, want %+v", userId, prediction.PredictionKey, prediction, expected)
+			}
+		}
+	}
+
+	for _, scope := range []LeaderboardScope{
+		{Season: 2025},
+		{Season: 2025, RoundWeights: map[string]float32{models.GameTypeWorldSeries: 4}},
+		{GameIds: map[string]bool{"g3": true}},
+	} {
+		got, err := db.CalculateLeaderboard(ctx, scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := memory.CalculateLeaderboard(ctx, scope)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("CalculateLeaderboard(%+v) = %+v, want %+v", scope, got, want)
+		}
+	}
+
+	got, err := db.CalculateModelLeaderboard(ctx, LeaderboardScope{Season: 2025})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := memory.CalculateModelLeaderboard(ctx, LeaderboardScope{Season: 2025})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CalculateModelLeaderboard() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+const sqliteUserColumns = `user_id, username, email, created_at`
+
+// CreateUser adds a new user, failing if the userId is already taken
+func (db *SQLiteDB) CreateUser(ctx context.Context, user *models.User) error {
+	user.CreatedAt = time.Now()
+
+	result, err := db.conn.ExecContext(ctx,
+		`INSERT INTO users (`+sqliteUserColumns+`) VALUES (?, ?, ?, ?) ON CONFLICT (user_id) DO NOTHING`,
+		user.Id, user.Username, user.Email, user.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+	if inserted == 0 {
+		return ErrUserAlreadyExists
+	}
+
+	return nil
+}
+
+func (db *SQLiteDB) GetUser(ctx context.Context, userId string) (*models.User, error) {
+	row := db.conn.QueryRowContext(ctx, `SELECT `+sqliteUserColumns+` FROM users WHERE user_id = ?`, userId)
+
+	var user models.User
+	if err := row.Scan(&user.Id, &user.Username, &user.Email, &user.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (db *SQLiteDB) GetUsersByIds(ctx context.Context, userIds []string) ([]*models.User, error) {
+	users := make([]*models.User, 0, len(userIds))
+	// Stay well under SQLite's limit on bound parameters
+	for start := 0; start < len(userIds); start += 500 {
+		chunk := userIds[start:min(start+500, len(userIds))]
+		args := make([]interface{}, len(chunk))
+		for i, userId := range chunk {
+			args[i] = userId
+		}
+
+		rows, err := db.conn.QueryContext(ctx,
+			`SELECT `+sqliteUserColumns+` FROM users WHERE user_id IN (?`+strings.Repeat(`, ?`, len(chunk)-1)+`)`, args...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+		for rows.Next() {
+			var user models.User
+			if err := rows.Scan(&user.Id, &user.Username, &user.Email, &user.CreatedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan user: %w", err)
+			}
+			users = append(users, &user)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+	}
+
+	sortUsers(users)
+	return users, nil
+}
+
+func (db *SQLiteDB) ListUsers(ctx context.Context) ([]*models.User, error) {
+	users, _, err := db.ListUsersPage(ctx, PageRequest{})
+	return users, err
+}
+
+func (db *SQLiteDB) ListUsersPage(ctx context.Context, page PageRequest) ([]*models.User, string, error) {
+	query, args, err := keysetPage(`SELECT `+sqliteUserColumns+` FROM users WHERE 1 = 1`, nil, "user_id", page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.Id, &user.Username, &user.Email, &user.CreatedAt); err != nil {
+			return nil, "", fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to list users: %w", err)
+	}
+
+	users, next := trimPage(users, func(u *models.User) string { return u.Id }, page)
+	return users, next, nil
+}
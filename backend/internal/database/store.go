@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// UserStore persists user profiles
+type UserStore interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUser(ctx context.Context, userId string) (*models.User, error)
+	GetUsersByIds(ctx context.Context, userIds []string) ([]*models.User, error)
+	ListUsers(ctx context.Context) ([]*models.User, error)
+	ListUsersPage(ctx context.Context, page PageRequest) ([]*models.User, string, error)
+}
+
+// GameStore persists the game schedule and results
+type GameStore interface {
+	CreateGame(ctx context.Context, game *models.Game) error
+	GetGame(ctx context.Context, gameID string) (*models.Game, error)
+	GetGamesByIds(ctx context.Context, gameIds []string) ([]models.Game, error)
+	GetUpcomingGames(ctx context.Context) ([]models.Game, error)
+	GetGamesByDate(ctx context.Context, day string) ([]models.Game, error)
+	GetGamesByDateRange(ctx context.Context, from, to string) ([]models.Game, error)
+	GetGamesByTeam(ctx context.Context, teamId, from, to string) ([]models.Game, error)
+	UpdateGameResult(ctx context.Context, gameID, winner string) error
+	CompleteGame(ctx context.Context, gameId string, homeScore int, awayScore int, winnerId string) error
+}
+
+// TeamStore persists the team reference data games point at
+type TeamStore interface {
+	PutTeams(ctx context.Context, teams []models.Team) error
+	GetTeam(ctx context.Context, teamId string) (*models.Team, error)
+	ListTeams(ctx context.Context) ([]models.Team, error)
+}
+
+// SeasonStore persists each season's calendar and scoring settings
+type SeasonStore interface {
+	PutSeason(ctx context.Context, season *models.Season) error
+	GetSeason(ctx context.Context, year int) (*models.Season, error)
+	ListSeasons(ctx context.Context) ([]models.Season, error)
+}
+
+// LeagueStore persists private leagues and their members
+type LeagueStore interface {
+	// CreateLeague stores a new league with its owner as the first member
+	CreateLeague(ctx context.Context, league *models.League) error
+	GetLeague(ctx context.Context, leagueId string) (*models.League, error)
+	GetLeagueByInviteCode(ctx context.Context, inviteCode string) (*models.League, error)
+	UpdateLeague(ctx context.Context, league *models.League) error
+	GetUserLeagues(ctx context.Context, userId string) ([]models.League, error)
+	AddLeagueMember(ctx context.Context, member *models.LeagueMember) error
+	GetLeagueMember(ctx context.Context, leagueId, userId string) (*models.LeagueMember, error)
+	GetLeagueMembers(ctx context.Context, leagueId string) ([]models.LeagueMember, error)
+	RemoveLeagueMember(ctx context.Context, leagueId, userId string) error
+}
+
+// ContestStore persists league contests and who has entered them
+type ContestStore interface {
+	CreateContest(ctx context.Context, contest *models.Contest) error
+	GetContest(ctx context.Context, contestId string) (*models.Contest, error)
+	GetLeagueContests(ctx context.Context, leagueId string) ([]models.Contest, error)
+	EnterContest(ctx context.Context, entry *models.ContestEntry) error
+	GetContestEntries(ctx context.Context, contestId string) ([]models.ContestEntry, error)
+}
+
+// PredictionStore persists predictions, keyed by userId + gameId + modelId
+type PredictionStore interface {
+	CreatePrediction(ctx context.Context, prediction *models.Prediction) error
+	BatchCreatePredictions(ctx context.Context, predictions []models.Prediction) error
+	GetPredictionByUser(ctx context.Context, userId, gameId, modelId string) (*models.Prediction, error)
+	GetUserPredictions(ctx context.Context, userId string) ([]models.Prediction, error)
+	GetUserPredictionsPage(ctx context.Context, userId string, page PageRequest) ([]models.Prediction, string, error)
+	GetPredictionsByGame(ctx context.Context, gameId string) ([]models.Prediction, error)
+	GetPredictionsByGamePage(ctx context.Context, gameId string, page PageRequest) ([]models.Prediction, string, error)
+	GetPredictionsByGames(ctx context.Context, gameIds []string) (map[string][]models.Prediction, error)
+	GetModelPredictions(ctx context.Context, modelId string) ([]models.Prediction, error)
+}
+
+// ModelStore persists metadata for uploaded models
+type ModelStore interface {
+	// CreateModel stores a new model, or returns ErrModelVersionTaken if the user already has
+	// a model with the same name and version. Deleting a model frees its version.
+	CreateModel(ctx context.Context, model *models.ModelMetadata) error
+	GetModelById(ctx context.Context, modelId string, userId string) (*models.ModelMetadata, error)
+	GetModelsByUserId(ctx context.Context, userId string) ([]*models.ModelMetadata, error)
+	GetModelsByUserIdPage(ctx context.Context, userId string, page PageRequest) ([]*models.ModelMetadata, string, error)
+	GetModelsByStatus(ctx context.Context, status string) ([]*models.ModelMetadata, error)
+	DeleteModel(ctx context.Context, modelId string, userId string) error
+	UpdateModel(ctx context.Context, model *models.ModelMetadata) error
+	UpdateModelStatus(ctx context.Context, modelId string, userId string, status string, reason string) error
+	GetModelVersions(ctx context.Context, userId string, modelName string) ([]*models.ModelMetadata, error)
+	PromoteModel(ctx context.Context, modelId string, userId string) error
+	// RecordModelUpload counts one upload against the user's day, or returns ErrUploadLimitReached
+	// without counting it if the day already has limit uploads; 0 means no limit. The check and
+	// the count are one write, so concurrent uploads can't both take the last slot. Counts only
+	// ever go up, so deleting a model doesn't give its upload back.
+	RecordModelUpload(ctx context.Context, userId string, day string, limit int) error
+	GetModelUploadCount(ctx context.Context, userId string, day string) (int, error)
+}
+
+// LeaderboardStore computes standings from stored predictions, within a LeaderboardScope
+type LeaderboardStore interface {
+	CalculateLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error)
+	GetUserStats(ctx context.Context, userId string, scope LeaderboardScope) (*models.LeaderboardEntry, error)
+	CalculateModelLeaderboard(ctx context.Context, scope LeaderboardScope) ([]models.LeaderboardEntry, error)
+	GetModelStats(ctx context.Context, modelId string, scope LeaderboardScope) (*models.LeaderboardEntry, error)
+}
+
+// Store is everything the API needs from a storage backend.
+// DB is the DynamoDB implementation, SQLiteDB the self-hosted one and MemoryDB the in-memory one used in tests.
+type Store interface {
+	UserStore
+	GameStore
+	TeamStore
+	SeasonStore
+	LeagueStore
+	ContestStore
+	PredictionStore
+	ModelStore
+	LeaderboardStore
+
+	HealthCheck(ctx context.Context) error
+	Close() error
+}
+
+var (
+	_ Store = (*DB)(nil)
+	_ Store = (*SQLiteDB)(nil)
+	_ Store = (*MemoryDB)(nil)
+)
+
+// NewStoreFromEnv picks the storage backend from STORAGE_BACKEND ("dynamodb" or "sqlite").
+// The SQLite file location is read from SQLITE_PATH.
+func NewStoreFromEnv(ctx context.Context) (Store, error) {
+	switch backend := getEnv("STORAGE_BACKEND", "dynamodb"); backend {
+	case "dynamodb":
+		db, err := NewDBFromEnv(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case "sqlite":
+		db, err := NewSQLiteDB(ctx, getEnv("SQLITE_PATH", "mlb-prediction-pool.db"))
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
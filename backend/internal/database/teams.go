@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+var ErrTeamNotFound = errors.New("team not found")
+
+// PutTeams stores teams, replacing any existing team with the same ID
+func (db *DB) PutTeams(ctx context.Context, teams []models.Team) error {
+	const batchSize = 25 // DynamoDB batch write limit
+
+	for i := 0; i < len(teams); i += batchSize {
+		end := i + batchSize
+		if end > len(teams) {
+			end = len(teams)
+		}
+
+		writeRequests := make([]types.WriteRequest, 0, end-i)
+		for _, team := range teams[i:end] {
+			item, err := attributevalue.MarshalMap(team)
+			if err != nil {
+				return fmt.Errorf("failed to marshal team: %w", err)
+			}
+			writeRequests = append(writeRequests, types.WriteRequest{
+				PutRequest: &types.PutRequest{Item: item},
+			})
+		}
+
+		_, err := db.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{
+				db.teamsTable: writeRequests,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to batch write teams: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetTeam retrieves a team by its Stats API ID
+func (db *DB) GetTeam(ctx context.Context, teamId string) (*models.Team, error) {
+	result, err := db.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(db.teamsTable),
+		Key: map[string]types.AttributeValue{
+			"teamId": &types.AttributeValueMemberS{Value: teamId},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, ErrTeamNotFound
+	}
+
+	var team models.Team
+	if err := attributevalue.UnmarshalMap(result.Item, &team); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal team: %w", err)
+	}
+
+	return &team, nil
+}
+
+// ListTeams retrieves every team ordered by division then name.
+// There are only 30 teams, so a scan is fine.
+func (db *DB) ListTeams(ctx context.Context) ([]models.Team, error) {
+	items, err := db.scanAll(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(db.teamsTable),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan teams: %w", err)
+	}
+
+	teams := make([]models.Team, 0, len(items))
+	for _, item := range items {
+		var team models.Team
+		if err := attributevalue.UnmarshalMap(item, &team); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal team: %w", err)
+		}
+		teams = append(teams, team)
+	}
+
+	sortTeams(teams)
+	return teams, nil
+}
+
+func sortTeams(teams []models.Team) {
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].Division != teams[j].Division {
+			return teams[i].Division < teams[j].Division
+		}
+		return teams[i].Name < teams[j].Name
+	})
+}
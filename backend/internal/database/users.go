@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -70,21 +72,89 @@ func (db *DB) GetUser(ctx context.Context, userId string) (*models.User, error)
 	return &user, nil
 }
 
+// GetUsersByIds retrieves the listed users, ordered by userId. Ids without a user are skipped.
+func (db *DB) GetUsersByIds(ctx context.Context, userIds []string) ([]*models.User, error) {
+	keys := make([]item, 0, len(userIds))
+	seen := make(map[string]bool, len(userIds))
+	for _, userId := range userIds {
+		// BatchGetItem rejects a request that names the same key twice
+		if seen[userId] {
+			continue
+		}
+		seen[userId] = true
+		keys = append(keys, item{"userId": &types.AttributeValueMemberS{Value: userId}})
+	}
+
+	items, err := db.batchGetAll(ctx, db.usersTable, keys)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to batch get users from DynamoDB: %w", err)
+	}
+
+	users, err := unmarshalUsers(items)
+	if err != nil {
+		return nil, err
+	}
+	sortUsers(users)
+	return users, nil
+}
+
+// GetUsersPage reads one page of the listed users, ordered by userId. The cursor pages through
+// userIds rather than the table, so a page only comes back short when ids have no user.
+func GetUsersPage(ctx context.Context, db UserStore, userIds []string, page PageRequest) ([]*models.User, string, error) {
+	ids, next, err := pageSlice(slices.Clone(userIds), func(id string) string { return id }, page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	users, err := db.GetUsersByIds(ctx, ids)
+	if err != nil {
+		return nil, "", err
+	}
+	return users, next, nil
+}
+
+func sortUsers(users []*models.User) {
+	sort.Slice(users, func(i, j int) bool { return users[i].Id < users[j].Id })
+}
+
 // ListUsers retrieves all users from the Users table
 func (db *DB) ListUsers(ctx context.Context) ([]*models.User, error) {
 	input := &dynamodb.ScanInput{
 		TableName: aws.String(db.usersTable),
 	}
 
-	result, err := db.client.Scan(ctx, input)
+	items, err := db.scanAll(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to scan DynamoDB: %w", err)
 	}
 
-	users := make([]*models.User, 0, len(result.Items))
-	for _, item := range result.Items {
+	return unmarshalUsers(items)
+}
+
+// ListUsersPage retrieves one page of users from the Users table
+func (db *DB) ListUsersPage(ctx context.Context, page PageRequest) ([]*models.User, string, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(db.usersTable),
+	}
+
+	items, next, err := db.scanPage(ctx, input, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed to scan DynamoDB: %w", err)
+	}
+
+	users, err := unmarshalUsers(items)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return users, next, nil
+}
+
+func unmarshalUsers(items []item) ([]*models.User, error) {
+	users := make([]*models.User, 0, len(items))
+	for _, item := range items {
 		var user models.User
-		err = attributevalue.UnmarshalMap(item, &user)
+		err := attributevalue.UnmarshalMap(item, &user)
 		if err != nil {
 			return nil, fmt.Errorf("Failed to unmarshal user: %w", err)
 		}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestGetUsersPage(t *testing.T) {
+	ctx := context.Background()
+	for name, db := range map[string]Store{
+		"memory": NewMemoryDB(),
+		"sqlite": openSQLite(t, filepath.Join(t.TempDir(), "pool.db")),
+	} {
+		for _, id := range []string{"u1", "u2", "u3", "u4"} {
+			if err := db.CreateUser(ctx, &models.User{Id: id, Username: id, Email: id + "@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// u4 isn't asked for, and "gone" has no user so the page it falls on comes back short
+		userIds := []string{"u3", "gone", "u1", "u2"}
+		var got []string
+		cursor := ""
+		for pages := 0; pages < 5; pages++ {
+			users, next, err := GetUsersPage(ctx, db, userIds, PageRequest{Limit: 2, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("%s: GetUsersPage() error = %v", name, err)
+			}
+			for _, user := range users {
+				got = append(got, user.Id)
+			}
+			if cursor = next; cursor == "" {
+				break
+			}
+		}
+		if !slices.Equal(got, []string{"u1", "u2", "u3"}) {
+			t.Errorf("%s: GetUsersPage() = %v, want [u1 u2 u3]", name, got)
+		}
+	}
+}
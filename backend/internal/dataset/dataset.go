@@ -0,0 +1,207 @@
+// Package dataset exports completed games joined with the team stats known on game day,
+// as training data for model authors
+package dataset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/stats"
+)
+
+var ErrInvalidQuery = errors.New("invalid dataset query")
+
+// Query selects the games to export: a season, a from/to range of game days, or a range within a season
+type Query struct {
+	Season int
+	From   string
+	To     string
+}
+
+// Bounds resolves the query to an inclusive range of game days
+func (q Query) Bounds() (string, string, error) {
+	from, to := q.From, q.To
+
+	if q.Season != 0 {
+		seasonStart := fmt.Sprintf("%04d-01-01", q.Season)
+		seasonEnd := fmt.Sprintf("%04d-12-31", q.Season)
+		if from == "" || from < seasonStart {
+			from = seasonStart
+		}
+		if to == "" || to > seasonEnd {
+			to = seasonEnd
+		}
+	}
+
+	if from == "" || to == "" {
+		return "", "", fmt.Errorf("%w: a season or both from and to are required", ErrInvalidQuery)
+	}
+	for _, day := range []string{from, to} {
+		if _, err := time.Parse(models.GameDayLayout, day); err != nil {
+			return "", "", fmt.Errorf("%w: invalid date %q, expected YYYY-MM-DD", ErrInvalidQuery, day)
+		}
+	}
+	if to < from {
+		return "", "", fmt.Errorf("%w: from must not be after to", ErrInvalidQuery)
+	}
+
+	return from, to, nil
+}
+
+// Kind is the type of a column's values
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindBool
+)
+
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// Dataset is a table of rows whose values line up with Columns. A nil value is missing.
+type Dataset struct {
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+// gameColumns describe each game and its result; stat columns follow them
+var gameColumns = []Column{
+	{"game_id", KindString},
+	{"game_day", KindString},
+	{"start_time", KindString},
+	{"season", KindInt},
+	{"game_type", KindString},
+	{"home_team_id", KindString},
+	{"home_team", KindString},
+	{"away_team_id", KindString},
+	{"away_team", KindString},
+	{"home_score", KindInt},
+	{"away_score", KindInt},
+	{"total_runs", KindInt},
+	{"innings", KindInt},
+	{"winner_id", KindString},
+	{"home_win", KindBool},
+}
+
+// Exporter builds datasets from the game store and, when Stats is set, the team stat snapshots
+type Exporter struct {
+	Games database.GameStore
+	Stats *stats.TeamStats
+}
+
+func NewExporter(games database.GameStore, teamStats *stats.TeamStats) *Exporter {
+	return &Exporter{Games: games, Stats: teamStats}
+}
+
+// Build exports every completed game in the query's range, oldest first.
+// Each game gets the snapshot AsOf would serve for its game day, so no stats from after the game leak in.
+func (e *Exporter) Build(ctx context.Context, query Query) (*Dataset, error) {
+	from, to, err := query.Bounds()
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := e.completedGames(ctx, from, to, query.Season)
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := e.loadFeatures(ctx, games)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := &Dataset{Columns: append([]Column(nil), gameColumns...)}
+	for _, category := range features {
+		dataset.Columns = append(dataset.Columns, category.columns()...)
+	}
+
+	for _, game := range games {
+		row := gameRow(game)
+		for _, category := range features {
+			row = append(row, category.values(game)...)
+		}
+		dataset.Rows = append(dataset.Rows, row)
+	}
+
+	return dataset, nil
+}
+
+// completedGames reads the range, keeping only settled games and, when season is set,
+// only that season's games
+func (e *Exporter) completedGames(ctx context.Context, from, to string, season int) ([]models.Game, error) {
+	inRange, err := database.GetGamesInRange(ctx, e.Games, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get games: %w", err)
+	}
+
+	games := make([]models.Game, 0, len(inRange))
+	for _, game := range inRange {
+		// Games stored before they carried a season are already bounded by the season's dates
+		if game.Status == models.GameStatusCompleted && (season == 0 || game.Season == 0 || game.Season == season) {
+			games = append(games, game)
+		}
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].Date.Equal(games[j].Date) {
+			return games[i].GameId < games[j].GameId
+		}
+		return games[i].Date.Before(games[j].Date)
+	})
+
+	return games, nil
+}
+
+func gameRow(game models.Game) []interface{} {
+	var season interface{}
+	if game.Season != 0 {
+		season = game.Season
+	} else if year, err := strconv.Atoi(game.GameDay[:min(4, len(game.GameDay))]); err == nil {
+		season = year
+	}
+
+	var gameType interface{}
+	if game.GameType != "" {
+		gameType = game.GameType
+	}
+
+	var startTime interface{}
+	if !game.Date.IsZero() {
+		startTime = game.Date.UTC().Format(time.RFC3339)
+	}
+
+	var innings interface{}
+	if game.Innings > 0 {
+		innings = game.Innings
+	}
+
+	return []interface{}{
+		game.GameId,
+		game.GameDay,
+		startTime,
+		season,
+		gameType,
+		game.HomeTeamId,
+		game.HomeTeam,
+		game.AwayTeamId,
+		game.AwayTeam,
+		game.HomeScore,
+		game.AwayScore,
+		game.HomeScore + game.AwayScore,
+		innings,
+		game.Winner,
+		game.Winner == game.HomeTeamId,
+	}
+}
@@ -0,0 +1,222 @@
+package dataset
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/stats"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestQueryBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    Query
+		from, to string
+		wantErr  bool
+	}{
+		{name: "season", query: Query{Season: 2025}, from: "2025-01-01", to: "2025-12-31"},
+		{name: "range", query: Query{From: "2025-04-01", To: "2025-04-30"}, from: "2025-04-01", to: "2025-04-30"},
+		{name: "range within a season", query: Query{Season: 2025, From: "2025-06-01"}, from: "2025-06-01", to: "2025-12-31"},
+		{name: "range clamped to the season", query: Query{Season: 2025, From: "2024-10-01", To: "2026-03-01"}, from: "2025-01-01", to: "2025-12-31"},
+		{name: "nothing", query: Query{}, wantErr: true},
+		{name: "from only", query: Query{From: "2025-04-01"}, wantErr: true},
+		{name: "bad date", query: Query{From: "April", To: "2025-04-30"}, wantErr: true},
+		{name: "reversed", query: Query{From: "2025-04-30", To: "2025-04-01"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		from, to, err := tt.query.Bounds()
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("%s: Bounds() error = %v, want ErrInvalidQuery", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || from != tt.from || to != tt.to {
+			t.Errorf("%s: Bounds() = %s, %s, %v, want %s, %s", tt.name, from, to, err, tt.from, tt.to)
+		}
+	}
+}
+
+// newTestExporter stores completed BOS (111) home games on 2025-05-31, 06-02 and 06-03, an upcoming
+// one on 06-04, and hitting snapshots taken on 06-01 and 06-03
+func newTestExporter(t *testing.T) *Exporter {
+	t.Helper()
+	ctx := context.Background()
+
+	db := database.NewMemoryDB()
+	for _, game := range []models.Game{
+		{GameId: "g0", Date: time.Date(2025, 5, 31, 23, 0, 0, 0, time.UTC), HomeScore: 3, AwayScore: 2, Winner: "111", Status: models.GameStatusCompleted},
+		{GameId: "g1", Date: time.Date(2025, 6, 2, 23, 0, 0, 0, time.UTC), HomeScore: 1, AwayScore: 4, Winner: "147", Status: models.GameStatusCompleted},
+		{GameId: "g2", Date: time.Date(2025, 6, 3, 23, 0, 0, 0, time.UTC), HomeScore: 6, AwayScore: 5, Winner: "111", Status: models.GameStatusCompleted, Innings: 10},
+		{GameId: "g3", Date: time.Date(2025, 6, 4, 23, 0, 0, 0, time.UTC), Status: models.GameStatusUpcoming},
+	} {
+		game.HomeTeamId, game.AwayTeamId = "111", "147"
+		if err := db.CreateGame(ctx, &game); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blobs, err := storage.NewLocalStore(t.TempDir(), "http://localhost", []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, body := range map[string]string{
+		// NYY has no stats on 06-01, and the Stats API sends rates as strings
+		"team-stats/hitting/2025-06-01.json": `{"111": {"teamId": 111, "homeRuns": 10, "avg": ".250", "ops": "-.--"}}`,
+		"team-stats/hitting/2025-06-03.json": `{"111": {"teamId": 111, "homeRuns": 12, "avg": ".255"}, "147": {"teamId": 147, "homeRuns": 15, "avg": ".262"}}`,
+	} {
+		if err := blobs.Put(ctx, key, strings.NewReader(body), "application/json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewExporter(db, stats.NewTeamStats(blobs))
+}
+
+// cell returns the row's value for the named column
+func cell(t *testing.T, data *Dataset, row []interface{}, name string) interface{} {
+	t.Helper()
+	i := slices.IndexFunc(data.Columns, func(column Column) bool { return column.Name == name })
+	if i < 0 {
+		t.Fatalf("no column %s in %v", name, data.Columns)
+	}
+	return row[i]
+}
+
+func TestExporterBuildJoinsSnapshotsByGameDay(t *testing.T) {
+	data, err := newTestExporter(t).Build(context.Background(), Query{From: "2025-05-31", To: "2025-06-04"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	var gameIds []interface{}
+	for _, row := range data.Rows {
+		gameIds = append(gameIds, row[0])
+	}
+	if !slices.Equal(gameIds, []interface{}{"g0", "g1", "g2"}) {
+		t.Fatalf("rows = %v, want the completed games oldest first", gameIds)
+	}
+
+	tests := []struct {
+		gameId                        string
+		statsDate                     interface{}
+		homeHomeRuns, awayHomeRuns    interface{}
+		homeAvg                       interface{}
+		innings, homeWin, pitchingDay interface{}
+	}{
+		// No snapshot this early, so everything from it is missing
+		{gameId: "g0", innings: nil, homeWin: true},
+		// The day before a snapshot uses the previous one, where NYY has no stats
+		{gameId: "g1", statsDate: "2025-06-01", homeHomeRuns: 10.0, awayHomeRuns: nil, homeAvg: 0.25, homeWin: false},
+		// A snapshot taken on game day is used, since it is taken before the first pitch
+		{gameId: "g2", statsDate: "2025-06-03", homeHomeRuns: 12.0, awayHomeRuns: 15.0, homeAvg: 0.255, innings: 10, homeWin: true},
+	}
+	for i, tt := range tests {
+		row := data.Rows[i]
+		for name, want := range map[string]interface{}{
+			"hitting_stats_date":     tt.statsDate,
+			"home_hitting_home_runs": tt.homeHomeRuns,
+			"away_hitting_home_runs": tt.awayHomeRuns,
+			"home_hitting_avg":       tt.homeAvg,
+			"innings":                tt.innings,
+			"home_win":               tt.homeWin,
+			"pitching_stats_date":    tt.pitchingDay,
+		} {
+			if got := cell(t, data, row, name); got != want {
+				t.Errorf("%s %s = %v, want %v", tt.gameId, name, got, want)
+			}
+		}
+	}
+
+	// Placeholders aren't numbers and identifiers aren't stats, so neither becomes a column
+	for _, column := range data.Columns {
+		if strings.HasSuffix(column.Name, "_ops") || strings.HasSuffix(column.Name, "_team_id") && strings.Contains(column.Name, "hitting") {
+			t.Errorf("unexpected column %s", column.Name)
+		}
+	}
+}
+
+// missingValues has a value missing from each kind of column
+var missingValues = &Dataset{
+	Columns: []Column{{"game_id", KindString}, {"innings", KindInt}, {"home_hitting_avg", KindFloat}, {"home_win", KindBool}},
+	Rows: [][]interface{}{
+		{"g1", 9, 0.25, true},
+		{"g2", nil, nil, nil},
+	},
+}
+
+func TestDatasetWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := missingValues.Write(&out, FormatCSV); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := "game_id,innings,home_hitting_avg,home_win\ng1,9,0.25,true\ng2,,,\n"
+	if out.String() != want {
+		t.Errorf("CSV = %q, want %q", out.String(), want)
+	}
+}
+
+func TestDatasetWriteJSONL(t *testing.T) {
+	var out bytes.Buffer
+	if err := missingValues.Write(&out, FormatJSONL); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `{"game_id":"g1","innings":9,"home_hitting_avg":0.25,"home_win":true}` + "\n" +
+		`{"game_id":"g2","innings":null,"home_hitting_avg":null,"home_win":null}` + "\n"
+	if out.String() != want {
+		t.Errorf("JSONL = %q, want %q", out.String(), want)
+	}
+}
+
+func TestDatasetWriteParquet(t *testing.T) {
+	var out bytes.Buffer
+	if err := missingValues.Write(&out, FormatParquet); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	file, err := parquet.OpenFile(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+
+	// Columns keep the dataset's order rather than being sorted by name
+	var names []string
+	for _, field := range file.Schema().Fields() {
+		names = append(names, field.Name())
+		if !field.Optional() {
+			t.Errorf("column %s is required, want optional", field.Name())
+		}
+	}
+	if !slices.Equal(names, []string{"game_id", "innings", "home_hitting_avg", "home_win"}) {
+		t.Errorf("columns = %v, want game_id first in dataset order", names)
+	}
+
+	rows := make([]parquet.Row, 2)
+	reader := file.RowGroups()[0].Rows()
+	defer reader.Close()
+	if n, _ := reader.ReadRows(rows); n != 2 {
+		t.Fatalf("read %d rows, want 2", n)
+	}
+
+	first := rows[0]
+	if first[0].String() != "g1" || first[1].Int64() != 9 || first[2].Double() != 0.25 || !first[3].Boolean() {
+		t.Errorf("first row = %v, want g1, 9, 0.25, true", first)
+	}
+	for i, value := range rows[1][1:] {
+		if !value.IsNull() {
+			t.Errorf("second row column %s = %v, want null", names[i+1], value)
+		}
+	}
+}
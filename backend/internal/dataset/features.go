@@ -0,0 +1,171 @@
+package dataset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/stats"
+)
+
+// nonFeatureStats are identifiers the Lambda adds to each team's stats rather than stats themselves
+var nonFeatureStats = map[string]bool{"teamId": true, "teamName": true, "season": true, "rank": true}
+
+// categoryFeatures holds one stats category's snapshots for the game days being exported
+type categoryFeatures struct {
+	category string
+	// snapshotDates maps a game day to the snapshot used for it
+	snapshotDates map[string]string
+	// snapshots maps a snapshot date to each team's numeric stats
+	snapshots map[string]map[string]map[string]float64
+	// stats is every stat name seen, sorted, so all rows share the same columns
+	stats []string
+}
+
+// loadFeatures reads the snapshots each game day needs, once per snapshot
+func (e *Exporter) loadFeatures(ctx context.Context, games []models.Game) ([]*categoryFeatures, error) {
+	if e.Stats == nil {
+		return nil, nil
+	}
+
+	features := make([]*categoryFeatures, 0, len(stats.Categories))
+	for _, category := range stats.Categories {
+		dates, err := e.Stats.Dates(ctx, category)
+		if err != nil {
+			return nil, err
+		}
+
+		cf := &categoryFeatures{
+			category:      category,
+			snapshotDates: make(map[string]string),
+			snapshots:     make(map[string]map[string]map[string]float64),
+		}
+		seen := make(map[string]bool)
+
+		for _, game := range games {
+			if _, ok := cf.snapshotDates[game.GameDay]; ok {
+				continue
+			}
+
+			date, ok := stats.SnapshotDate(dates, game.GameDay)
+			if !ok {
+				continue
+			}
+			cf.snapshotDates[game.GameDay] = date
+
+			if _, ok := cf.snapshots[date]; ok {
+				continue
+			}
+			snapshot, err := e.Stats.Snapshot(ctx, category, date)
+			if err != nil {
+				return nil, err
+			}
+			teams, err := numericStats(snapshot)
+			if err != nil {
+				return nil, err
+			}
+			cf.snapshots[date] = teams
+
+			for _, teamStats := range teams {
+				for stat := range teamStats {
+					seen[stat] = true
+				}
+			}
+		}
+
+		for stat := range seen {
+			cf.stats = append(cf.stats, stat)
+		}
+		sort.Strings(cf.stats)
+
+		features = append(features, cf)
+	}
+
+	return features, nil
+}
+
+// numericStats keeps the stats that parse as numbers. The Stats API sends rates such as
+// avg as strings (".250"), so those are parsed too; placeholders like "-.--" are dropped.
+func numericStats(snapshot *models.TeamStatsSnapshot) (map[string]map[string]float64, error) {
+	teams := make(map[string]map[string]float64, len(snapshot.Teams))
+
+	for teamId, raw := range snapshot.Teams {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("failed to decode %s stats for team %s on %s: %w", snapshot.Category, teamId, snapshot.Date, err)
+		}
+
+		values := make(map[string]float64, len(fields))
+		for name, field := range fields {
+			if nonFeatureStats[name] {
+				continue
+			}
+			switch v := field.(type) {
+			case float64:
+				values[name] = v
+			case string:
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					values[name] = f
+				}
+			}
+		}
+		teams[teamId] = values
+	}
+
+	return teams, nil
+}
+
+// columns are the snapshot date followed by each stat for the home team, then the away team
+func (cf *categoryFeatures) columns() []Column {
+	columns := []Column{{cf.category + "_stats_date", KindString}}
+	for _, side := range []string{"home", "away"} {
+		for _, stat := range cf.stats {
+			columns = append(columns, Column{side + "_" + cf.category + "_" + snakeCase(stat), KindFloat})
+		}
+	}
+	return columns
+}
+
+func (cf *categoryFeatures) values(game models.Game) []interface{} {
+	values := make([]interface{}, 0, 1+2*len(cf.stats))
+
+	date, ok := cf.snapshotDates[game.GameDay]
+	if !ok {
+		// No snapshot this early, so every stat is missing too
+		return make([]interface{}, 1+2*len(cf.stats))
+	}
+	values = append(values, date)
+
+	for _, teamId := range []string{game.HomeTeamId, game.AwayTeamId} {
+		teamStats := cf.snapshots[date][teamId]
+		for _, stat := range cf.stats {
+			if v, ok := teamStats[stat]; ok {
+				values = append(values, v)
+			} else {
+				values = append(values, nil)
+			}
+		}
+	}
+
+	return values
+}
+
+// snakeCase turns the Stats API's camelCase names into column names, e.g. homeRuns to home_runs
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,236 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is a file format datasets can be written in
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+	FormatJSONL   Format = "jsonl"
+)
+
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatCSV, FormatParquet, FormatJSONL:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: unknown format %q, expected csv, parquet or jsonl", ErrInvalidQuery, name)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Write encodes the dataset to w
+func (d *Dataset) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatCSV:
+		return d.writeCSV(w)
+	case FormatJSONL:
+		return d.writeJSONL(w)
+	case FormatParquet:
+		return d.writeParquet(w)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// writeCSV leaves missing values as empty cells
+func (d *Dataset) writeCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+
+	header := make([]string, len(d.Columns))
+	for i, column := range d.Columns {
+		header[i] = column.Name
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(d.Columns))
+	for _, row := range d.Rows {
+		for i, value := range row {
+			record[i] = formatValue(value)
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeJSONL writes one object per row with keys in column order and null for missing values
+func (d *Dataset) writeJSONL(w io.Writer) error {
+	out := bufio.NewWriter(w)
+
+	keys := make([][]byte, len(d.Columns))
+	for i, column := range d.Columns {
+		key, err := json.Marshal(column.Name)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	for _, row := range d.Rows {
+		out.WriteByte('{')
+		for i, value := range row {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			out.Write(keys[i])
+			out.WriteByte(':')
+			out.Write(encoded)
+		}
+		if _, err := out.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+// writeParquet writes every column as optional so missing values stay null
+func (d *Dataset) writeParquet(w io.Writer) error {
+	group := columnGroup{Group: make(parquet.Group, len(d.Columns))}
+	for _, column := range d.Columns {
+		node := parquet.Optional(parquetNode(column.Kind))
+		group.Group[column.Name] = node
+		group.fields = append(group.fields, columnField{Node: node, name: column.Name})
+	}
+	schema := parquet.NewSchema("game", group)
+
+	writer := parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy))
+
+	rows := make([]parquet.Row, 0, len(d.Rows))
+	for _, row := range d.Rows {
+		values := make(parquet.Row, len(row))
+		for i, value := range row {
+			values[i] = parquetValue(value, i)
+		}
+		rows = append(rows, values)
+	}
+
+	if _, err := writer.WriteRows(rows); err != nil {
+		return fmt.Errorf("failed to write parquet rows: %w", err)
+	}
+	return writer.Close()
+}
+
+// columnGroup is a parquet group that keeps the dataset's column order, so game_id comes first;
+// parquet.Group would sort the columns by name
+type columnGroup struct {
+	parquet.Group
+	fields []parquet.Field
+}
+
+func (g columnGroup) Fields() []parquet.Field { return g.fields }
+
+func (g columnGroup) String() string {
+	var b strings.Builder
+	parquet.PrintSchema(&b, "", g)
+	return b.String()
+}
+
+func (g columnGroup) GoType() reflect.Type {
+	structFields := make([]reflect.StructField, len(g.fields))
+	for i, field := range g.fields {
+		structFields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Column%d", i),
+			Type: field.GoType(),
+			Tag:  reflect.StructTag(fmt.Sprintf(`parquet:%q`, field.Name())),
+		}
+	}
+	return reflect.StructOf(structFields)
+}
+
+type columnField struct {
+	parquet.Node
+	name string
+}
+
+func (f columnField) Name() string { return f.name }
+
+// Value reads the column from a map keyed by column name, as parquet.Group's fields do
+func (f columnField) Value(base reflect.Value) reflect.Value {
+	if base.Kind() == reflect.Interface {
+		if base.IsNil() {
+			return reflect.ValueOf(nil)
+		}
+		base = base.Elem()
+	}
+	return base.MapIndex(reflect.ValueOf(f.name))
+}
+
+func parquetNode(kind Kind) parquet.Node {
+	switch kind {
+	case KindInt:
+		return parquet.Int(64)
+	case KindFloat:
+		return parquet.Leaf(parquet.DoubleType)
+	case KindBool:
+		return parquet.Leaf(parquet.BooleanType)
+	default:
+		return parquet.String()
+	}
+}
+
+// parquetValue sets the definition level to 1 for present values of an optional column
+func parquetValue(value interface{}, column int) parquet.Value {
+	switch v := value.(type) {
+	case string:
+		return parquet.ByteArrayValue([]byte(v)).Level(0, 1, column)
+	case int:
+		return parquet.Int64Value(int64(v)).Level(0, 1, column)
+	case float64:
+		return parquet.DoubleValue(v).Level(0, 1, column)
+	case bool:
+		return parquet.BooleanValue(v).Level(0, 1, column)
+	default:
+		return parquet.NullValue().Level(0, 0, column)
+	}
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// ContestSummary is a contest as one user sees it
+type ContestSummary struct {
+	models.Contest
+	Open         bool `json:"open"`
+	EntrantCount int  `json:"entrant_count"`
+	// Entered is whether the caller has entered
+	Entered bool `json:"entered"`
+	// Games is the contest's slate, only included when a single contest is requested
+	Games []models.Game `json:"games,omitempty"`
+}
+
+func summarizeContest(contest models.Contest, userId string, entries []models.ContestEntry) ContestSummary {
+	summary := ContestSummary{
+		Contest:      contest,
+		Open:         contest.IsOpen(time.Now()),
+		EntrantCount: len(entries),
+	}
+	for _, entry := range entries {
+		if entry.UserId == userId {
+			summary.Entered = true
+		}
+	}
+	return summary
+}
+
+// CreateContestHandler lets a league owner create a contest over a slate of games.
+// Entry opens now and closes when the first game starts, unless the request opens it later or closes it earlier.
+// POST /contests/create
+func (h *Handler) CreateContestHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		LeagueId    string              `json:"league_id"`
+		Name        string              `json:"name"`
+		Rules       models.ContestRules `json:"rules"`
+		EntryOpens  *time.Time          `json:"entry_opens"`
+		EntryCloses *time.Time          `json:"entry_closes"`
+	}
+	if err := h.decodeJsonBody(request, &req); err != nil {
+		h.respondError(writer, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.LeagueId == "" {
+		h.respondError(writer, http.StatusBadRequest, "League ID is required")
+		return
+	}
+	league, userId, ok := h.leagueForUser(writer, request, req.LeagueId, true)
+	if !ok {
+		return
+	}
+	if league.OwnerId != userId {
+		h.respondError(writer, http.StatusForbidden, "Only the league owner can create contests")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		h.respondError(writer, http.StatusBadRequest, "Contest name is required")
+		return
+	}
+
+	if err := h.validateContestRules(request.Context(), req.Rules); err != nil {
+		h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid contest rules: %s", err.Error()))
+		return
+	}
+
+	games, err := database.ContestGames(request.Context(), h.db, req.Rules)
+	if err != nil {
+		if errors.Is(err, database.ErrGameNotFound) || errors.Is(err, database.ErrInvalidDateRange) {
+			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid contest rules: %s", err.Error()))
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
+	if len(games) == 0 {
+		h.respondError(writer, http.StatusBadRequest, "No games match the contest rules")
+		return
+	}
+
+	contest := &models.Contest{
+		ContestId:   generateUUID(),
+		LeagueId:    league.LeagueId,
+		Name:        req.Name,
+		Rules:       req.Rules,
+		GameIds:     make([]string, 0, len(games)),
+		EntryOpens:  time.Now().UTC(),
+		EntryCloses: games[0].Date,
+		CreatedBy:   userId,
+	}
+	for _, game := range games {
+		contest.GameIds = append(contest.GameIds, game.GameId)
+	}
+	if req.EntryOpens != nil {
+		contest.EntryOpens = req.EntryOpens.UTC()
+	}
+	if req.EntryCloses != nil {
+		// A later close would let someone enter once early results are known and still be scored on them
+		if req.EntryCloses.After(games[0].Date) {
+			h.respondError(writer, http.StatusBadRequest, "Entry must close by the time the first game starts")
+			return
+		}
+		contest.EntryCloses = req.EntryCloses.UTC()
+	}
+	if !contest.EntryCloses.After(contest.EntryOpens) {
+		h.respondError(writer, http.StatusBadRequest, "Entry must close after it opens; the first game may already have started")
+		return
+	}
+
+	if err := h.db.CreateContest(request.Context(), contest); err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to create contest")
+		return
+	}
+
+	summary := summarizeContest(*contest, userId, nil)
+	summary.Games = games
+	h.respondJson(writer, http.StatusCreated, summary)
+}
+
+// validateContestRules checks that rules select games either explicitly or by a date range of at
+// most a season, and that any teams and game types they name exist
+func (h *Handler) validateContestRules(ctx context.Context, rules models.ContestRules) error {
+	if len(rules.GameIds) > 0 {
+		if rules.From != "" || rules.To != "" || len(rules.TeamIds) > 0 || len(rules.GameTypes) > 0 {
+			return errors.New("use either game_ids or from/to with team and game type filters, not both")
+		}
+		return nil
+	}
+
+	if rules.From == "" || rules.To == "" {
+		return errors.New("game_ids or a from/to date range is required")
+	}
+	from, err := time.Parse(models.GameDayLayout, rules.From)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", rules.From)
+	}
+	to, err := time.Parse(models.GameDayLayout, rules.To)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", rules.To)
+	}
+	if to.Before(from) {
+		return errors.New("from must not be after to")
+	}
+	if to.Sub(from) >= database.MaxGamesInRangeDays*24*time.Hour {
+		return fmt.Errorf("the date range is limited to %d days", database.MaxGamesInRangeDays)
+	}
+
+	for _, teamId := range rules.TeamIds {
+		if _, err := h.db.GetTeam(ctx, teamId); err != nil {
+			if errors.Is(err, database.ErrTeamNotFound) {
+				return fmt.Errorf("team %q not found", teamId)
+			}
+			return err
+		}
+	}
+	for _, gameType := range rules.GameTypes {
+		if !slices.Contains(models.GameTypes, gameType) {
+			return fmt.Errorf("unknown game type %q", gameType)
+		}
+	}
+
+	return nil
+}
+
+// GetLeagueContestsHandler lists a league's contests
+// GET /contests?league_id=abc
+func (h *Handler) GetLeagueContestsHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	leagueId := request.URL.Query().Get("league_id")
+	if leagueId == "" {
+		h.respondError(writer, http.StatusBadRequest, "Missing league_id parameter")
+		return
+	}
+
+	league, userId, ok := h.leagueForUser(writer, request, leagueId, false)
+	if !ok {
+		return
+	}
+
+	contests, err := h.db.GetLeagueContests(request.Context(), league.LeagueId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get contests")
+		return
+	}
+
+	summaries := make([]ContestSummary, 0, len(contests))
+	for _, contest := range contests {
+		entries, err := h.db.GetContestEntries(request.Context(), contest.ContestId)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, "Failed to get contest entries")
+			return
+		}
+		summaries = append(summaries, summarizeContest(contest, userId, entries))
+	}
+
+	h.respondJson(writer, http.StatusOK, summaries)
+}
+
+// GetContestHandler returns a contest with its slate of games
+// GET /contests/{contestId}
+func (h *Handler) GetContestHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	contest, _, userId, ok := h.contestFromPath(writer, request, false)
+	if !ok {
+		return
+	}
+
+	entries, err := h.db.GetContestEntries(request.Context(), contest.ContestId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get contest entries")
+		return
+	}
+
+	games, err := database.ContestSlate(request.Context(), h.db, *contest)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
+
+	summary := summarizeContest(*contest, userId, entries)
+	summary.Games = games
+	h.respondJson(writer, http.StatusOK, summary)
+}
+
+// EnterContestHandler enters the caller into a contest while its entry window is open
+// POST /contests/enter/{contestId}
+func (h *Handler) EnterContestHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	contest, _, userId, ok := h.contestFromPath(writer, request, true)
+	if !ok {
+		return
+	}
+
+	if !contest.IsOpen(time.Now()) {
+		h.respondError(writer, http.StatusBadRequest, "The contest is not open for entries")
+		return
+	}
+
+	entry := &models.ContestEntry{ContestId: contest.ContestId, UserId: userId}
+	if err := h.db.EnterContest(request.Context(), entry); err != nil {
+		if errors.Is(err, database.ErrAlreadyEnteredContest) {
+			h.respondError(writer, http.StatusConflict, "Already entered this contest")
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, "Failed to enter contest")
+		return
+	}
+
+	h.respondJson(writer, http.StatusCreated, entry)
+}
+
+// GetContestLeaderboard ranks a contest's entrants on its games, under the league's scoring profile
+// GET /contests/leaderboard/{contestId}
+func (h *Handler) GetContestLeaderboard(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	contest, league, _, ok := h.contestFromPath(writer, request, false)
+	if !ok {
+		return
+	}
+
+	entries, err := h.db.GetContestEntries(request.Context(), contest.ContestId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get contest entries")
+		return
+	}
+
+	games, err := database.ContestSlate(request.Context(), h.db, *contest)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
+
+	leaderboard, err := h.db.CalculateLeaderboard(request.Context(), database.ContestScope(league, entries, games))
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get contest leaderboard")
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, leaderboard)
+}
+
+// contestFromPath loads the contest whose id ends the path and its league, which the caller must
+// be able to see, see leagueForUser. On failure it writes the error response and returns false.
+func (h *Handler) contestFromPath(w http.ResponseWriter, r *http.Request, membersOnly bool) (*models.Contest, *models.League, string, bool) {
+	parts := strings.Split(r.URL.Path, "/")
+	contestId := parts[len(parts)-1]
+	if len(parts) < 3 || contestId == "" {
+		h.respondError(w, http.StatusBadRequest, "Contest ID is required")
+		return nil, nil, "", false
+	}
+
+	contest, err := h.db.GetContest(r.Context(), contestId)
+	if err != nil {
+		if errors.Is(err, database.ErrContestNotFound) {
+			h.respondError(w, http.StatusNotFound, "Contest not found")
+			return nil, nil, "", false
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to get contest")
+		return nil, nil, "", false
+	}
+
+	league, userId, ok := h.leagueForUser(w, r, contest.LeagueId, membersOnly)
+	if !ok {
+		return nil, nil, "", false
+	}
+
+	return contest, league, userId, true
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestCreateContestEntryWindow(t *testing.T) {
+	ctx := context.Background()
+	h, db := newTestHandler()
+
+	first := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	for i, gameId := range []string{"g1", "g2"} {
+		game := models.Game{GameId: gameId, Date: first.Add(time.Duration(i) * 24 * time.Hour), HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming}
+		if err := db.CreateGame(ctx, &game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.CreateLeague(ctx, &models.League{LeagueId: "l1", Name: "Office", OwnerId: "u1", InviteCode: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		entryCloses string
+		status      int
+		wantCloses  time.Time
+	}{
+		{name: "defaults to the first game", status: http.StatusCreated, wantCloses: first},
+		{name: "closes early", entryCloses: first.Add(-time.Hour).Format(time.RFC3339), status: http.StatusCreated, wantCloses: first.Add(-time.Hour)},
+		{name: "closes as the first game starts", entryCloses: first.Format(time.RFC3339), status: http.StatusCreated, wantCloses: first},
+		{name: "closes after the first game starts", entryCloses: first.Add(time.Minute).Format(time.RFC3339), status: http.StatusBadRequest},
+		{name: "closes after the last game", entryCloses: first.Add(72 * time.Hour).Format(time.RFC3339), status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		closes := ""
+		if tt.entryCloses != "" {
+			closes = fmt.Sprintf(`, "entry_closes": %q`, tt.entryCloses)
+		}
+		body := fmt.Sprintf(`{"league_id": "l1", "name": "Weekend", "rules": {"game_ids": ["g1", "g2"]}%s}`, closes)
+
+		recorder := serveAs(h.CreateContestHandler, http.MethodPost, "/contests/create", "u1", strings.NewReader(body))
+		if tt.status != http.StatusCreated {
+			if recorder.Code != tt.status {
+				t.Errorf("%s: status = %d, want %d: %s", tt.name, recorder.Code, tt.status, recorder.Body.String())
+			}
+			continue
+		}
+
+		summary := decodeResponse[ContestSummary](t, recorder, tt.status)
+		if !summary.EntryCloses.Equal(tt.wantCloses) {
+			t.Errorf("%s: entry_closes = %v, want %v", tt.name, summary.EntryCloses, tt.wantCloses)
+		}
+	}
+}
+
+func TestContestSlateIsFrozen(t *testing.T) {
+	ctx := context.Background()
+	h, db := newTestHandler()
+
+	first := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	addGame := func(gameId string, start time.Time) {
+		t.Helper()
+		game := models.Game{GameId: gameId, Date: start, HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming}
+		if err := db.CreateGame(ctx, &game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addGame("g1", first)
+	addGame("g2", first.Add(24*time.Hour))
+	if err := db.CreateLeague(ctx, &models.League{LeagueId: "l1", Name: "Office", OwnerId: "u1", InviteCode: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := fmt.Sprintf(`{"from": %q, "to": %q}`, first.Format(models.GameDayLayout), first.AddDate(0, 0, 2).Format(models.GameDayLayout))
+	body := fmt.Sprintf(`{"league_id": "l1", "name": "Weekend", "rules": %s}`, rules)
+	created := decodeResponse[ContestSummary](t, serveAs(h.CreateContestHandler, http.MethodPost, "/contests/create", "u1", strings.NewReader(body)), http.StatusCreated)
+	if strings.Join(created.GameIds, ",") != "g1,g2" {
+		t.Fatalf("game_ids = %v, want [g1 g2]", created.GameIds)
+	}
+
+	// A makeup game scheduled into the range afterwards isn't part of the contest
+	addGame("g3", first.Add(36*time.Hour))
+
+	summary := decodeResponse[ContestSummary](t, serveAs(h.GetContestHandler, http.MethodGet, "/contests/"+created.ContestId, "u1", nil), http.StatusOK)
+	var got []string
+	for _, game := range summary.Games {
+		got = append(got, game.GameId)
+	}
+	if strings.Join(got, ",") != "g1,g2" {
+		t.Errorf("games = %v, want the slate frozen at creation [g1 g2]", got)
+	}
+}
+
+func TestCreateContestRejectsLongRange(t *testing.T) {
+	ctx := context.Background()
+	h, db := newTestHandler()
+	if err := db.CreateLeague(ctx, &models.League{LeagueId: "l1", Name: "Office", OwnerId: "u1", InviteCode: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rules := range []string{
+		`{"from": "0001-01-01", "to": "9999-12-31"}`,
+		`{"from": "2025-01-01", "to": "2026-01-01"}`,
+		`{"from": "2025-06-30", "to": "2025-04-01"}`,
+	} {
+		body := fmt.Sprintf(`{"league_id": "l1", "name": "Forever", "rules": %s}`, rules)
+		recorder := serveAs(h.CreateContestHandler, http.MethodPost, "/contests/create", "u1", strings.NewReader(body))
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("rules %s: status = %d, want %d: %s", rules, recorder.Code, http.StatusBadRequest, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/dataset"
+)
+
+// exportWriteTimeout replaces the server's write timeout for exports, which can cover a whole season
+const exportWriteTimeout = 5 * time.Minute
+
+// GET /export/games?season=2025&format=csv
+// GET /export/games?from=2025-04-01&to=2025-04-30&format=parquet
+// Exports completed games with each team's stats as of game day. format is csv (default), parquet or jsonl.
+func (h *Handler) ExportGames(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := request.URL.Query()
+
+	format := dataset.FormatCSV
+	if name := query.Get("format"); name != "" {
+		var err error
+		if format, err = dataset.ParseFormat(name); err != nil {
+			h.respondError(writer, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	datasetQuery := dataset.Query{From: query.Get("from"), To: query.Get("to")}
+	if season := query.Get("season"); season != "" {
+		year, err := strconv.Atoi(season)
+		if err != nil || year < 1876 {
+			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid season %q", season))
+			return
+		}
+		datasetQuery.Season = year
+	}
+
+	from, to, err := datasetQuery.Bounds()
+	if err != nil {
+		h.respondError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	http.NewResponseController(writer).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+
+	data, err := dataset.NewExporter(h.db, h.teamStats).Build(request.Context(), datasetQuery)
+	if err != nil {
+		if errors.Is(err, dataset.ErrInvalidQuery) || errors.Is(err, database.ErrInvalidDateRange) {
+			h.respondError(writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to build dataset: ", err))
+		return
+	}
+
+	writer.Header().Set("Content-Type", format.ContentType())
+	writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="games_%s_%s.%s"`, from, to, format))
+	writer.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure here can only cut the file short
+	if err := data.Write(writer, format); err != nil {
+		log.Printf("Failed to write %s export: %v", format, err)
+	}
+}
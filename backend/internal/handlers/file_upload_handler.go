@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"path"
+	"strings"
 	"time"
 
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
 	"github.com/bendemouth/mlb-prediction-pool/internal/middleware"
 	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/validation"
 )
 
 // generateUUID generates a simple UUID-like string
@@ -18,6 +23,62 @@ func generateUUID() string {
 	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
 
+func nextModelVersion(versions []*models.ModelMetadata) int {
+	next := 1
+	for _, version := range versions {
+		if version.Version >= next {
+			next = version.Version + 1
+		}
+	}
+	return next
+}
+
+// maxVersionAttempts bounds how many versions createModelVersion tries before giving up
+const maxVersionAttempts = 5
+
+// createModelVersion stores a new model version. Another upload to the family can take the
+// version between reading the family and storing the model; the next version is tried then.
+func (h *Handler) createModelVersion(ctx context.Context, model *models.ModelMetadata) error {
+	for attempt := 1; ; attempt++ {
+		err := h.db.CreateModel(ctx, model)
+		if !errors.Is(err, database.ErrModelVersionTaken) || attempt == maxVersionAttempts {
+			return err
+		}
+		model.Version++
+	}
+}
+
+// newPendingModel builds the record for the next version of a model family
+func newPendingModel(userId, modelName, fileName, changelog string, versions []*models.ModelMetadata) *models.ModelMetadata {
+	return &models.ModelMetadata{
+		ModelId:   generateUUID(),
+		ModelName: modelName,
+		UserId:    userId,
+		FileName:  fileName,
+		// Generate S3 key with user ID and timestamp to ensure uniqueness
+		S3Key:     fmt.Sprintf("models/%s/%d/%s", userId, time.Now().Unix(), fileName),
+		Status:    models.ModelStatusPending,
+		Version:   nextModelVersion(versions),
+		Changelog: changelog,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// defaultContentType is recorded when the uploader doesn't say what a model file is
+const defaultContentType = "application/octet-stream"
+
+// parseFeatureList splits a comma-separated feature declaration
+func parseFeatureList(value string) []string {
+	var features []string
+	for _, feature := range strings.Split(value, ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
 func (h *Handler) UploadModelHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from context
 	userId, ok := r.Context().Value(middleware.UserSubKey).(string)
@@ -26,8 +87,8 @@ func (h *Handler) UploadModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse the multipart form with a max upload size of 500MB
-	if err := r.ParseMultipartForm(500 * 1024 * 1024); err != nil {
+	// Parse the multipart form, keeping up to the max model size in memory
+	if err := r.ParseMultipartForm(validation.MaxModelSize); err != nil {
 		http.Error(w, "Failed to parse multipart form: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -39,6 +100,15 @@ func (h *Handler) UploadModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	changelog := r.FormValue("changelog")
+
+	// Uploading under an existing name adds the next version to that model family
+	versions, err := h.db.GetModelVersions(r.Context(), userId, modelName)
+	if err != nil {
+		http.Error(w, "Failed to look up model versions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// Get the file from form data
 	file, header, err := r.FormFile("file")
 	if err != nil {
@@ -47,40 +117,75 @@ func (h *Handler) UploadModelHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	// Validate file type
-	if path.Ext(header.Filename) != ".pkl" {
-		http.Error(w, "Invalid file type. Only .pkl files are allowed.", http.StatusBadRequest)
+	if !h.checkUploadQuota(w, r, userId, header.Size, versions) {
 		return
 	}
 
-	// Generate model ID
-	modelId := generateUUID()
+	// Inspect the file before anything is stored. Rejected files are never stored,
+	// only their metadata is kept so the owner can see why.
+	report, err := validation.Inspect(file, header.Filename)
+	if err != nil {
+		http.Error(w, "Failed to read model file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Generate S3 key with user ID and timestamp to ensure uniqueness
-	s3Key := fmt.Sprintf("models/%s/%d/%s", userId, time.Now().Unix(), header.Filename)
+	// Input columns the model expects, e.g. "home_win_pct,away_win_pct"
+	features, err := validation.CheckFeatures(report.Framework, parseFeatureList(r.FormValue("features")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = defaultContentType
+	}
 
-	// Upload file to S3
-	success, key, err := h.S3Handler.UploadFileToS3(file, s3Key, r.Context())
-	if !success {
-		http.Error(w, "Failed to upload file to S3: "+err.Error(), http.StatusInternalServerError)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, "Failed to read model file: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Create model metadata record in DynamoDB
-	model := &models.ModelMetadata{
-		ModelId:   modelId,
-		ModelName: modelName,
-		UserId:    userId,
-		FileName:  header.Filename,
-		S3Key:     key,
-		Status:    "active",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	// Create model metadata record in DynamoDB. It stays pending until the upload is done.
+	model := newPendingModel(userId, modelName, header.Filename, changelog, versions)
+	model.Sha256 = report.Sha256
+	model.SizeBytes = report.Size
+	model.ContentType = contentType
+	model.Framework = report.Framework
+	model.Signature = report.Signature
+	model.Features = features
+	if !report.Passed() {
+		model.S3Key = ""
+	}
+
+	if err := h.createModelVersion(r.Context(), model); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrModelVersionTaken) {
+			status = http.StatusConflict
+		}
+		http.Error(w, "Failed to save model metadata: "+err.Error(), status)
+		return
+	}
+
+	if report.Passed() {
+		// Upload file to storage
+		if err := h.blobs.Put(r.Context(), model.S3Key, file, contentType); err != nil {
+			h.db.UpdateModelStatus(r.Context(), model.ModelId, userId, models.ModelStatusRejected, "upload to storage failed")
+			http.Error(w, "Failed to upload file to storage: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := h.uploads.Settle(r.Context(), model, report, versions); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	if err := h.db.CreateModel(r.Context(), model); err != nil {
-		// If DB write fails, we should ideally delete the S3 file, but for now just return error
-		http.Error(w, "Failed to save model metadata: "+err.Error(), http.StatusInternalServerError)
+	if !report.Passed() {
+		h.respondJson(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": "Model rejected: " + report.Reason,
+			"model": model,
+		})
 		return
 	}
 
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestCreateModelVersionSkipsTakenVersions(t *testing.T) {
+	ctx := context.Background()
+	h, db := newTestHandler()
+
+	// Two uploads that read the family while it had one version both want version 2
+	first := newPendingModel("user1", "elo", "elo.onnx", "", []*models.ModelMetadata{{Version: 1}})
+	second := newPendingModel("user1", "elo", "elo.onnx", "", []*models.ModelMetadata{{Version: 1}})
+	for _, model := range []*models.ModelMetadata{first, second} {
+		if err := h.createModelVersion(ctx, model); err != nil {
+			t.Fatalf("createModelVersion() error = %v", err)
+		}
+	}
+	if first.Version != 2 || second.Version != 3 {
+		t.Errorf("versions = %d and %d, want 2 and 3", first.Version, second.Version)
+	}
+
+	// It gives up rather than trying versions forever
+	for version := 4; version < 4+maxVersionAttempts; version++ {
+		taken := newPendingModel("user1", "elo", "elo.onnx", "", nil)
+		taken.Version = version
+		if err := db.CreateModel(ctx, taken); err != nil {
+			t.Fatal(err)
+		}
+	}
+	late := newPendingModel("user1", "elo", "elo.onnx", "", []*models.ModelMetadata{{Version: 3}})
+	if err := h.createModelVersion(ctx, late); !errors.Is(err, database.ErrModelVersionTaken) {
+		t.Errorf("createModelVersion() with every version taken error = %v, want ErrModelVersionTaken", err)
+	}
+}
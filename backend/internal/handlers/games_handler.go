@@ -1,68 +1,377 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"sort"
+	"strings"
+	"time"
 
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
 	"github.com/bendemouth/mlb-prediction-pool/internal/models"
 )
 
 // GamePredictionSummary combines a game with aggregated prediction stats
 type GamePredictionSummary struct {
-	models.Game
+	GameWithTeams
 	PredictionCount        int     `json:"prediction_count"`
 	AvgHomeScorePredicted  float64 `json:"avg_home_score_predicted"`
 	AvgAwayScorePredicted  float64 `json:"avg_away_score_predicted"`
 	AvgTotalScorePredicted float64 `json:"avg_total_score_predicted"`
 	AvgConfidence          float64 `json:"avg_confidence"`
+
+	// Share of predictions picking each team, 0-100
+	HomePickPct float64 `json:"home_pick_pct"`
+	AwayPickPct float64 `json:"away_pick_pct"`
+
+	// Each team's share of the confidence placed on it, 0-1: the summed confidence of predictions
+	// picking the home team over the summed confidence of all picks. It is the community's lean,
+	// not a calibrated chance of winning. Without any confidence it is the share of picks.
+	HomeWinProbability float64 `json:"home_win_probability"`
+	AwayWinProbability float64 `json:"away_win_probability"`
+
+	MedianTotalScorePredicted float64 `json:"median_total_score_predicted"`
+	StdDevTotalScorePredicted float64 `json:"stddev_total_score_predicted"`
+
+	HomeScoreHistogram  []ScoreBucket `json:"home_score_histogram"`
+	AwayScoreHistogram  []ScoreBucket `json:"away_score_histogram"`
+	TotalScoreHistogram []ScoreBucket `json:"total_score_histogram"`
+}
+
+// ScoreBucket counts predictions whose score rounds down to Runs
+type ScoreBucket struct {
+	Runs  int `json:"runs"`
+	Count int `json:"count"`
 }
 
 // GET /games/upcoming
+// GET /games/upcoming?expand=teams
 // Returns upcoming games with aggregated community prediction stats
 func (h *Handler) GetUpcomingGamesSummary(writer http.ResponseWriter, request *http.Request) {
+	expand, err := expandTeams(request)
+	if err != nil {
+		h.respondError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	games, err := h.db.GetUpcomingGames(request.Context())
 	if err != nil {
 		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get upcoming games: ", err))
 		return
 	}
 
-	summaries := make([]GamePredictionSummary, 0, len(games))
+	sortSchedule(games)
 
+	gameIds := make([]string, 0, len(games))
 	for _, game := range games {
-		predictions, err := h.db.GetPredictionsByGame(request.Context(), game.GameId)
+		gameIds = append(gameIds, game.GameId)
+	}
+
+	predictionsByGame, err := h.db.GetPredictionsByGames(request.Context(), gameIds)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get predictions: ", err))
+		return
+	}
+
+	summaries := make([]GamePredictionSummary, 0, len(games))
+	for _, game := range games {
+		summaries = append(summaries, summarizePredictions(game, predictionsByGame[game.GameId]))
+	}
+
+	if expand {
+		expanded, err := h.withTeams(request.Context(), games)
 		if err != nil {
-			h.respondError(writer, http.StatusInternalServerError, fmt.Sprintf("Failed to get predictions for game %s: %s", game.GameId, err))
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get teams: ", err))
 			return
 		}
+		for i := range summaries {
+			summaries[i].GameWithTeams = expanded[i]
+		}
+	}
+
+	h.respondJson(writer, http.StatusOK, summaries)
+}
+
+// sortSchedule orders games by start time. A doubleheader game whose start is TBD has only a
+// placeholder time, so it follows the game before it when that game is in the list.
+func sortSchedule(games []models.Game) {
+	starts := make(map[string]time.Time, len(games))
+	for _, game := range games {
+		starts[game.GameId] = game.Date
+		if game.StartTimeTBD {
+			if opener := findOpener(games, game); opener != nil {
+				starts[game.GameId] = opener.Date
+			}
+		}
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		a, b := starts[games[i].GameId], starts[games[j].GameId]
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return games[i].GameNumber < games[j].GameNumber
+	})
+}
 
-		summary := GamePredictionSummary{
-			Game:            game,
-			PredictionCount: len(predictions),
+// findOpener finds the game before game in its doubleheader: the same two teams on the same day,
+// one game number earlier
+func findOpener(games []models.Game, game models.Game) *models.Game {
+	if game.GameNumber < 2 {
+		return nil
+	}
+
+	for i := range games {
+		other := &games[i]
+		if other.GameId == game.GameId || other.GameDay != game.GameDay || other.GameNumber != game.GameNumber-1 {
+			continue
+		}
+		if (other.HomeTeamId == game.HomeTeamId && other.AwayTeamId == game.AwayTeamId) ||
+			(other.HomeTeamId == game.AwayTeamId && other.AwayTeamId == game.HomeTeamId) {
+			return other
 		}
+	}
+	return nil
+}
+
+// summarizePredictions aggregates the community's predictions for a single game
+func summarizePredictions(game models.Game, predictions []models.Prediction) GamePredictionSummary {
+	summary := GamePredictionSummary{
+		GameWithTeams:       GameWithTeams{Game: game},
+		PredictionCount:     len(predictions),
+		HomeScoreHistogram:  []ScoreBucket{},
+		AwayScoreHistogram:  []ScoreBucket{},
+		TotalScoreHistogram: []ScoreBucket{},
+	}
+
+	if len(predictions) == 0 {
+		return summary
+	}
+
+	var totalHome, totalAway, totalRuns, totalConf float64
+	var homePicks, awayPicks int
+	var homeConf, awayConf float64
+	homeScores := make([]float64, 0, len(predictions))
+	awayScores := make([]float64, 0, len(predictions))
+	totals := make([]float64, 0, len(predictions))
+
+	for _, p := range predictions {
+		totalHome += float64(p.HomeScorePredicted)
+		totalAway += float64(p.AwayScorePredicted)
+		totalRuns += float64(p.TotalScorePredicted)
+		totalConf += float64(p.Confidence)
+
+		switch p.PredictedWinnerId {
+		case game.HomeTeamId:
+			homePicks++
+			homeConf += float64(p.Confidence)
+		case game.AwayTeamId:
+			awayPicks++
+			awayConf += float64(p.Confidence)
+		}
+
+		homeScores = append(homeScores, float64(p.HomeScorePredicted))
+		awayScores = append(awayScores, float64(p.AwayScorePredicted))
+		totals = append(totals, float64(p.TotalScorePredicted))
+	}
+	n := float64(len(predictions))
+	summary.AvgHomeScorePredicted = totalHome / n
+	summary.AvgAwayScorePredicted = totalAway / n
+	summary.AvgTotalScorePredicted = totalRuns / n
+	summary.AvgConfidence = totalConf / n
+
+	summary.HomePickPct = 100 * float64(homePicks) / n
+	summary.AwayPickPct = 100 * float64(awayPicks) / n
+
+	// Without any confidence to weigh by, fall back to the raw pick split
+	if homeConf+awayConf > 0 {
+		summary.HomeWinProbability = homeConf / (homeConf + awayConf)
+		summary.AwayWinProbability = awayConf / (homeConf + awayConf)
+	} else if homePicks+awayPicks > 0 {
+		summary.HomeWinProbability = float64(homePicks) / float64(homePicks+awayPicks)
+		summary.AwayWinProbability = float64(awayPicks) / float64(homePicks+awayPicks)
+	}
+
+	summary.MedianTotalScorePredicted = median(totals)
+	summary.StdDevTotalScorePredicted = stdDev(totals, summary.AvgTotalScorePredicted)
+
+	summary.HomeScoreHistogram = histogram(homeScores)
+	summary.AwayScoreHistogram = histogram(awayScores)
+	summary.TotalScoreHistogram = histogram(totals)
+
+	return summary
+}
+
+// median is the middle value, or the mean of the middle two for an even count; 0 when empty
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// stdDev is the population standard deviation around mean; 0 when empty
+func stdDev(values []float64, mean float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sumSquares float64
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sumSquares / float64(len(values)))
+}
 
-		if len(predictions) > 0 {
-			var totalHome, totalAway, totalRuns, totalConf float64
-			for _, p := range predictions {
-				totalHome += float64(p.HomeScorePredicted)
-				totalAway += float64(p.AwayScorePredicted)
-				totalRuns += float64(p.TotalScorePredicted)
-				totalConf += float64(p.Confidence)
+// histogram buckets scores into whole runs, ascending
+func histogram(values []float64) []ScoreBucket {
+	counts := make(map[int]int)
+	for _, v := range values {
+		counts[int(math.Floor(v))]++
+	}
+
+	buckets := make([]ScoreBucket, 0, len(counts))
+	for runs, count := range counts {
+		buckets = append(buckets, ScoreBucket{Runs: runs, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Runs < buckets[j].Runs })
+
+	return buckets
+}
+
+// GET /games?date=2025-04-01
+// GET /games?from=2025-04-01&to=2025-04-07
+// GET /games?team=147 (optionally bounded with date or from/to)
+// Any of these take expand=teams to include each team's details
+func (h *Handler) GetGames(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := request.URL.Query()
+	date, from, to, team := query.Get("date"), query.Get("from"), query.Get("to"), query.Get("team")
+
+	expand, err := expandTeams(request)
+	if err != nil {
+		h.respondError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if date != "" {
+		if from != "" || to != "" {
+			h.respondError(writer, http.StatusBadRequest, "Use either date or from/to, not both")
+			return
+		}
+		from, to = date, date
+	}
+
+	for _, day := range []string{from, to} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(models.GameDayLayout, day); err != nil {
+			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid date %q, expected YYYY-MM-DD", day))
+			return
+		}
+	}
+
+	if team != "" {
+		if _, err := h.db.GetTeam(request.Context(), team); err != nil {
+			if errors.Is(err, database.ErrTeamNotFound) {
+				h.respondError(writer, http.StatusNotFound, fmt.Sprintf("Team %q not found", team))
+				return
 			}
-			n := float64(len(predictions))
-			summary.AvgHomeScorePredicted = totalHome / n
-			summary.AvgAwayScorePredicted = totalAway / n
-			summary.AvgTotalScorePredicted = totalRuns / n
-			summary.AvgConfidence = totalConf / n
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get team: ", err))
+			return
 		}
+	}
 
-		summaries = append(summaries, summary)
+	var games []models.Game
+
+	switch {
+	case team != "":
+		games, err = h.db.GetGamesByTeam(request.Context(), team, from, to)
+	case date != "":
+		games, err = h.db.GetGamesByDate(request.Context(), date)
+	case from != "" && to != "":
+		games, err = h.db.GetGamesByDateRange(request.Context(), from, to)
+	default:
+		h.respondError(writer, http.StatusBadRequest, "One of date, from and to, or team is required")
+		return
 	}
 
-	// Sort by game date ascending
-	sort.Slice(summaries, func(i, j int) bool {
-		return summaries[i].Date.Before(summaries[j].Date)
-	})
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidDateRange) {
+			h.respondError(writer, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
 
-	h.respondJson(writer, http.StatusOK, summaries)
+	sortSchedule(games)
+
+	if expand {
+		expanded, err := h.withTeams(request.Context(), games)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get teams: ", err))
+			return
+		}
+		h.respondJson(writer, http.StatusOK, expanded)
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, games)
+}
+
+// GET /games/{gameId}
+// GET /games/{gameId}?expand=teams
+func (h *Handler) GetGameById(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	expand, err := expandTeams(request)
+	if err != nil {
+		h.respondError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gameId := strings.TrimPrefix(request.URL.Path, "/games/")
+	if gameId == "" || strings.Contains(gameId, "/") {
+		h.respondError(writer, http.StatusBadRequest, "Game ID is required")
+		return
+	}
+
+	game, err := h.db.GetGame(request.Context(), gameId)
+	if err != nil {
+		if errors.Is(err, database.ErrGameNotFound) {
+			h.respondError(writer, http.StatusNotFound, "Game not found")
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get game: ", err))
+		return
+	}
+
+	if expand {
+		expanded, err := h.withTeams(request.Context(), []models.Game{*game})
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get teams: ", err))
+			return
+		}
+		h.respondJson(writer, http.StatusOK, expanded[0])
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, game)
 }
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"empty", nil, 0},
+		{"single", []float64{7}, 7},
+		{"odd count", []float64{9, 3, 5}, 5},
+		{"even count", []float64{10, 4, 8, 6}, 7},
+	}
+	for _, tt := range tests {
+		if got := median(tt.values); got != tt.want {
+			t.Errorf("median(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		mean   float64
+		want   float64
+	}{
+		{"empty", nil, 0, 0},
+		{"single", []float64{8}, 8, 0},
+		{"population", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 5, 2},
+	}
+	for _, tt := range tests {
+		if got := stdDev(tt.values, tt.mean); got != tt.want {
+			t.Errorf("stdDev(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []ScoreBucket
+	}{
+		{"empty", nil, []ScoreBucket{}},
+		{"single", []float64{4.5}, []ScoreBucket{{Runs: 4, Count: 1}}},
+		// Scores round down, so a bucket holds [runs, runs+1)
+		{"bucket edges", []float64{3.99, 0, 4, 0.5, 3}, []ScoreBucket{{Runs: 0, Count: 2}, {Runs: 3, Count: 2}, {Runs: 4, Count: 1}}},
+	}
+	for _, tt := range tests {
+		if got := histogram(tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("histogram(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizePredictions(t *testing.T) {
+	game := models.Game{GameId: "g1", HomeTeamId: "111", AwayTeamId: "147"}
+	pick := func(winner string, home, away, confidence float32) models.Prediction {
+		return models.Prediction{GameId: "g1", PredictedWinnerId: winner, HomeScorePredicted: home, AwayScorePredicted: away, TotalScorePredicted: home + away, Confidence: confidence}
+	}
+
+	empty := summarizePredictions(game, nil)
+	if empty.PredictionCount != 0 || empty.HomeWinProbability != 0 || empty.MedianTotalScorePredicted != 0 {
+		t.Errorf("summarizePredictions(none) = %+v, want zeros", empty)
+	}
+	if empty.HomeScoreHistogram == nil || empty.AwayScoreHistogram == nil || empty.TotalScoreHistogram == nil {
+		t.Error("summarizePredictions(none) histograms are nil, want empty so they encode as []")
+	}
+
+	single := summarizePredictions(game, []models.Prediction{pick("147", 2, 5, 0.7)})
+	if single.PredictionCount != 1 || single.AvgTotalScorePredicted != 7 || single.MedianTotalScorePredicted != 7 ||
+		single.StdDevTotalScorePredicted != 0 || single.AwayPickPct != 100 || single.AwayWinProbability != 1 {
+		t.Errorf("summarizePredictions(one) = %+v", single)
+	}
+
+	// Two home picks at 0.6 against one away pick at 0.9: two thirds of the picks, but
+	// 1.2 of the 2.1 confidence placed
+	summary := summarizePredictions(game, []models.Prediction{
+		pick("111", 5, 3, 0.6),
+		pick("111", 6, 2, 0.6),
+		pick("147", 3, 4, 0.9),
+	})
+	if math.Abs(summary.HomePickPct-200.0/3) > 1e-9 {
+		t.Errorf("HomePickPct = %v, want 66.7", summary.HomePickPct)
+	}
+	if math.Abs(summary.HomeWinProbability-1.2/2.1) > 1e-6 || math.Abs(summary.AwayWinProbability-0.9/2.1) > 1e-6 {
+		t.Errorf("win probabilities = %v/%v, want each side's share of the confidence", summary.HomeWinProbability, summary.AwayWinProbability)
+	}
+	if summary.MedianTotalScorePredicted != 8 {
+		t.Errorf("MedianTotalScorePredicted = %v, want 8", summary.MedianTotalScorePredicted)
+	}
+
+	// Without confidence, the split falls back to the picks
+	unweighted := summarizePredictions(game, []models.Prediction{pick("111", 5, 3, 0), pick("147", 3, 4, 0)})
+	if unweighted.HomeWinProbability != 0.5 || unweighted.AwayWinProbability != 0.5 {
+		t.Errorf("win probabilities without confidence = %v/%v, want the pick split", unweighted.HomeWinProbability, unweighted.AwayWinProbability)
+	}
+}
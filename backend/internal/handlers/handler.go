@@ -5,22 +5,32 @@ import (
 	"net/http"
 
 	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
 	"github.com/bendemouth/mlb-prediction-pool/internal/services"
+	"github.com/bendemouth/mlb-prediction-pool/internal/stats"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
 )
 
 // Define Handler struct
 type Handler struct {
-	db                 *database.DB
+	db                 database.Store
 	healthcheckService *services.HealthcheckService
-	S3Handler          *S3Handler
+	blobs              storage.BlobStore
+	uploads            *services.ModelUploadService
+	quotaLimits        models.ModelQuotaLimits
+	teamStats          *stats.TeamStats
 }
 
-// Create new Handler
-func NewHandler(db *database.DB, s3Client S3Handler) *Handler {
+// Create new Handler. teamStats may be nil when no data bucket is configured.
+// uploads has to be running for completed uploads to be inspected.
+func NewHandler(db database.Store, blobs storage.BlobStore, uploads *services.ModelUploadService, quotaLimits models.ModelQuotaLimits, teamStats *stats.TeamStats) *Handler {
 	return &Handler{
 		db:                 db,
 		healthcheckService: services.NewHealthcheckService(db),
-		S3Handler:          &s3Client,
+		blobs:              blobs,
+		uploads:            uploads,
+		quotaLimits:        quotaLimits,
+		teamStats:          teamStats,
 	}
 }
 
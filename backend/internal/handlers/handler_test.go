@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/middleware"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// newTestHandler returns a handler over an empty MemoryDB, without model storage or team stats
+func newTestHandler() (*Handler, *database.MemoryDB) {
+	db := database.NewMemoryDB()
+	return NewHandler(db, nil, nil, models.ModelQuotaLimits{}, nil), db
+}
+
+// serveAs runs one request through handle as if userId had signed in, and returns the recorded response
+func serveAs(handle http.HandlerFunc, method, target, userId string, body io.Reader) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, body)
+	if userId != "" {
+		request = request.WithContext(context.WithValue(request.Context(), middleware.UserSubKey, userId))
+	}
+	recorder := httptest.NewRecorder()
+	handle(recorder, request)
+	return recorder
+}
+
+// decodeResponse checks the status code and decodes the JSON body into a T
+func decodeResponse[T any](t *testing.T, recorder *httptest.ResponseRecorder, status int) T {
+	t.Helper()
+	var body T
+	if recorder.Code != status {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, status, recorder.Body.String())
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return body
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
 )
 
-// GetLeaderboard returns current standings
+// GetLeaderboard returns standings for the current season, or ?season=2025 / ?season=all
 // GET /leaderboard
 func (h *Handler) GetLeaderboard(writer http.ResponseWriter, request *http.Request) {
 	if request.Method != http.MethodGet {
@@ -12,7 +15,12 @@ func (h *Handler) GetLeaderboard(writer http.ResponseWriter, request *http.Reque
 		return
 	}
 
-	leaderboard, err := h.db.CalculateLeaderboard(request.Context())
+	scope, ok := h.leaderboardScope(writer, request)
+	if !ok {
+		return
+	}
+
+	leaderboard, err := h.db.CalculateLeaderboard(request.Context(), scope)
 
 	if err != nil {
 		h.respondError(writer, http.StatusInternalServerError, "Failed to get leaderboard")
@@ -21,3 +29,58 @@ func (h *Handler) GetLeaderboard(writer http.ResponseWriter, request *http.Reque
 
 	h.respondJson(writer, http.StatusOK, leaderboard)
 }
+
+// GetModelLeaderboard ranks each uploaded model separately, scoped by season like GetLeaderboard
+// GET /leaderboard/models
+func (h *Handler) GetModelLeaderboard(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	scope, ok := h.leaderboardScope(writer, request)
+	if !ok {
+		return
+	}
+
+	leaderboard, err := h.db.CalculateModelLeaderboard(request.Context(), scope)
+
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get model leaderboard")
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, leaderboard)
+}
+
+// GetModelStatsHandler retrieves statistics for a specific model
+// GET /models/stats?model_id=abc&season=2025
+func (h *Handler) GetModelStatsHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	modelId := request.URL.Query().Get("model_id")
+	if modelId == "" {
+		h.respondError(writer, http.StatusBadRequest, "Missing model_id parameter")
+		return
+	}
+
+	scope, ok := h.leaderboardScope(writer, request)
+	if !ok {
+		return
+	}
+
+	stats, err := h.db.GetModelStats(request.Context(), modelId, scope)
+	if err != nil {
+		if errors.Is(err, database.ErrModelNotFound) {
+			h.respondError(writer, http.StatusNotFound, "Model not found")
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get model stats")
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, stats)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// seedResults stores a finished 2024 game and a finished 2025 World Series game. u1 calls both,
+// u2 calls neither, and u2's model calls the World Series game.
+func seedResults(t *testing.T, db *database.MemoryDB) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, user := range []models.User{{Id: "u1", Username: "alice"}, {Id: "u2", Username: "bob"}} {
+		if err := db.CreateUser(ctx, &user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.CreateModel(ctx, &models.ModelMetadata{ModelId: "m1", UserId: "u2", ModelName: "elo", Status: models.ModelStatusActive}); err != nil {
+		t.Fatal(err)
+	}
+	for _, game := range []models.Game{
+		{GameId: "g1", Date: time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC), HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming},
+		{GameId: "g2", Date: time.Date(2025, 10, 25, 0, 0, 0, 0, time.UTC), HomeTeamId: "119", AwayTeamId: "141", GameType: models.GameTypeWorldSeries, Status: models.GameStatusUpcoming},
+	} {
+		if err := db.CreateGame(ctx, &game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, prediction := range []models.Prediction{
+		{UserId: "u1", GameId: "g1", PredictedWinnerId: "111", HomeScorePredicted: 4, AwayScorePredicted: 2, TotalScorePredicted: 6},
+		{UserId: "u1", GameId: "g2", PredictedWinnerId: "119", HomeScorePredicted: 5, AwayScorePredicted: 1, TotalScorePredicted: 6},
+		{UserId: "u2", GameId: "g1", PredictedWinnerId: "147", HomeScorePredicted: 2, AwayScorePredicted: 4, TotalScorePredicted: 6},
+		{UserId: "u2", GameId: "g2", PredictedWinnerId: "141", HomeScorePredicted: 1, AwayScorePredicted: 5, TotalScorePredicted: 6},
+		{UserId: "u2", GameId: "g2", ModelId: "m1", PredictedWinnerId: "119", HomeScorePredicted: 5, AwayScorePredicted: 1, TotalScorePredicted: 6},
+	} {
+		if err := db.CreatePrediction(ctx, &prediction); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.CompleteGame(ctx, "g1", 4, 2, "111"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CompleteGame(ctx, "g2", 5, 1, "119"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	h, db := newTestHandler()
+	seedResults(t, db)
+
+	tests := []struct {
+		target      string
+		wantSeason  int
+		wantCorrect int
+	}{
+		{"/leaderboard?season=all", 0, 2},
+		{"/leaderboard?season=2025", 2025, 1},
+		{"/leaderboard?season=2024", 2024, 1},
+	}
+	for _, tt := range tests {
+		recorder := serveAs(h.GetLeaderboard, http.MethodGet, tt.target, "u1", nil)
+		leaderboard := decodeResponse[[]models.LeaderboardEntry](t, recorder, http.StatusOK)
+		if len(leaderboard) != 2 {
+			t.Fatalf("GET %s returned %d entries, want 2", tt.target, len(leaderboard))
+		}
+
+		leader := leaderboard[0]
+		if leader.UserId != "u1" || leader.Rank != 1 || leader.Season != tt.wantSeason || leader.TotalWinnersCorrect != tt.wantCorrect {
+			t.Errorf("GET %s leader = %+v, want u1 ranked first in %d with %d correct", tt.target, leader, tt.wantSeason, tt.wantCorrect)
+		}
+		// u2's model called g2, but the user leaderboard only counts u2's own picks
+		if runnerUp := leaderboard[1]; runnerUp.UserId != "u2" || runnerUp.Rank != 2 || runnerUp.TotalWinnersCorrect != 0 {
+			t.Errorf("GET %s runner up = %+v, want u2 ranked second with none correct", tt.target, runnerUp)
+		}
+	}
+
+	for _, target := range []string{"/leaderboard?season=next", "/leaderboard?season=1492"} {
+		if recorder := serveAs(h.GetLeaderboard, http.MethodGet, target, "u1", nil); recorder.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want %d", target, recorder.Code, http.StatusBadRequest)
+		}
+	}
+	if recorder := serveAs(h.GetLeaderboard, http.MethodPost, "/leaderboard", "u1", nil); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGetModelLeaderboard(t *testing.T) {
+	h, db := newTestHandler()
+	seedResults(t, db)
+
+	recorder := serveAs(h.GetModelLeaderboard, http.MethodGet, "/leaderboard/models?season=2025", "u1", nil)
+	leaderboard := decodeResponse[[]models.LeaderboardEntry](t, recorder, http.StatusOK)
+	if len(leaderboard) != 1 {
+		t.Fatalf("model leaderboard has %d entries, want 1: %+v", len(leaderboard), leaderboard)
+	}
+	if got := leaderboard[0]; got.ModelId != "m1" || got.ModelName != "elo" || got.UserId != "u2" || got.Rank != 1 || got.TotalWinnersCorrect != 1 {
+		t.Errorf("model leaderboard = %+v, want u2's elo first with one winner", got)
+	}
+}
+
+func TestGetModelStatsHandler(t *testing.T) {
+	h, db := newTestHandler()
+	seedResults(t, db)
+
+	recorder := serveAs(h.GetModelStatsHandler, http.MethodGet, "/models/stats?model_id=m1&season=all", "u1", nil)
+	if stats := decodeResponse[models.LeaderboardEntry](t, recorder, http.StatusOK); stats.ModelId != "m1" || stats.Rank != 1 || stats.WinnerAccuracy != 1 {
+		t.Errorf("stats = %+v, want m1 ranked first and always right", stats)
+	}
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/models/stats?season=all", http.StatusBadRequest},
+		{"/models/stats?model_id=missing&season=all", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if recorder := serveAs(h.GetModelStatsHandler, http.MethodGet, tt.target, "u1", nil); recorder.Code != tt.status {
+			t.Errorf("GET %s status = %d, want %d", tt.target, recorder.Code, tt.status)
+		}
+	}
+}
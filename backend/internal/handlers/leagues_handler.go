@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/middleware"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// LeagueSummary is a league as one user sees it. The invite code is only shown to the owner.
+type LeagueSummary struct {
+	models.League
+	// Role is the caller's role, empty if they aren't a member
+	Role        string `json:"role,omitempty"`
+	MemberCount int    `json:"member_count"`
+}
+
+// inviteCodeAlphabet leaves out characters that are easily confused when read aloud or typed
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// generateInviteCode generates an eight character invite code
+func generateInviteCode() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	for i := range b {
+		b[i] = inviteCodeAlphabet[int(b[i])%len(inviteCodeAlphabet)]
+	}
+	return string(b)
+}
+
+// normalizeLeagueSettings fills in defaults for settings left empty and checks the rest
+func normalizeLeagueSettings(settings *models.LeagueSettings) error {
+	if settings.ScoringProfile == "" {
+		settings.ScoringProfile = models.ScoringProfileStandard
+	}
+	if settings.Visibility == "" {
+		settings.Visibility = models.LeagueVisibilityPrivate
+	}
+	if settings.PredictionVisibility == "" {
+		settings.PredictionVisibility = models.PredictionVisibilityLocked
+	}
+
+	if !database.IsScoringProfile(settings.ScoringProfile) {
+		return fmt.Errorf("unknown scoring profile %q", settings.ScoringProfile)
+	}
+	if settings.Visibility != models.LeagueVisibilityPrivate && settings.Visibility != models.LeagueVisibilityPublic {
+		return fmt.Errorf("visibility must be %s or %s", models.LeagueVisibilityPrivate, models.LeagueVisibilityPublic)
+	}
+	if settings.PredictionVisibility != models.PredictionVisibilityLocked && settings.PredictionVisibility != models.PredictionVisibilityOpen {
+		return fmt.Errorf("prediction_visibility must be %s or %s", models.PredictionVisibilityLocked, models.PredictionVisibilityOpen)
+	}
+	for _, gameType := range settings.GameTypes {
+		if !slices.Contains(models.GameTypes, gameType) {
+			return fmt.Errorf("unknown game type %q", gameType)
+		}
+	}
+
+	return nil
+}
+
+// summarizeLeague builds the view of league userId gets
+func summarizeLeague(league models.League, userId string, members []models.LeagueMember) LeagueSummary {
+	summary := LeagueSummary{League: league, MemberCount: len(members)}
+	if league.OwnerId != userId {
+		summary.InviteCode = ""
+	}
+	for _, member := range members {
+		if member.UserId == userId {
+			summary.Role = member.Role
+		}
+	}
+	return summary
+}
+
+// CreateLeagueHandler creates a league owned by the caller
+// POST /leagues/create
+func (h *Handler) CreateLeagueHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userId, ok := middleware.GetUserSub(request)
+	if !ok || userId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "User ID required in context")
+		return
+	}
+
+	var req struct {
+		Name     string                `json:"name"`
+		Settings models.LeagueSettings `json:"settings"`
+	}
+	if err := h.decodeJsonBody(request, &req); err != nil {
+		h.respondError(writer, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		h.respondError(writer, http.StatusBadRequest, "League name is required")
+		return
+	}
+	if err := normalizeLeagueSettings(&req.Settings); err != nil {
+		h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid league settings: %s", err.Error()))
+		return
+	}
+
+	league := &models.League{
+		LeagueId:   generateUUID(),
+		Name:       req.Name,
+		OwnerId:    userId,
+		InviteCode: generateInviteCode(),
+		Settings:   req.Settings,
+	}
+
+	if err := h.db.CreateLeague(request.Context(), league); err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to create league")
+		return
+	}
+
+	h.respondJson(writer, http.StatusCreated, LeagueSummary{League: *league, Role: models.LeagueRoleOwner, MemberCount: 1})
+}
+
+// GetUserLeaguesHandler lists the leagues the caller belongs to
+// GET /leagues
+func (h *Handler) GetUserLeaguesHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userId, ok := middleware.GetUserSub(request)
+	if !ok || userId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "User ID required in context")
+		return
+	}
+
+	leagues, err := h.db.GetUserLeagues(request.Context(), userId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get leagues")
+		return
+	}
+
+	summaries := make([]LeagueSummary, 0, len(leagues))
+	for _, league := range leagues {
+		members, err := h.db.GetLeagueMembers(request.Context(), league.LeagueId)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, "Failed to get league members")
+			return
+		}
+		summaries = append(summaries, summarizeLeague(league, userId, members))
+	}
+
+	h.respondJson(writer, http.StatusOK, summaries)
+}
+
+// JoinLeagueHandler adds the caller to a league, by invite code or, for a public league, by id
+// POST /leagues/join
+func (h *Handler) JoinLeagueHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userId, ok := middleware.GetUserSub(request)
+	if !ok || userId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "User ID required in context")
+		return
+	}
+
+	var req struct {
+		InviteCode string `json:"invite_code"`
+		LeagueId   string `json:"league_id"`
+	}
+	if err := h.decodeJsonBody(request, &req); err != nil {
+		h.respondError(writer, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var league *models.League
+	var err error
+	switch {
+	case req.InviteCode != "":
+		league, err = h.db.GetLeagueByInviteCode(request.Context(), strings.ToUpper(strings.TrimSpace(req.InviteCode)))
+	case req.LeagueId != "":
+		league, err = h.db.GetLeague(request.Context(), req.LeagueId)
+		// A private league can only be joined with its invite code, and looks missing without one
+		if err == nil && league.Settings.Visibility != models.LeagueVisibilityPublic {
+			err = database.ErrLeagueNotFound
+		}
+	default:
+		h.respondError(writer, http.StatusBadRequest, "An invite_code or league_id is required")
+		return
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrLeagueNotFound) {
+			h.respondError(writer, http.StatusNotFound, "League not found")
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get league")
+		return
+	}
+
+	member := &models.LeagueMember{
+		LeagueId: league.LeagueId,
+		UserId:   userId,
+		Role:     models.LeagueRoleMember,
+	}
+	if err := h.db.AddLeagueMember(request.Context(), member); err != nil {
+		if errors.Is(err, database.ErrAlreadyLeagueMember) {
+			h.respondError(writer, http.StatusConflict, "Already a member of this league")
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, "Failed to join league")
+		return
+	}
+
+	h.respondJson(writer, http.StatusCreated, member)
+}
+
+// LeagueHandler returns a league, or lets its owner rename it and change its settings
+// GET /leagues/{leagueId}
+// PUT /leagues/{leagueId}
+func (h *Handler) LeagueHandler(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+		h.getLeague(writer, request)
+	case http.MethodPut:
+		h.updateLeague(writer, request)
+	default:
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *Handler) getLeague(writer http.ResponseWriter, request *http.Request) {
+	league, userId, ok := h.leagueFromPath(writer, request, false)
+	if !ok {
+		return
+	}
+
+	members, err := h.db.GetLeagueMembers(request.Context(), league.LeagueId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get league members")
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, summarizeLeague(*league, userId, members))
+}
+
+func (h *Handler) updateLeague(writer http.ResponseWriter, request *http.Request) {
+	league, userId, ok := h.leagueFromPath(writer, request, true)
+	if !ok {
+		return
+	}
+	if league.OwnerId != userId {
+		h.respondError(writer, http.StatusForbidden, "Only the league owner can change the league")
+		return
+	}
+
+	// A name or settings left out of the body are unchanged; settings sent replace the old ones whole
+	var req struct {
+		Name     *string                `json:"name"`
+		Settings *models.LeagueSettings `json:"settings"`
+	}
+	if err := h.decodeJsonBody(request, &req); err != nil {
+		h.respondError(writer, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			h.respondError(writer, http.StatusBadRequest, "League name is required")
+			return
+		}
+		league.Name = name
+	}
+	if req.Settings != nil {
+		if err := normalizeLeagueSettings(req.Settings); err != nil {
+			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid league settings: %s", err.Error()))
+			return
+		}
+		league.Settings = *req.Settings
+	}
+
+	if err := h.db.UpdateLeague(request.Context(), league); err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to update league")
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, league)
+}
+
+// RegenerateInviteHandler replaces a league's invite code, so the old one stops working
+// POST /leagues/invite/{leagueId}
+func (h *Handler) RegenerateInviteHandler(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodPost {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	league, userId, ok := h.leagueFromPath(writer, request, true)
+	if !ok {
+		return
+	}
+	if league.OwnerId != userId {
+		h.respondError(writer, http.StatusForbidden, "Only the league owner can change the invite code")
+		return
+	}
+
+	league.InviteCode = generateInviteCode()
+	if err := h.db.UpdateLeague(request.Context(), league); err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to update invite code")
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, map[string]string{"invite_code": league.InviteCode})
+}
+
+// LeagueMembersHandler lists a league's members, or removes one. Members can remove themselves
+// to leave; the owner can remove anyone but themselves.
+// GET /leagues/members/{leagueId}
+// DELETE /leagues/members/{leagueId}?user_id=abc
+func (h *Handler) LeagueMembersHandler(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+		league, _, ok := h.leagueFromPath(writer, request, false)
+		if !ok {
+			return
+		}
+
+		members, err := h.db.GetLeagueMembers(request.Context(), league.LeagueId)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, "Failed to get league members")
+			return
+		}
+		h.respondJson(writer, http.StatusOK, members)
+
+	case http.MethodDelete:
+		league, userId, ok := h.leagueFromPath(writer, request, true)
+		if !ok {
+			return
+		}
+
+		removed := request.URL.Query().Get("user_id")
+		if removed == "" {
+			removed = userId
+		}
+		switch {
+		case removed == league.OwnerId:
+			h.respondError(writer, http.StatusBadRequest, "The league owner can't leave the league")
+			return
+		case removed != userId && league.OwnerId != userId:
+			h.respondError(writer, http.StatusForbidden, "Only the league owner can remove other members")
+			return
+		}
+
+		if err := h.db.RemoveLeagueMember(request.Context(), league.LeagueId, removed); err != nil {
+			if errors.Is(err, database.ErrLeagueMemberNotFound) {
+				h.respondError(writer, http.StatusNotFound, "League member not found")
+				return
+			}
+			h.respondError(writer, http.StatusInternalServerError, "Failed to remove league member")
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
+
+	default:
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// GetLeagueLeaderboard ranks a league's members under its settings, scoped by season like GetLeaderboard
+// GET /leagues/leaderboard/{leagueId}
+func (h *Handler) GetLeagueLeaderboard(writer http.ResponseWriter, request *http.Request) {
+	h.leagueLeaderboard(writer, request, h.db.CalculateLeaderboard)
+}
+
+// GetLeagueModelLeaderboard ranks the models of a league's members
+// GET /leagues/leaderboard/models/{leagueId}
+func (h *Handler) GetLeagueModelLeaderboard(writer http.ResponseWriter, request *http.Request) {
+	h.leagueLeaderboard(writer, request, h.db.CalculateModelLeaderboard)
+}
+
+func (h *Handler) leagueLeaderboard(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calculate func(ctx context.Context, scope database.LeaderboardScope) ([]models.LeaderboardEntry, error),
+) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	league, _, ok := h.leagueFromPath(writer, request, false)
+	if !ok {
+		return
+	}
+
+	scope, ok := h.leaderboardScope(writer, request)
+	if !ok {
+		return
+	}
+
+	members, err := h.db.GetLeagueMembers(request.Context(), league.LeagueId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get league members")
+		return
+	}
+
+	leaderboard, err := calculate(request.Context(), database.LeagueScope(scope, league, members))
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get league leaderboard")
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, leaderboard)
+}
+
+// GetLeaguePredictions returns the predictions a league's members made on a game. Unless the
+// league shows picks openly, other members' picks stay hidden until the game locks.
+// GET /leagues/predictions/{leagueId}?game_id=123
+func (h *Handler) GetLeaguePredictions(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	league, userId, ok := h.leagueFromPath(writer, request, true)
+	if !ok {
+		return
+	}
+
+	gameId := request.URL.Query().Get("game_id")
+	if gameId == "" {
+		h.respondError(writer, http.StatusBadRequest, "Missing game_id parameter")
+		return
+	}
+
+	game, err := h.db.GetGame(request.Context(), gameId)
+	if err != nil {
+		h.respondError(writer, http.StatusNotFound, "Game not found")
+		return
+	}
+
+	revealed := league.Settings.PredictionVisibility == models.PredictionVisibilityOpen
+	if !revealed {
+		if revealed, err = h.predictionsRevealed(request.Context(), game); err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+			return
+		}
+	}
+
+	members, err := h.db.GetLeagueMembers(request.Context(), league.LeagueId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get league members")
+		return
+	}
+	memberIds := make(map[string]bool, len(members))
+	for _, member := range members {
+		memberIds[member.UserId] = true
+	}
+
+	predictions, err := h.db.GetPredictionsByGame(request.Context(), gameId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get predictions")
+		return
+	}
+
+	visible := make([]models.Prediction, 0)
+	for _, prediction := range predictions {
+		if memberIds[prediction.UserId] && (revealed || prediction.UserId == userId) {
+			visible = append(visible, prediction)
+		}
+	}
+
+	h.respondJson(writer, http.StatusOK, visible)
+}
+
+// leagueFromPath loads the league whose id ends the path, and the caller's user id, see leagueForUser.
+// On failure it writes the error response and returns false.
+func (h *Handler) leagueFromPath(w http.ResponseWriter, r *http.Request, membersOnly bool) (*models.League, string, bool) {
+	parts := strings.Split(r.URL.Path, "/")
+	leagueId := parts[len(parts)-1]
+	if len(parts) < 3 || leagueId == "" {
+		h.respondError(w, http.StatusBadRequest, "League ID is required")
+		return nil, "", false
+	}
+
+	return h.leagueForUser(w, r, leagueId, membersOnly)
+}
+
+// leagueForUser loads a league the caller may see, and the caller's user id. Anyone can see a
+// public league unless membersOnly is set; otherwise non-members get a 404, so private leagues
+// aren't revealed. On failure it writes the error response and returns false.
+func (h *Handler) leagueForUser(w http.ResponseWriter, r *http.Request, leagueId string, membersOnly bool) (*models.League, string, bool) {
+	userId, ok := r.Context().Value(middleware.UserSubKey).(string)
+	if !ok || userId == "" {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized: invalid user context")
+		return nil, "", false
+	}
+
+	league, err := h.db.GetLeague(r.Context(), leagueId)
+	if err != nil {
+		if errors.Is(err, database.ErrLeagueNotFound) {
+			h.respondError(w, http.StatusNotFound, "League not found")
+			return nil, "", false
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to get league")
+		return nil, "", false
+	}
+
+	if membersOnly || league.Settings.Visibility != models.LeagueVisibilityPublic {
+		if _, err := h.db.GetLeagueMember(r.Context(), leagueId, userId); err != nil {
+			if errors.Is(err, database.ErrLeagueMemberNotFound) {
+				h.respondError(w, http.StatusNotFound, "League not found")
+				return nil, "", false
+			}
+			h.respondError(w, http.StatusInternalServerError, "Failed to get league member")
+			return nil, "", false
+		}
+	}
+
+	return league, userId, true
+}
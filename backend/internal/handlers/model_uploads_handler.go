@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/middleware"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/requests"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+	"github.com/bendemouth/mlb-prediction-pool/internal/validation"
+)
+
+// uploadURLExpiry is how long a presigned upload URL stays valid
+const uploadURLExpiry = 15 * time.Minute
+
+// ModelUpload is a pending model and the signed request that uploads its file.
+// Model files are capped well below S3's 5 GB single PUT limit, so one PUT is always enough.
+type ModelUpload struct {
+	Model  *models.ModelMetadata    `json:"model"`
+	Upload *storage.PresignedUpload `json:"upload"`
+}
+
+// CreateModelUpload creates a pending model and returns a presigned PUT for its file
+// POST /models/uploads
+func (h *Handler) CreateModelUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userId, ok := r.Context().Value(middleware.UserSubKey).(string)
+	if !ok || userId == "" {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized: invalid user context")
+		return
+	}
+
+	var req requests.CreateModelUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.ModelName == "" {
+		h.respondError(w, http.StatusBadRequest, "Model name is required")
+		return
+	}
+	var framework string
+	switch path.Ext(req.FileName) {
+	case ".pkl":
+		framework = models.ModelFrameworkPickle
+	case ".onnx":
+		framework = models.ModelFrameworkONNX
+	default:
+		h.respondError(w, http.StatusBadRequest, "Unsupported file type, only .pkl and .onnx files are allowed")
+		return
+	}
+	if req.SizeBytes <= 0 || req.SizeBytes > validation.MaxModelSize {
+		h.respondError(w, http.StatusBadRequest, fmt.Sprintf("size_bytes must be between 1 and %d", validation.MaxModelSize))
+		return
+	}
+
+	features, err := validation.CheckFeatures(framework, req.Features)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	contentType := req.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	// The store verifies the upload against the base64 checksum, we keep the hex form like Inspect reports it
+	digest, err := hex.DecodeString(req.Sha256)
+	if err != nil || len(digest) != 32 {
+		h.respondError(w, http.StatusBadRequest, "sha256 must be a hex-encoded SHA-256 digest")
+		return
+	}
+
+	versions, err := h.db.GetModelVersions(r.Context(), userId, req.ModelName)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to look up model versions: "+err.Error())
+		return
+	}
+
+	if !h.checkUploadQuota(w, r, userId, req.SizeBytes, versions) {
+		return
+	}
+
+	model := newPendingModel(userId, req.ModelName, path.Base(req.FileName), req.Changelog, versions)
+	model.Sha256 = strings.ToLower(req.Sha256)
+	model.SizeBytes = req.SizeBytes
+	model.Framework = framework
+	model.ContentType = contentType
+	model.Features = features
+
+	upload, err := h.blobs.PresignPut(r.Context(), model.S3Key, req.SizeBytes, base64.StdEncoding.EncodeToString(digest), contentType, uploadURLExpiry)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to create upload URL: "+err.Error())
+		return
+	}
+
+	if err := h.createModelVersion(r.Context(), model); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrModelVersionTaken) {
+			status = http.StatusConflict
+		}
+		h.respondError(w, status, "Failed to save model metadata: "+err.Error())
+		return
+	}
+
+	h.respondJson(w, http.StatusCreated, ModelUpload{Model: model, Upload: upload})
+}
+
+// CompleteModelUpload checks that a pending model's file has arrived and queues it for inspection.
+// The model stays pending until the file has been inspected; the owner sees the outcome in its status.
+// POST /models/uploads/{modelId}/complete
+func (h *Handler) CompleteModelUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userId, ok := r.Context().Value(middleware.UserSubKey).(string)
+	if !ok || userId == "" {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized: invalid user context")
+		return
+	}
+
+	modelId, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/models/uploads/"), "/complete")
+	if !ok || modelId == "" || strings.Contains(modelId, "/") {
+		h.respondError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	model, err := h.db.GetModelById(r.Context(), modelId, userId)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "Model not found")
+		return
+	}
+	if model.Status != models.ModelStatusPending {
+		h.respondError(w, http.StatusConflict, "Model upload is already complete")
+		return
+	}
+
+	if _, err := h.blobs.Head(r.Context(), model.S3Key); err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			h.respondError(w, http.StatusConflict, "Model file has not been uploaded yet")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to check uploaded file: "+err.Error())
+		return
+	}
+
+	// Reading and inspecting the file can take longer than a request may, so it happens in the background
+	if !h.uploads.Enqueue(model) {
+		log.Printf("Inspection queue is full, model %s is left for the reconcile job", model.ModelId)
+	}
+
+	h.respondJson(w, http.StatusAccepted, map[string]interface{}{
+		"message": "Model uploaded, it is being validated. Check your models page for the result.",
+		"model":   model,
+	})
+}
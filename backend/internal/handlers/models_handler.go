@@ -1,12 +1,39 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
 	"github.com/bendemouth/mlb-prediction-pool/internal/middleware"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/quota"
+	"github.com/bendemouth/mlb-prediction-pool/internal/services"
 )
 
+// ModelVersionSummary is one version of a model family with how it has performed
+type ModelVersionSummary struct {
+	Model *models.ModelMetadata    `json:"model"`
+	Stats *models.LeaderboardEntry `json:"stats,omitempty"`
+}
+
+// UserModels is a user's models along with how much of their quota they use
+type UserModels struct {
+	Models []*models.ModelMetadata `json:"models"`
+	Quota  models.ModelQuotaUsage  `json:"quota"`
+}
+
+// userModelsPage is a page of a user's models, with quota usage across all of them
+type userModelsPage struct {
+	pagedResponse
+	Quota models.ModelQuotaUsage `json:"quota"`
+}
+
+// GetUserModelsHandler lists the caller's models and their quota usage
+// GET /models
 func (h *Handler) GetUserModelsHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from context
 	userId, ok := r.Context().Value(middleware.UserSubKey).(string)
@@ -15,6 +42,29 @@ func (h *Handler) GetUserModelsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, paged, err := parsePageRequest(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Usage covers every model, not just the requested page
+	usage, err := h.quotaUsage(r.Context(), userId)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve models: "+err.Error())
+		return
+	}
+
+	if paged {
+		userModels, next, err := h.db.GetModelsByUserIdPage(r.Context(), userId, page)
+		if err != nil {
+			h.respondPageError(w, err, "Failed to retrieve models: ")
+			return
+		}
+		h.respondJson(w, http.StatusOK, userModelsPage{pagedResponse: pagedResponse{Items: userModels, Next: next}, Quota: usage})
+		return
+	}
+
 	// Get all models for the user
 	userModels, err := h.db.GetModelsByUserId(r.Context(), userId)
 	if err != nil {
@@ -23,14 +73,11 @@ func (h *Handler) GetUserModelsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Ensure we return a JSON array, not null
-	var response interface{}
-	if len(userModels) == 0 {
-		response = []interface{}{}
-	} else {
-		response = userModels
+	if userModels == nil {
+		userModels = []*models.ModelMetadata{}
 	}
 
-	h.respondJson(w, http.StatusOK, response)
+	h.respondJson(w, http.StatusOK, UserModels{Models: userModels, Quota: usage})
 }
 
 func (h *Handler) DeleteModelHandler(w http.ResponseWriter, r *http.Request) {
@@ -67,18 +114,31 @@ func (h *Handler) DeleteModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Delete the file from S3
-	// success, _, err := h.S3Handler.DeleteFileFromS3(model.S3Key, r.Context())
-	// if !success {
-	//     // Log error but don't fail the request - model is already deleted from DB
-	//     log.Printf("Failed to delete S3 file: %v", err)
-	// }
+	// The record is gone, so a failure here only leaves an orphaned file for cmd/reconcile to clean up
+	if model.S3Key != "" {
+		if err := h.blobs.Delete(r.Context(), model.S3Key); err != nil {
+			log.Printf("Failed to delete model file %s for model %s: %v", model.S3Key, modelId, err)
+		}
+	}
 
 	h.respondJson(w, http.StatusOK, map[string]string{
 		"message": "Model deleted successfully",
 	})
 }
 
+// downloadURLExpiry is how long a presigned download URL stays valid
+const downloadURLExpiry = 5 * time.Minute
+
+// ModelDownload is a short-lived link to a model's file along with the metadata to verify it by
+type ModelDownload struct {
+	URL       string                `json:"url"`
+	ExpiresAt time.Time             `json:"expires_at"`
+	Model     *models.ModelMetadata `json:"model"`
+}
+
+// GetModelHandler returns a model's metadata, or a download link for its file
+// GET /models/{modelId}
+// GET /models/{modelId}/download
 func (h *Handler) GetModelHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from context
 	userId, ok := r.Context().Value(middleware.UserSubKey).(string)
@@ -87,15 +147,9 @@ func (h *Handler) GetModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get model ID from URL path (e.g., /models/{modelId})
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 4 {
-		h.respondError(w, http.StatusBadRequest, "Invalid request path")
-		return
-	}
-
-	modelId := parts[len(parts)-1]
-	if modelId == "" {
+	// Get model ID from URL path (e.g., /models/{modelId} or /models/{modelId}/download)
+	modelId, download := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/models/"), "/download")
+	if modelId == "" || strings.Contains(modelId, "/") {
 		h.respondError(w, http.StatusBadRequest, "Model ID is required")
 		return
 	}
@@ -107,5 +161,199 @@ func (h *Handler) GetModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !download {
+		h.respondJson(w, http.StatusOK, model)
+		return
+	}
+
+	// Rejected files are never stored, and pending ones may not have finished uploading
+	switch {
+	case model.Status == models.ModelStatusPending:
+		h.respondError(w, http.StatusConflict, "Model upload is not complete")
+		return
+	case model.S3Key == "":
+		h.respondError(w, http.StatusNotFound, "Model file not available")
+		return
+	}
+
+	url, err := h.blobs.PresignGet(r.Context(), model.S3Key, model.FileName, downloadURLExpiry)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to create download URL: "+err.Error())
+		return
+	}
+
+	h.respondJson(w, http.StatusOK, ModelDownload{
+		URL:       url,
+		ExpiresAt: time.Now().Add(downloadURLExpiry),
+		Model:     model,
+	})
+}
+
+// GetModelVersionsHandler lists every version in a model's family with per-version stats
+// GET /models/versions/{modelId}?season=2025
+func (h *Handler) GetModelVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	model, userId, ok := h.modelFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.db.GetModelVersions(r.Context(), userId, model.ModelName)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve model versions: "+err.Error())
+		return
+	}
+
+	scope, ok := h.leaderboardScope(w, r)
+	if !ok {
+		return
+	}
+
+	leaderboard, err := h.db.CalculateModelLeaderboard(r.Context(), scope)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to calculate model stats: "+err.Error())
+		return
+	}
+
+	stats := make(map[string]*models.LeaderboardEntry, len(leaderboard))
+	for i := range leaderboard {
+		stats[leaderboard[i].ModelId] = &leaderboard[i]
+	}
+
+	summaries := make([]ModelVersionSummary, 0, len(versions))
+	for _, version := range versions {
+		summaries = append(summaries, ModelVersionSummary{Model: version, Stats: stats[version.ModelId]})
+	}
+
+	h.respondJson(w, http.StatusOK, summaries)
+}
+
+// PromoteModelHandler makes a validated version the one that runs for its family
+// POST /models/promote/{modelId}
+func (h *Handler) PromoteModelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	model, userId, ok := h.modelFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	// Swapping a family's active version doesn't change how many models are active
+	versions, err := h.db.GetModelVersions(r.Context(), userId, model.ModelName)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve model versions: "+err.Error())
+		return
+	}
+	if !services.HasActiveVersion(versions) {
+		usage, err := h.quotaUsage(r.Context(), userId)
+		if err == nil {
+			err = quota.CheckActivation(usage)
+		}
+		if err != nil {
+			h.respondQuotaError(w, err)
+			return
+		}
+	}
+
+	h.promoteModel(w, r, model.ModelId, userId)
+}
+
+// RollbackModelHandler re-promotes the version that was active before the given active version
+// POST /models/rollback/{modelId}
+func (h *Handler) RollbackModelHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	model, userId, ok := h.modelFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if model.Status != models.ModelStatusActive {
+		h.respondError(w, http.StatusConflict, "Only the active version can be rolled back")
+		return
+	}
+
+	versions, err := h.db.GetModelVersions(r.Context(), userId, model.ModelName)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve model versions: "+err.Error())
+		return
+	}
+
+	previous := previousActiveVersion(model, versions)
+	if previous == nil {
+		h.respondError(w, http.StatusConflict, "No earlier version to roll back to")
+		return
+	}
+
+	h.promoteModel(w, r, previous.ModelId, userId)
+}
+
+func (h *Handler) promoteModel(w http.ResponseWriter, r *http.Request, modelId string, userId string) {
+	if err := h.db.PromoteModel(r.Context(), modelId, userId); err != nil {
+		switch {
+		case errors.Is(err, database.ErrModelNotFound):
+			h.respondError(w, http.StatusNotFound, "Model not found")
+		case errors.Is(err, database.ErrModelNotPromotable):
+			h.respondError(w, http.StatusConflict, err.Error())
+		default:
+			h.respondError(w, http.StatusInternalServerError, "Failed to promote model: "+err.Error())
+		}
+		return
+	}
+
+	model, err := h.db.GetModelById(r.Context(), modelId, userId)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve model: "+err.Error())
+		return
+	}
+
 	h.respondJson(w, http.StatusOK, model)
 }
+
+// modelFromPath loads the caller's model named by the last path segment, e.g. /models/promote/{modelId}
+func (h *Handler) modelFromPath(w http.ResponseWriter, r *http.Request) (*models.ModelMetadata, string, bool) {
+	userId, ok := r.Context().Value(middleware.UserSubKey).(string)
+	if !ok || userId == "" {
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized: invalid user context")
+		return nil, "", false
+	}
+
+	parts := strings.Split(r.URL.Path, "/")
+	modelId := parts[len(parts)-1]
+	if len(parts) < 4 || modelId == "" {
+		h.respondError(w, http.StatusBadRequest, "Model ID is required")
+		return nil, "", false
+	}
+
+	model, err := h.db.GetModelById(r.Context(), modelId, userId)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "Model not found")
+		return nil, "", false
+	}
+
+	return model, userId, true
+}
+
+// previousActiveVersion is the most recently promoted version other than current that can still run
+func previousActiveVersion(current *models.ModelMetadata, versions []*models.ModelMetadata) *models.ModelMetadata {
+	var previous *models.ModelMetadata
+	for _, version := range versions {
+		if version.ModelId == current.ModelId || version.Status != models.ModelStatusValidated || version.PromotedAt == nil {
+			continue
+		}
+		if previous == nil || version.PromotedAt.After(*previous.PromotedAt) {
+			previous = version
+		}
+	}
+	return previous
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// pagedResponse is returned by list endpoints when the caller asks for a page
+type pagedResponse struct {
+	Items interface{} `json:"items"`
+	Next  string      `json:"next,omitempty"`
+}
+
+// parsePageRequest reads the optional ?limit= and ?next= query parameters.
+// paged is false when neither is set, in which case callers return the full list as before.
+func parsePageRequest(request *http.Request) (page database.PageRequest, paged bool, err error) {
+	query := request.URL.Query()
+	limitParam := query.Get("limit")
+	page.Cursor = query.Get("next")
+
+	if limitParam == "" && page.Cursor == "" {
+		return page, false, nil
+	}
+
+	page.Limit = defaultPageLimit
+	if limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return page, true, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		page.Limit = int32(limit)
+	}
+
+	return page, true, nil
+}
+
+// respondPageError maps a bad cursor to 400 and anything else to 500
+func (h *Handler) respondPageError(writer http.ResponseWriter, err error, message string) {
+	if errors.Is(err, database.ErrInvalidCursor) {
+		h.respondError(writer, http.StatusBadRequest, "Invalid next token")
+		return
+	}
+	h.respondError(writer, http.StatusInternalServerError, message+err.Error())
+}
@@ -1,17 +1,20 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
 	"github.com/bendemouth/mlb-prediction-pool/internal/middleware"
 	"github.com/bendemouth/mlb-prediction-pool/internal/models"
 	"github.com/bendemouth/mlb-prediction-pool/internal/requests"
 )
 
-// Handle GET /predictions
-// Eg: /predictions?userId=123
+// Handle GET /predictions. Another user's picks on games that haven't locked are left out.
+// Eg: /predictions?userId=123&limit=50&next=token
 func (h *Handler) GetPredictionsByUser(writer http.ResponseWriter, request *http.Request) {
 	userId := request.URL.Query().Get("userId")
 	if userId == "" {
@@ -19,11 +22,43 @@ func (h *Handler) GetPredictionsByUser(writer http.ResponseWriter, request *http
 		return
 	}
 
+	callerId, ok := request.Context().Value(middleware.UserSubKey).(string)
+	if !ok || callerId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "User ID required in context")
+		return
+	}
+
+	page, paged, err := parsePageRequest(request)
+	if err != nil {
+		h.respondError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if paged {
+		predictions, next, err := h.db.GetUserPredictionsPage(request.Context(), userId, page)
+		if err != nil {
+			h.respondPageError(writer, err, "Failed to get predictions: ")
+			return
+		}
+		predictions, err = h.visiblePredictions(request.Context(), callerId, predictions)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+			return
+		}
+		h.respondJson(writer, http.StatusOK, pagedResponse{Items: predictions, Next: next})
+		return
+	}
+
 	predictions, err := h.db.GetUserPredictions(request.Context(), userId)
 	if err != nil {
 		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get predictions: ", err))
 		return
 	}
+	predictions, err = h.visiblePredictions(request.Context(), callerId, predictions)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
 
 	h.respondJson(writer, http.StatusOK, predictions)
 }
@@ -55,10 +90,16 @@ func (h *Handler) CreatePrediction(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
+	if err := h.checkModelOwnership(request, req.ModelId, userId); err != nil {
+		h.respondModelOwnershipError(writer, err)
+		return
+	}
+
 	// Create prediction
 	prediction := &models.Prediction{
 		UserId:              userId,
 		GameId:              req.GameId,
+		ModelId:             req.ModelId,
 		HomeScorePredicted:  req.HomeScorePredicted,
 		AwayScorePredicted:  req.AwayScorePredicted,
 		TotalScorePredicted: req.TotalScorePredicted,
@@ -66,7 +107,13 @@ func (h *Handler) CreatePrediction(writer http.ResponseWriter, request *http.Req
 		PredictedWinnerId:   req.PredictedWinnerId,
 	}
 
-	if err := validateGamePrediction(*prediction, game); err != nil {
+	lock, err := h.predictionLock(request.Context(), game)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
+
+	if err := validateGamePrediction(*prediction, game, lock); err != nil {
 		h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid prediction: %s", err.Error()))
 		return
 	}
@@ -103,6 +150,7 @@ func (h *Handler) CreateBulkPredictions(writer http.ResponseWriter, request *htt
 	}
 
 	predictions := make([]models.Prediction, 0, len(req.Predictions))
+	ownedModels := make(map[string]bool)
 
 	for _, prediction := range req.Predictions {
 		game, err := h.db.GetGame(request.Context(), prediction.GameId)
@@ -111,9 +159,18 @@ func (h *Handler) CreateBulkPredictions(writer http.ResponseWriter, request *htt
 			return
 		}
 
+		if !ownedModels[prediction.ModelId] {
+			if err := h.checkModelOwnership(request, prediction.ModelId, userId); err != nil {
+				h.respondModelOwnershipError(writer, err)
+				return
+			}
+			ownedModels[prediction.ModelId] = true
+		}
+
 		prediction := models.Prediction{
 			UserId:              userId,
 			GameId:              prediction.GameId,
+			ModelId:             prediction.ModelId,
 			HomeScorePredicted:  prediction.HomeScorePredicted,
 			AwayScorePredicted:  prediction.AwayScorePredicted,
 			TotalScorePredicted: prediction.TotalScorePredicted,
@@ -121,7 +178,13 @@ func (h *Handler) CreateBulkPredictions(writer http.ResponseWriter, request *htt
 			PredictedWinnerId:   prediction.PredictedWinnerId,
 		}
 
-		if err := validateGamePrediction(prediction, game); err != nil {
+		lock, err := h.predictionLock(request.Context(), game)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+			return
+		}
+
+		if err := validateGamePrediction(prediction, game, lock); err != nil {
 			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid prediction for game %s: %s", prediction.GameId, err.Error()))
 			return
 		}
@@ -137,28 +200,184 @@ func (h *Handler) CreateBulkPredictions(writer http.ResponseWriter, request *htt
 	h.respondJson(writer, http.StatusCreated, predictions)
 }
 
-// Handle GET /predictions/game?gameId=123
+// Handle GET /predictions/game?gameId=123&limit=50&next=token
+// Until the game locks the caller only gets their own picks back.
 func (h *Handler) GetPredictionsByGame(writer http.ResponseWriter, request *http.Request) {
 	if request.Method != http.MethodGet {
 		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
+	userId, ok := request.Context().Value(middleware.UserSubKey).(string)
+	if !ok || userId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "User ID required in context")
+		return
+	}
+
 	gameId := request.URL.Query().Get("gameId")
 	if gameId == "" {
 		h.respondError(writer, http.StatusBadRequest, "Game id is required")
 		return
 	}
 
+	page, paged, err := parsePageRequest(request)
+	if err != nil {
+		h.respondError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if paged {
+		predictions, next, err := h.db.GetPredictionsByGamePage(request.Context(), gameId, page)
+		if err != nil {
+			h.respondPageError(writer, err, "Failed to get predictions: ")
+			return
+		}
+		predictions, err = h.visiblePredictions(request.Context(), userId, predictions)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+			return
+		}
+		h.respondJson(writer, http.StatusOK, pagedResponse{Items: predictions, Next: next})
+		return
+	}
+
 	predictions, err := h.db.GetPredictionsByGame(request.Context(), gameId)
 	if err != nil {
 		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get predictions: ", err))
 		return
 	}
+	predictions, err = h.visiblePredictions(request.Context(), userId, predictions)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
 	h.respondJson(writer, http.StatusOK, predictions)
 }
 
-func validateGamePrediction(prediction models.Prediction, game *models.Game) error {
+// Handle GET /predictions/model?modelId=123
+// Another user's model picks on games that haven't locked are left out.
+func (h *Handler) GetPredictionsByModel(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userId, ok := request.Context().Value(middleware.UserSubKey).(string)
+	if !ok || userId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "User ID required in context")
+		return
+	}
+
+	modelId := request.URL.Query().Get("modelId")
+	if modelId == "" {
+		h.respondError(writer, http.StatusBadRequest, "Model id is required")
+		return
+	}
+
+	predictions, err := h.db.GetModelPredictions(request.Context(), modelId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get predictions: ", err))
+		return
+	}
+	predictions, err = h.visiblePredictions(request.Context(), userId, predictions)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get games: ", err))
+		return
+	}
+	h.respondJson(writer, http.StatusOK, predictions)
+}
+
+// predictionsRevealed reports whether everyone's picks on a game may be shown, which is once the game
+// has locked. Until then each user only sees their own.
+func (h *Handler) predictionsRevealed(ctx context.Context, game *models.Game) (bool, error) {
+	if game.Status != models.GameStatusUpcoming {
+		return true, nil
+	}
+	lock, err := h.predictionLock(ctx, game)
+	if err != nil {
+		return false, err
+	}
+	return time.Now().After(lock), nil
+}
+
+// visiblePredictions drops other users' picks on games that haven't locked yet, see predictionsRevealed.
+// A pick whose game can't be found is dropped too, rather than revealed.
+func (h *Handler) visiblePredictions(ctx context.Context, userId string, predictions []models.Prediction) ([]models.Prediction, error) {
+	revealed := make(map[string]bool)
+	visible := make([]models.Prediction, 0, len(predictions))
+	for _, prediction := range predictions {
+		if prediction.UserId == userId {
+			visible = append(visible, prediction)
+			continue
+		}
+
+		shown, checked := revealed[prediction.GameId]
+		if !checked {
+			game, err := h.db.GetGame(ctx, prediction.GameId)
+			switch {
+			case errors.Is(err, database.ErrGameNotFound):
+				shown = false
+			case err != nil:
+				return nil, err
+			default:
+				if shown, err = h.predictionsRevealed(ctx, game); err != nil {
+					return nil, err
+				}
+			}
+			revealed[prediction.GameId] = shown
+		}
+
+		if shown {
+			visible = append(visible, prediction)
+		}
+	}
+	return visible, nil
+}
+
+// checkModelOwnership makes sure a prediction attributed to a model is submitted by the model's owner.
+// An empty modelId is the user's own pick and needs no check.
+func (h *Handler) checkModelOwnership(request *http.Request, modelId, userId string) error {
+	if modelId == "" {
+		return nil
+	}
+	_, err := h.db.GetModelById(request.Context(), modelId, userId)
+	return err
+}
+
+func (h *Handler) respondModelOwnershipError(writer http.ResponseWriter, err error) {
+	if errors.Is(err, database.ErrModelNotFound) {
+		h.respondError(writer, http.StatusBadRequest, "Model not found")
+		return
+	}
+	h.respondError(writer, http.StatusInternalServerError, "Failed to look up model")
+}
+
+// predictionLock is when predictions for game close, which is normally its start. A later game of a
+// doubleheader whose start is TBD locks when the game before it starts, and is locked outright once
+// that game is under way. The zero time means the game is already locked.
+func (h *Handler) predictionLock(ctx context.Context, game *models.Game) (time.Time, error) {
+	if !game.StartTimeTBD || game.GameNumber < 2 {
+		return game.Date, nil
+	}
+
+	games, err := h.db.GetGamesByDate(ctx, game.GameDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	opener := findOpener(games, *game)
+	switch {
+	case opener == nil:
+		// The TBD placeholder is early on the game day, so this errs on the side of locking
+		return game.Date, nil
+	case opener.Status != models.GameStatusUpcoming:
+		return time.Time{}, nil
+	default:
+		return opener.Date, nil
+	}
+}
+
+func validateGamePrediction(prediction models.Prediction, game *models.Game, lock time.Time) error {
 	if prediction.PredictedWinnerId != game.HomeTeamId && prediction.PredictedWinnerId != game.AwayTeamId {
 		return fmt.Errorf("predicted winner %s is not a valid team for game %s", prediction.PredictedWinnerId, game.GameId)
 	}
@@ -168,7 +387,7 @@ func validateGamePrediction(prediction models.Prediction, game *models.Game) err
 	if game.Status != "upcoming" {
 		return fmt.Errorf("cannot predict for games that have started: %s", game.GameId)
 	}
-	if time.Now().After(game.Date) {
+	if time.Now().After(lock) {
 		return fmt.Errorf("cannot predict for games that have started: %s", game.GameId)
 	}
 	return nil
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// seedPicks stores an upcoming game and one that has already locked, with picks from u1 and u2 on both
+func seedPicks(t *testing.T, db *database.MemoryDB) {
+	t.Helper()
+	ctx := context.Background()
+	for _, game := range []models.Game{
+		{GameId: "later", Date: time.Now().Add(3 * time.Hour), HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming},
+		// Still marked upcoming, but its first pitch has passed
+		{GameId: "started", Date: time.Now().Add(-time.Hour), HomeTeamId: "121", AwayTeamId: "139", Status: models.GameStatusUpcoming},
+	} {
+		if err := db.CreateGame(ctx, &game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, prediction := range []models.Prediction{
+		{UserId: "u1", GameId: "later", PredictedWinnerId: "111"},
+		{UserId: "u2", GameId: "later", PredictedWinnerId: "147"},
+		{UserId: "u1", GameId: "started", PredictedWinnerId: "121"},
+		{UserId: "u2", GameId: "started", PredictedWinnerId: "139"},
+	} {
+		if err := db.CreatePrediction(ctx, &prediction); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func pickers(predictions []models.Prediction) []string {
+	users := make([]string, 0, len(predictions))
+	for _, prediction := range predictions {
+		users = append(users, prediction.UserId+"/"+prediction.GameId)
+	}
+	slices.Sort(users)
+	return users
+}
+
+func TestGetPredictionsByGameHidesPicksUntilLock(t *testing.T) {
+	h, db := newTestHandler()
+	seedPicks(t, db)
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/predictions/game?gameId=later", []string{"u1/later"}},
+		{"/predictions/game?gameId=started", []string{"u1/started", "u2/started"}},
+	}
+	for _, tt := range tests {
+		recorder := serveAs(h.GetPredictionsByGame, http.MethodGet, tt.target, "u1", nil)
+		got := pickers(decodeResponse[[]models.Prediction](t, recorder, http.StatusOK))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("GET %s = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+
+	recorder := serveAs(h.GetPredictionsByGame, http.MethodGet, "/predictions/game?gameId=later&limit=10", "u2", nil)
+	page := decodeResponse[struct {
+		Items []models.Prediction `json:"items"`
+	}](t, recorder, http.StatusOK)
+	if got := pickers(page.Items); !slices.Equal(got, []string{"u2/later"}) {
+		t.Errorf("paged GET = %v, want only u2's own pick", got)
+	}
+}
+
+func TestGetPredictionsByUserHidesUnlockedPicks(t *testing.T) {
+	h, db := newTestHandler()
+	seedPicks(t, db)
+
+	recorder := serveAs(h.GetPredictionsByUser, http.MethodGet, "/predictions?userId=u2", "u1", nil)
+	if got := pickers(decodeResponse[[]models.Prediction](t, recorder, http.StatusOK)); !slices.Equal(got, []string{"u2/started"}) {
+		t.Errorf("u2's picks as seen by u1 = %v, want only the locked game", got)
+	}
+
+	recorder = serveAs(h.GetPredictionsByUser, http.MethodGet, "/predictions?userId=u2", "u2", nil)
+	if got := pickers(decodeResponse[[]models.Prediction](t, recorder, http.StatusOK)); len(got) != 2 {
+		t.Errorf("u2's own picks = %v, want both", got)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/quota"
+	"github.com/bendemouth/mlb-prediction-pool/internal/services"
+)
+
+// quotaUsage measures the user's model quota against the configured limits
+func (h *Handler) quotaUsage(ctx context.Context, userId string) (models.ModelQuotaUsage, error) {
+	userModels, err := h.db.GetModelsByUserId(ctx, userId)
+	if err != nil {
+		return models.ModelQuotaUsage{}, err
+	}
+	uploads, err := h.db.GetModelUploadCount(ctx, userId, quota.UploadDay(time.Now()))
+	if err != nil {
+		return models.ModelQuotaUsage{}, err
+	}
+	return quota.Usage(userModels, uploads, h.quotaLimits), nil
+}
+
+// respondQuotaError answers an over-quota attempt with 403, or 429 for the upload rate limit,
+// and a machine-readable code. Other errors become a 500.
+func (h *Handler) respondQuotaError(w http.ResponseWriter, err error) {
+	var quotaErr *quota.Error
+	if !errors.As(err, &quotaErr) {
+		h.respondError(w, http.StatusInternalServerError, "Failed to check model quota: "+err.Error())
+		return
+	}
+
+	status := http.StatusForbidden
+	if quotaErr.RateLimited {
+		status = http.StatusTooManyRequests
+	}
+	h.respondJson(w, status, map[string]string{
+		"error": quotaErr.Message,
+		"code":  quotaErr.Code,
+	})
+}
+
+// checkUploadQuota responds and returns false if the user can't upload size more bytes.
+// An upload that is allowed is counted against today straight away, whatever becomes of it.
+// The count only goes up while today is under the limit, so of two uploads racing for the last
+// slot one is turned away even though both passed the check on the usage read beforehand.
+func (h *Handler) checkUploadQuota(w http.ResponseWriter, r *http.Request, userId string, size int64, versions []*models.ModelMetadata) bool {
+	usage, err := h.quotaUsage(r.Context(), userId)
+	if err == nil {
+		err = quota.CheckUpload(usage, size, !services.HasActiveVersion(versions))
+	}
+	if err != nil {
+		h.respondQuotaError(w, err)
+		return false
+	}
+
+	err = h.db.RecordModelUpload(r.Context(), userId, quota.UploadDay(time.Now()), h.quotaLimits.MaxUploadsPerDay)
+	if errors.Is(err, database.ErrUploadLimitReached) {
+		h.respondQuotaError(w, quota.UploadRateError(h.quotaLimits))
+		return false
+	}
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, "Failed to record upload: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// SeasonSummary is a season with the phase it is in today, if any
+type SeasonSummary struct {
+	models.Season
+	CurrentPhase string `json:"current_phase,omitempty"`
+}
+
+// GET /seasons
+func (h *Handler) GetSeasons(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	seasons, err := h.db.ListSeasons(request.Context())
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to list seasons: ", err))
+		return
+	}
+
+	today := time.Now().Format(models.GameDayLayout)
+	summaries := make([]SeasonSummary, 0, len(seasons))
+	for _, season := range seasons {
+		summaries = append(summaries, SeasonSummary{Season: season, CurrentPhase: season.PhaseOn(today)})
+	}
+
+	h.respondJson(writer, http.StatusOK, summaries)
+}
+
+// GET /seasons/{year}
+// GET /seasons/current
+func (h *Handler) GetSeasonByYear(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ctx := request.Context()
+
+	var year int
+	switch path := strings.TrimPrefix(request.URL.Path, "/seasons/"); path {
+	case "current":
+		current, err := h.currentSeason(ctx)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get current season: ", err))
+			return
+		}
+		year = current
+	default:
+		parsed, err := strconv.Atoi(path)
+		if err != nil {
+			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid season %q", path))
+			return
+		}
+		year = parsed
+	}
+
+	season, err := h.db.GetSeason(ctx, year)
+	if err != nil {
+		if errors.Is(err, database.ErrSeasonNotFound) {
+			h.respondError(writer, http.StatusNotFound, "Season not found")
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get season: ", err))
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, SeasonSummary{
+		Season:       *season,
+		CurrentPhase: season.PhaseOn(time.Now().Format(models.GameDayLayout)),
+	})
+}
+
+// currentSeason is the season today falls in, see database.CurrentSeason
+func (h *Handler) currentSeason(ctx context.Context) (int, error) {
+	seasons, err := h.db.ListSeasons(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return database.CurrentSeason(seasons, time.Now().Format(models.GameDayLayout)), nil
+}
+
+// leaderboardScope reads the season query parameter: a year, "all" for all-time standings, or
+// by default the current season. A stored season brings its postseason round weights with it.
+// On failure it writes the error response and returns false.
+func (h *Handler) leaderboardScope(writer http.ResponseWriter, request *http.Request) (database.LeaderboardScope, bool) {
+	ctx := request.Context()
+
+	var year int
+	switch param := request.URL.Query().Get("season"); param {
+	case "all":
+		return database.LeaderboardScope{}, true
+	case "":
+		current, err := h.currentSeason(ctx)
+		if err != nil {
+			h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get current season: ", err))
+			return database.LeaderboardScope{}, false
+		}
+		year = current
+	default:
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed < 1876 {
+			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid season %q, expected a year or all", param))
+			return database.LeaderboardScope{}, false
+		}
+		year = parsed
+	}
+
+	season, err := h.db.GetSeason(ctx, year)
+	if err != nil {
+		if errors.Is(err, database.ErrSeasonNotFound) {
+			return database.LeaderboardScope{Season: year}, true
+		}
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get season: ", err))
+		return database.LeaderboardScope{}, false
+	}
+
+	return database.SeasonScope(season), true
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/stats"
+)
+
+// TeamStatsDates lists the days a stats category has snapshots for
+type TeamStatsDates struct {
+	Category string   `json:"category"`
+	Dates    []string `json:"dates"`
+}
+
+// GET /stats/teams?category=hitting
+// GET /stats/teams?category=hitting&date=2025-06-10&team=147
+// date is point-in-time: the response is the newest snapshot taken on or before it, so features
+// built for a game never include stats from after it was played.
+func (h *Handler) GetTeamStats(writer http.ResponseWriter, request *http.Request) {
+	if !h.teamStatsAvailable(writer, request) {
+		return
+	}
+
+	query := request.URL.Query()
+	category, date, team := query.Get("category"), query.Get("date"), query.Get("team")
+
+	if date != "" {
+		if _, err := time.Parse(models.GameDayLayout, date); err != nil {
+			h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("Invalid date %q, expected YYYY-MM-DD", date))
+			return
+		}
+	}
+
+	snapshot, err := h.teamStats.AsOf(request.Context(), category, date)
+	if err != nil {
+		h.respondTeamStatsError(writer, err)
+		return
+	}
+
+	if team != "" {
+		teamStats, ok := snapshot.Teams[team]
+		if !ok {
+			h.respondError(writer, http.StatusNotFound, fmt.Sprintf("No %s stats for team %q on %s", category, team, snapshot.Date))
+			return
+		}
+		snapshot.Teams = map[string]json.RawMessage{team: teamStats}
+	}
+
+	h.respondJson(writer, http.StatusOK, snapshot)
+}
+
+// GET /stats/teams/dates?category=hitting
+func (h *Handler) GetTeamStatsDates(writer http.ResponseWriter, request *http.Request) {
+	if !h.teamStatsAvailable(writer, request) {
+		return
+	}
+
+	category := request.URL.Query().Get("category")
+
+	dates, err := h.teamStats.Dates(request.Context(), category)
+	if err != nil {
+		h.respondTeamStatsError(writer, err)
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, TeamStatsDates{Category: category, Dates: dates})
+}
+
+// teamStatsAvailable checks the method and that a data bucket is configured, responding if not
+func (h *Handler) teamStatsAvailable(writer http.ResponseWriter, request *http.Request) bool {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return false
+	}
+	if h.teamStats == nil {
+		h.respondError(writer, http.StatusServiceUnavailable, "Team stats are not configured")
+		return false
+	}
+	return true
+}
+
+func (h *Handler) respondTeamStatsError(writer http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, stats.ErrUnknownCategory):
+		h.respondError(writer, http.StatusBadRequest, fmt.Sprintf("%v, expected one of %s", err, strings.Join(stats.Categories, ", ")))
+	case errors.Is(err, stats.ErrNoSnapshot):
+		h.respondError(writer, http.StatusNotFound, err.Error())
+	default:
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get team stats: ", err))
+	}
+}
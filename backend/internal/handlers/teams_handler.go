@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// GameWithTeams is a game with its teams' reference data filled in, for ?expand=teams
+type GameWithTeams struct {
+	models.Game
+	HomeTeamDetails *models.Team `json:"home_team_details,omitempty"`
+	AwayTeamDetails *models.Team `json:"away_team_details,omitempty"`
+}
+
+// GET /teams
+// GET /teams?league=AL&division=AL East
+func (h *Handler) GetTeams(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	teams, err := h.db.ListTeams(request.Context())
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get teams: ", err))
+		return
+	}
+
+	league, division := request.URL.Query().Get("league"), request.URL.Query().Get("division")
+
+	filtered := make([]models.Team, 0, len(teams))
+	for _, team := range teams {
+		if league != "" && !strings.EqualFold(team.League, league) {
+			continue
+		}
+		if division != "" && !strings.EqualFold(team.Division, division) {
+			continue
+		}
+		filtered = append(filtered, team)
+	}
+
+	h.respondJson(writer, http.StatusOK, filtered)
+}
+
+// GET /teams/{teamId}
+func (h *Handler) GetTeamById(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	teamId := strings.TrimPrefix(request.URL.Path, "/teams/")
+	if teamId == "" || strings.Contains(teamId, "/") {
+		h.respondError(writer, http.StatusBadRequest, "Team ID is required")
+		return
+	}
+
+	team, err := h.db.GetTeam(request.Context(), teamId)
+	if err != nil {
+		if errors.Is(err, database.ErrTeamNotFound) {
+			h.respondError(writer, http.StatusNotFound, "Team not found")
+			return
+		}
+		h.respondError(writer, http.StatusInternalServerError, fmt.Sprint("Failed to get team: ", err))
+		return
+	}
+
+	h.respondJson(writer, http.StatusOK, team)
+}
+
+// expandTeams reads the expand query parameter, which only accepts "teams"
+func expandTeams(request *http.Request) (bool, error) {
+	switch expand := request.URL.Query().Get("expand"); expand {
+	case "":
+		return false, nil
+	case "teams":
+		return true, nil
+	default:
+		return false, fmt.Errorf("Unsupported expand %q, expected teams", expand)
+	}
+}
+
+// withTeams attaches team details to each game. Teams missing from the reference data are left out
+// rather than failing the request, so a new club doesn't break the schedule.
+func (h *Handler) withTeams(ctx context.Context, games []models.Game) ([]GameWithTeams, error) {
+	teams, err := h.db.ListTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]*models.Team, len(teams))
+	for i := range teams {
+		byId[teams[i].Id] = &teams[i]
+	}
+
+	expanded := make([]GameWithTeams, 0, len(games))
+	for _, game := range games {
+		expanded = append(expanded, GameWithTeams{
+			Game:            game,
+			HomeTeamDetails: byId[game.HomeTeamId],
+			AwayTeamDetails: byId[game.AwayTeamId],
+		})
+	}
+
+	return expanded, nil
+}
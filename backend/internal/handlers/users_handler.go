@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/mail"
@@ -62,7 +63,8 @@ func (h *Handler) HandleCreateUser(writer http.ResponseWriter, request *http.Req
 	h.respondJson(writer, http.StatusCreated, newUserRequest)
 }
 
-// HandleGetUser retrieves a user by userId
+// HandleGetUser retrieves a user by userId. Callers can only see themselves and the people
+// they share a league with; anyone else is reported as not found.
 // GET /users?user_id=username
 func (h *Handler) HandleGetUser(writer http.ResponseWriter, request *http.Request) {
 	if request.Method != http.MethodGet {
@@ -70,12 +72,28 @@ func (h *Handler) HandleGetUser(writer http.ResponseWriter, request *http.Reques
 		return
 	}
 
+	callerId, ok := request.Context().Value(middleware.UserSubKey).(string)
+	if !ok || callerId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "Unauthorized: invalid user context")
+		return
+	}
+
 	userId := request.URL.Query().Get("user_id")
 	if userId == "" {
 		h.respondError(writer, http.StatusBadRequest, "Missing user_id parameter")
 		return
 	}
 
+	visible, err := h.leagueMates(request.Context(), callerId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get leagues")
+		return
+	}
+	if !visible[userId] {
+		h.respondError(writer, http.StatusNotFound, "User not found")
+		return
+	}
+
 	user, err := h.db.GetUser(request.Context(), userId)
 	if err != nil {
 		if errors.Is(err, database.ErrUserNotFound) {
@@ -88,15 +106,49 @@ func (h *Handler) HandleGetUser(writer http.ResponseWriter, request *http.Reques
 	h.respondJson(writer, http.StatusOK, user)
 }
 
-// HandleListUsers retrieves all users
-// GET /users/listUsers
+// HandleListUsers retrieves the caller and everyone who shares a league with them,
+// or one page of them when limit/next are given
+// GET /users/listUsers?limit=50&next=token
 func (h *Handler) HandleListUsers(writer http.ResponseWriter, request *http.Request) {
 	if request.Method != http.MethodGet {
 		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	users, err := h.db.ListUsers(request.Context())
+	userId, ok := request.Context().Value(middleware.UserSubKey).(string)
+	if !ok || userId == "" {
+		h.respondError(writer, http.StatusUnauthorized, "Unauthorized: invalid user context")
+		return
+	}
+
+	page, paged, err := parsePageRequest(request)
+	if err != nil {
+		h.respondError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	visible, err := h.leagueMates(request.Context(), userId)
+	if err != nil {
+		h.respondError(writer, http.StatusInternalServerError, "Failed to get leagues")
+		return
+	}
+
+	userIds := make([]string, 0, len(visible))
+	for mateId := range visible {
+		userIds = append(userIds, mateId)
+	}
+
+	if paged {
+		users, next, err := database.GetUsersPage(request.Context(), h.db, userIds, page)
+		if err != nil {
+			h.respondPageError(writer, err, "Failed to list users: ")
+			return
+		}
+		h.respondJson(writer, http.StatusOK, pagedResponse{Items: users, Next: next})
+		return
+	}
+
+	users, err := h.db.GetUsersByIds(request.Context(), userIds)
 	if err != nil {
 		h.respondError(writer, http.StatusInternalServerError, "Failed to list users")
 		return
@@ -104,8 +156,28 @@ func (h *Handler) HandleListUsers(writer http.ResponseWriter, request *http.Requ
 	h.respondJson(writer, http.StatusOK, users)
 }
 
+// leagueMates returns the ids of the user and every member of the leagues they belong to
+func (h *Handler) leagueMates(ctx context.Context, userId string) (map[string]bool, error) {
+	leagues, err := h.db.GetUserLeagues(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	mates := map[string]bool{userId: true}
+	for _, league := range leagues {
+		members, err := h.db.GetLeagueMembers(ctx, league.LeagueId)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			mates[member.UserId] = true
+		}
+	}
+	return mates, nil
+}
+
 // HandleGetUserStats retrieves statistics for a specific user
-// GET /users/stats?user_id=username
+// GET /users/stats?user_id=username&season=2025
 func (h *Handler) HandleGetUserStats(writer http.ResponseWriter, request *http.Request) {
 	if request.Method != http.MethodGet {
 		h.respondError(writer, http.StatusMethodNotAllowed, "Method not allowed")
@@ -118,7 +190,12 @@ func (h *Handler) HandleGetUserStats(writer http.ResponseWriter, request *http.R
 		return
 	}
 
-	stats, err := h.db.GetUserStats(request.Context(), userId)
+	scope, ok := h.leaderboardScope(writer, request)
+	if !ok {
+		return
+	}
+
+	stats, err := h.db.GetUserStats(request.Context(), userId, scope)
 	if err != nil {
 		h.respondError(writer, http.StatusInternalServerError, "Failed to get user stats")
 		return
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestHandleListUsersOnlyLeagueMates(t *testing.T) {
+	ctx := context.Background()
+	h, db := newTestHandler()
+
+	for _, id := range []string{"u1", "u2", "u3", "u4"} {
+		if err := db.CreateUser(ctx, &models.User{Id: id, Username: id, Email: id + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// u1 owns a league u2 joined; u3 and u4 share nothing with anyone
+	if err := db.CreateLeague(ctx, &models.League{LeagueId: "l1", Name: "Office", OwnerId: "u1", InviteCode: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddLeagueMember(ctx, &models.LeagueMember{LeagueId: "l1", UserId: "u2", Role: models.LeagueRoleMember}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		userId string
+		want   []string
+	}{
+		{"u1", []string{"u1", "u2"}},
+		{"u2", []string{"u1", "u2"}},
+		{"u3", []string{"u3"}},
+	}
+	for _, tt := range tests {
+		recorder := serveAs(h.HandleListUsers, http.MethodGet, "/users/listUsers", tt.userId, nil)
+		var got []string
+		for _, user := range decodeResponse[[]models.User](t, recorder, http.StatusOK) {
+			got = append(got, user.Id)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("users visible to %s = %v, want %v", tt.userId, got, tt.want)
+		}
+	}
+
+	recorder := serveAs(h.HandleListUsers, http.MethodGet, "/users/listUsers", "", nil)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("status without a user = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestHandleListUsersPages(t *testing.T) {
+	ctx := context.Background()
+	h, db := newTestHandler()
+
+	// Ids that sort between the league's members, so a page read from the whole table would come back short
+	for _, id := range []string{"a1", "a2", "b1", "b2", "c1", "c2", "d1", "d2", "e1"} {
+		if err := db.CreateUser(ctx, &models.User{Id: id, Username: id, Email: id + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.CreateLeague(ctx, &models.League{LeagueId: "l1", Name: "Office", OwnerId: "a1", InviteCode: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"b1", "c1", "d1", "e1"} {
+		if err := db.AddLeagueMember(ctx, &models.LeagueMember{LeagueId: "l1", UserId: id, Role: models.LeagueRoleMember}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var pages [][]string
+	next := ""
+	for {
+		recorder := serveAs(h.HandleListUsers, http.MethodGet, "/users/listUsers?limit=2&next="+next, "a1", nil)
+		page := decodeResponse[struct {
+			Items []models.User `json:"items"`
+			Next  string        `json:"next"`
+		}](t, recorder, http.StatusOK)
+
+		var ids []string
+		for _, user := range page.Items {
+			ids = append(ids, user.Id)
+		}
+		pages = append(pages, ids)
+		if next = page.Next; next == "" || len(pages) > 5 {
+			break
+		}
+	}
+
+	want := [][]string{{"a1", "b1"}, {"c1", "d1"}, {"e1"}}
+	if !slices.EqualFunc(pages, want, slices.Equal[[]string]) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+}
+
+func TestHandleGetUserOnlyLeagueMates(t *testing.T) {
+	ctx := context.Background()
+	h, db := newTestHandler()
+
+	for _, id := range []string{"u1", "u2", "u3"} {
+		if err := db.CreateUser(ctx, &models.User{Id: id, Username: id, Email: id + "@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.CreateLeague(ctx, &models.League{LeagueId: "l1", Name: "Office", OwnerId: "u1", InviteCode: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddLeagueMember(ctx, &models.LeagueMember{LeagueId: "l1", UserId: "u2", Role: models.LeagueRoleMember}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		callerId, userId string
+		status           int
+	}{
+		{"u1", "u1", http.StatusOK},
+		{"u1", "u2", http.StatusOK},
+		// u3 shares no league with u1, so their email stays private
+		{"u1", "u3", http.StatusNotFound},
+		{"u3", "u1", http.StatusNotFound},
+		{"", "u1", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		recorder := serveAs(h.HandleGetUser, http.MethodGet, "/users?user_id="+tt.userId, tt.callerId, nil)
+		if recorder.Code != tt.status {
+			t.Errorf("%q getting %s: status = %d, want %d", tt.callerId, tt.userId, recorder.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package inference
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+// fetchArtifact downloads a model's file from blobs to path unless it is already there.
+// A nil store means artifacts are provisioned some other way and nothing is fetched.
+// Downloads are checked against the SHA-256 recorded at upload before they are used.
+func fetchArtifact(ctx context.Context, blobs storage.BlobStore, path string, model models.ModelMetadata) error {
+	if blobs == nil {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	body, err := blobs.Get(ctx, model.S3Key)
+	if err != nil {
+		return fmt.Errorf("failed to fetch artifact for model %s: %w", model.ModelId, err)
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch artifact for model %s: %w", model.ModelId, err)
+	}
+
+	if model.Sha256 != "" && hex.EncodeToString(hash.Sum(nil)) != model.Sha256 {
+		return fmt.Errorf("artifact for model %s does not match its recorded checksum", model.ModelId)
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,60 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// GameInput is what a model sees for one upcoming game
+type GameInput struct {
+	GameId     string             `json:"game_id"`
+	Date       time.Time          `json:"date"`
+	GameDay    string             `json:"game_day"`
+	HomeTeam   string             `json:"home_team"`
+	HomeTeamId string             `json:"home_id"`
+	AwayTeam   string             `json:"away_team"`
+	AwayTeamId string             `json:"away_id"`
+	Features   map[string]float64 `json:"features,omitempty"`
+}
+
+// GameOutput is a model's prediction for one game
+type GameOutput struct {
+	GameId              string  `json:"game_id"`
+	HomeScorePredicted  float32 `json:"home_score_predicted"`
+	AwayScorePredicted  float32 `json:"away_score_predicted"`
+	TotalScorePredicted float32 `json:"total_score_predicted"`
+	Confidence          float32 `json:"confidence"`
+	PredictedWinnerId   string  `json:"predicted_winner_id"`
+}
+
+// Executor runs a stored model against a slate of games.
+// Implementations decide how the artifact is loaded and where the code runs.
+type Executor interface {
+	Predict(ctx context.Context, model models.ModelMetadata, games []GameInput) ([]GameOutput, error)
+}
+
+// FrameworkExecutors picks an Executor by the model's framework.
+// Models without a framework predate ONNX support and are pickles.
+type FrameworkExecutors map[string]Executor
+
+func (f FrameworkExecutors) Predict(ctx context.Context, model models.ModelMetadata, games []GameInput) ([]GameOutput, error) {
+	framework := model.Framework
+	if framework == "" {
+		framework = models.ModelFrameworkPickle
+	}
+
+	executor, ok := f[framework]
+	if !ok {
+		return nil, fmt.Errorf("no executor configured for %s models", framework)
+	}
+
+	return executor.Predict(ctx, model, games)
+}
+
+// FeatureSource supplies model features for a game, e.g. team stats as of game day
+type FeatureSource interface {
+	GameFeatures(ctx context.Context, game models.Game) (map[string]float64, error)
+}
@@ -0,0 +1,147 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// lastGames is how many recent games the last10 features cover
+const lastGames = 10
+
+// ResultFeatures computes the feature schema from the results already in the store: each team's
+// win percentage, runs scored and allowed per game, and last-ten win percentage in regular season
+// games that finished before game day. Until a team has finished a game this season, its previous
+// season is used. A team with no results in either season gets no features, so models that need
+// them fail rather than predicting from made-up numbers.
+type ResultFeatures struct {
+	games database.GameStore
+	// records caches each team's record by team and game day, since a slate repeats both
+	records map[string]*teamRecord
+}
+
+func NewResultFeatures(games database.GameStore) *ResultFeatures {
+	return &ResultFeatures{games: games, records: make(map[string]*teamRecord)}
+}
+
+// teamRecord is a team's results going into a game day, oldest first
+type teamRecord struct {
+	wins        []bool
+	runsScored  int
+	runsAllowed int
+}
+
+func (f *ResultFeatures) GameFeatures(ctx context.Context, game models.Game) (map[string]float64, error) {
+	features := make(map[string]float64)
+
+	for _, side := range []struct{ prefix, teamId string }{
+		{"home", game.HomeTeamId},
+		{"away", game.AwayTeamId},
+	} {
+		record, err := f.record(ctx, side.teamId, game)
+		if err != nil {
+			return nil, err
+		}
+		if record == nil {
+			continue
+		}
+
+		played := float64(len(record.wins))
+		recent := record.wins[max(len(record.wins)-lastGames, 0):]
+
+		features[side.prefix+"_win_pct"] = winPct(record.wins)
+		features[side.prefix+"_runs_scored_per_game"] = float64(record.runsScored) / played
+		features[side.prefix+"_runs_allowed_per_game"] = float64(record.runsAllowed) / played
+		features[side.prefix+"_last10_win_pct"] = winPct(recent)
+	}
+
+	return features, nil
+}
+
+// record returns teamId's record going into game, or nil when it has no results to go on
+func (f *ResultFeatures) record(ctx context.Context, teamId string, game models.Game) (*teamRecord, error) {
+	key := teamId + "/" + game.GameDay
+	if record, ok := f.records[key]; ok {
+		return record, nil
+	}
+
+	day, err := time.Parse(models.GameDayLayout, game.GameDay)
+	if err != nil {
+		return nil, fmt.Errorf("game %s has an invalid game day %q", game.GameId, game.GameDay)
+	}
+	season := game.Season
+	if season == 0 {
+		season = day.Year()
+	}
+
+	dayBefore := day.AddDate(0, 0, -1).Format(models.GameDayLayout)
+	record, err := f.seasonRecord(ctx, teamId, seasonStart(season), dayBefore)
+	if err == nil && record == nil {
+		record, err = f.seasonRecord(ctx, teamId, seasonStart(season-1), seasonEnd(season-1))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f.records[key] = record
+	return record, nil
+}
+
+// seasonRecord tallies teamId's finished regular season games between from and to, or nil if there are none
+func (f *ResultFeatures) seasonRecord(ctx context.Context, teamId, from, to string) (*teamRecord, error) {
+	if to < from {
+		return nil, nil
+	}
+
+	games, err := f.games.GetGamesByTeam(ctx, teamId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results for team %s: %w", teamId, err)
+	}
+	sort.SliceStable(games, func(i, j int) bool { return games[i].Date.Before(games[j].Date) })
+
+	record := &teamRecord{}
+	for _, game := range games {
+		if game.Status != models.GameStatusFinal && game.Status != models.GameStatusCompleted {
+			continue
+		}
+		if game.GameType != "" && game.GameType != models.GameTypeRegular {
+			continue
+		}
+
+		scored, allowed := game.HomeScore, game.AwayScore
+		if game.AwayTeamId == teamId {
+			scored, allowed = allowed, scored
+		}
+		record.wins = append(record.wins, scored > allowed)
+		record.runsScored += scored
+		record.runsAllowed += allowed
+	}
+
+	if len(record.wins) == 0 {
+		return nil, nil
+	}
+	return record, nil
+}
+
+func winPct(wins []bool) float64 {
+	won := 0
+	for _, win := range wins {
+		if win {
+			won++
+		}
+	}
+	return float64(won) / float64(len(wins))
+}
+
+func seasonStart(season int) string {
+	return strconv.Itoa(season) + "-01-01"
+}
+
+func seasonEnd(season int) string {
+	return strconv.Itoa(season) + "-12-31"
+}
@@ -0,0 +1,140 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/onnx"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+// ONNXExecutor runs ONNX models in a child process that only ever sees tensors.
+//
+// Feature rows are built here from onnx.FeatureNames, so the process receives
+//
+//	{"model_path": "/abs/path/to/model.onnx", "input_name": "...", "features": [[...], ...]}
+//
+// and must write {"outputs": [[home, away, home_win_probability], ...]} to stdout, one row per
+// input row. The process runs with an empty environment apart from PATH, so it can't pick up
+// AWS credentials or other secrets from the API's environment.
+type ONNXExecutor struct {
+	Command     []string
+	ArtifactDir string
+	// Blobs, when set, is where artifacts missing from ArtifactDir are downloaded from
+	Blobs storage.BlobStore
+}
+
+// NewONNXExecutor creates an executor that runs command with artifacts resolved
+// under artifactDir by each model's S3 key
+func NewONNXExecutor(command []string, artifactDir string) *ONNXExecutor {
+	return &ONNXExecutor{Command: command, ArtifactDir: artifactDir}
+}
+
+type onnxRequest struct {
+	ModelPath string      `json:"model_path"`
+	InputName string      `json:"input_name"`
+	Features  [][]float64 `json:"features"`
+}
+
+type onnxResponse struct {
+	Outputs [][]float64 `json:"outputs"`
+}
+
+func (e *ONNXExecutor) Predict(ctx context.Context, model models.ModelMetadata, games []GameInput) ([]GameOutput, error) {
+	if len(e.Command) == 0 {
+		return nil, fmt.Errorf("no ONNX inference command configured")
+	}
+	if model.Signature == nil {
+		return nil, fmt.Errorf("model %s has no ONNX signature", model.ModelId)
+	}
+	if err := onnx.ValidateSignature(model.Signature); err != nil {
+		return nil, fmt.Errorf("model %s no longer matches the feature schema: %w", model.ModelId, err)
+	}
+
+	modelPath, err := artifactPath(e.ArtifactDir, model)
+	if err != nil {
+		return nil, err
+	}
+	if err := fetchArtifact(ctx, e.Blobs, modelPath, model); err != nil {
+		return nil, err
+	}
+
+	games, features := featureMatrix(model, games, onnx.FeatureNames)
+	if len(games) == 0 {
+		return nil, nil
+	}
+
+	request := onnxRequest{
+		ModelPath: modelPath,
+		InputName: model.Signature.Inputs[0].Name,
+		Features:  features,
+	}
+
+	var response onnxResponse
+	if err := runProcess(ctx, e.Command, request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Outputs) != len(games) {
+		return nil, fmt.Errorf("model returned %d rows for %d games", len(response.Outputs), len(games))
+	}
+
+	outputs := make([]GameOutput, 0, len(games))
+	for i, row := range response.Outputs {
+		if len(row) < 3 {
+			return nil, fmt.Errorf("output row %d has %d columns, expected 3", i, len(row))
+		}
+		outputs = append(outputs, gameOutput(games[i], row))
+	}
+
+	return outputs, nil
+}
+
+// featureMatrix lays games out as rows with one column per name, returning the games it kept
+// alongside their rows. A game missing any feature is left out rather than given a stand-in
+// value, which would only get a confident-looking wrong answer; the runner counts it as skipped.
+func featureMatrix(model models.ModelMetadata, games []GameInput, names []string) ([]GameInput, [][]float64) {
+	kept := make([]GameInput, 0, len(games))
+	matrix := make([][]float64, 0, len(games))
+	for _, game := range games {
+		row := make([]float64, len(names))
+		missing := ""
+		for j, name := range names {
+			value, ok := game.Features[name]
+			if !ok {
+				missing = name
+				break
+			}
+			row[j] = value
+		}
+		if missing != "" {
+			log.Printf("Leaving game %s out of model %s's run: missing feature %s", game.GameId, model.ModelId, missing)
+			continue
+		}
+		kept = append(kept, game)
+		matrix = append(matrix, row)
+	}
+	return kept, matrix
+}
+
+func gameOutput(game GameInput, row []float64) GameOutput {
+	home := float32(row[onnx.OutputHomeScore])
+	away := float32(row[onnx.OutputAwayScore])
+	homeWin := min(max(row[onnx.OutputHomeWinProbability], 0), 1)
+
+	output := GameOutput{
+		GameId:              game.GameId,
+		HomeScorePredicted:  home,
+		AwayScorePredicted:  away,
+		TotalScorePredicted: home + away,
+		PredictedWinnerId:   game.HomeTeamId,
+		Confidence:          float32(homeWin),
+	}
+	if homeWin < 0.5 {
+		output.PredictedWinnerId = game.AwayTeamId
+		output.Confidence = float32(1 - homeWin)
+	}
+
+	return output
+}
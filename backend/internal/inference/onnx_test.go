@@ -0,0 +1,94 @@
+package inference
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/onnx"
+)
+
+func schemaSignature() *models.ModelSignature {
+	return &models.ModelSignature{
+		IrVersion: 8,
+		Inputs:    []models.TensorSpec{{Name: "features", ElemType: "float", Shape: []int64{-1, int64(len(onnx.FeatureNames))}}},
+		Outputs:   []models.TensorSpec{{Name: "scores", ElemType: "float", Shape: []int64{-1, 3}}},
+	}
+}
+
+func fullFeatures(value float64) map[string]float64 {
+	features := make(map[string]float64, len(onnx.FeatureNames))
+	for _, name := range onnx.FeatureNames {
+		features[name] = value
+	}
+	return features
+}
+
+func TestFeatureMatrix(t *testing.T) {
+	partial := fullFeatures(9)
+	delete(partial, "away_last10_win_pct")
+
+	games, matrix := featureMatrix(models.ModelMetadata{ModelId: "m1"}, []GameInput{
+		{GameId: "g1", Features: fullFeatures(0.25)},
+		{GameId: "g2", Features: partial},
+		{GameId: "g3", Features: fullFeatures(4)},
+	}, onnx.FeatureNames)
+	if len(games) != 2 || games[0].GameId != "g1" || games[1].GameId != "g3" {
+		t.Fatalf("featureMatrix() kept %+v, want g1 and g3", games)
+	}
+	if len(matrix) != 2 || len(matrix[0]) != len(onnx.FeatureNames) || matrix[0][0] != 0.25 || matrix[1][len(onnx.FeatureNames)-1] != 4 {
+		t.Errorf("featureMatrix() = %v", matrix)
+	}
+}
+
+func TestONNXExecutorLeavesOutGamesMissingFeatures(t *testing.T) {
+	features := fullFeatures(0.5)
+	delete(features, "away_last10_win_pct")
+
+	// One output row, so the run only succeeds if g2 wasn't sent
+	executor := NewONNXExecutor([]string{"sh", "-c", `cat > /dev/null; echo '{"outputs": [[5.5, 3.0, 0.7]]}'`}, t.TempDir())
+	model := models.ModelMetadata{ModelId: "m1", S3Key: "models/u1/m1.onnx", Framework: models.ModelFrameworkONNX, Signature: schemaSignature()}
+
+	outputs, err := executor.Predict(context.Background(), model, []GameInput{
+		{GameId: "g1", HomeTeamId: "111", AwayTeamId: "147", Features: fullFeatures(0.5)},
+		{GameId: "g2", HomeTeamId: "121", AwayTeamId: "147", Features: features},
+	})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+	if len(outputs) != 1 || outputs[0].GameId != "g1" {
+		t.Errorf("Predict() = %+v, want only g1", outputs)
+	}
+
+	// Nothing is left to predict, so the command, which would fail, never runs
+	executor.Command = []string{"false"}
+	outputs, err = executor.Predict(context.Background(), model, []GameInput{{GameId: "g2", Features: features}})
+	if err != nil || len(outputs) != 0 {
+		t.Errorf("Predict() with every game missing a feature = %+v, %v, want nothing", outputs, err)
+	}
+}
+
+func TestONNXExecutorOutputs(t *testing.T) {
+	// Home is favoured in the first game and the away team in the second
+	response := `{"outputs": [[5.5, 3.0, 0.7], [2.0, 4.0, 0.2]]}`
+	executor := NewONNXExecutor([]string{"sh", "-c", "cat > /dev/null; echo '" + response + "'"}, t.TempDir())
+	model := models.ModelMetadata{ModelId: "m1", S3Key: "models/u1/m1.onnx", Framework: models.ModelFrameworkONNX, Signature: schemaSignature()}
+
+	outputs, err := executor.Predict(context.Background(), model, []GameInput{
+		{GameId: "g1", HomeTeamId: "111", AwayTeamId: "147", Features: fullFeatures(0.5)},
+		{GameId: "g2", HomeTeamId: "121", AwayTeamId: "147", Features: fullFeatures(0.5)},
+	})
+	if err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	if len(outputs) != 2 {
+		t.Fatalf("Predict() = %+v, want 2 outputs", outputs)
+	}
+	if outputs[0].PredictedWinnerId != "111" || outputs[0].TotalScorePredicted != 8.5 || outputs[0].Confidence != float32(0.7) {
+		t.Errorf("g1 = %+v, want BOS at 0.7", outputs[0])
+	}
+	if outputs[1].PredictedWinnerId != "147" || outputs[1].Confidence != float32(0.8) {
+		t.Errorf("g2 = %+v, want NYY at 0.8", outputs[1])
+	}
+}
@@ -0,0 +1,189 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// RunResult summarises one model's run
+type RunResult struct {
+	ModelId   string `json:"model_id"`
+	UserId    string `json:"user_id"`
+	Submitted int    `json:"submitted"`
+	// Skipped counts games left without a prediction: ones missing a feature the model needs,
+	// ones the model didn't answer, and invalid outputs
+	Skipped int    `json:"skipped"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Runner produces predictions for upcoming games from every runnable model
+type Runner struct {
+	store    database.Store
+	executor Executor
+	features FeatureSource
+	now      func() time.Time
+}
+
+// NewRunner creates a Runner. features may be nil, in which case models only see the schedule.
+func NewRunner(store database.Store, executor Executor, features FeatureSource) *Runner {
+	return &Runner{
+		store:    store,
+		executor: executor,
+		features: features,
+		now:      time.Now,
+	}
+}
+
+// runnableStatuses are the model statuses that get run: only the promoted version of each family.
+// Other validated versions keep their past predictions and stats but stop predicting.
+var runnableStatuses = []string{models.ModelStatusActive}
+
+// RunAll runs every active model against the games that haven't started yet.
+// A failing model is recorded in its RunResult and doesn't stop the others.
+func (r *Runner) RunAll(ctx context.Context) ([]RunResult, error) {
+	var activeModels []*models.ModelMetadata
+	for _, status := range runnableStatuses {
+		statusModels, err := r.store.GetModelsByStatus(ctx, status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s models: %w", status, err)
+		}
+		activeModels = append(activeModels, statusModels...)
+	}
+
+	games, inputs, err := r.gatherGames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RunResult, 0, len(activeModels))
+	for _, model := range activeModels {
+		result := r.runModel(ctx, *model, games, inputs)
+		if result.Error != "" {
+			log.Printf("Model %s failed: %s", model.ModelId, result.Error)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (r *Runner) gatherGames(ctx context.Context) (map[string]models.Game, []GameInput, error) {
+	upcoming, err := r.store.GetUpcomingGames(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get upcoming games: %w", err)
+	}
+
+	now := r.now()
+	games := make(map[string]models.Game, len(upcoming))
+	inputs := make([]GameInput, 0, len(upcoming))
+
+	for _, game := range upcoming {
+		if !game.Date.After(now) {
+			continue
+		}
+
+		input := GameInput{
+			GameId:     game.GameId,
+			Date:       game.Date,
+			GameDay:    game.GameDay,
+			HomeTeam:   game.HomeTeam,
+			HomeTeamId: game.HomeTeamId,
+			AwayTeam:   game.AwayTeam,
+			AwayTeamId: game.AwayTeamId,
+		}
+
+		if r.features != nil {
+			features, err := r.features.GameFeatures(ctx, game)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get features for game %s: %w", game.GameId, err)
+			}
+			input.Features = features
+		}
+
+		games[game.GameId] = game
+		inputs = append(inputs, input)
+	}
+
+	return games, inputs, nil
+}
+
+func (r *Runner) runModel(ctx context.Context, model models.ModelMetadata, games map[string]models.Game, inputs []GameInput) RunResult {
+	result := RunResult{ModelId: model.ModelId, UserId: model.UserId}
+	// Models made active before uploads were inspected have no checksum, and aren't run until
+	// the reconcile job has inspected them
+	if model.Sha256 == "" {
+		result.Error = "model has not been inspected"
+		return result
+	}
+	if len(inputs) == 0 {
+		return result
+	}
+
+	outputs, err := r.executor.Predict(ctx, model, inputs)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	predictions := make([]models.Prediction, 0, len(outputs))
+	seen := make(map[string]bool, len(outputs))
+	answered := make(map[string]bool, len(outputs))
+	for _, output := range outputs {
+		answered[output.GameId] = true
+		prediction, err := toPrediction(model, games, output)
+		if err == nil && seen[output.GameId] {
+			err = fmt.Errorf("duplicate prediction for game %s", output.GameId)
+		}
+		if err != nil {
+			log.Printf("Skipping output from model %s: %v", model.ModelId, err)
+			result.Skipped++
+			continue
+		}
+		seen[output.GameId] = true
+		predictions = append(predictions, prediction)
+	}
+
+	for _, input := range inputs {
+		if !answered[input.GameId] {
+			result.Skipped++
+		}
+	}
+
+	if err := r.store.BatchCreatePredictions(ctx, predictions); err != nil {
+		result.Error = fmt.Sprintf("failed to submit predictions: %v", err)
+		return result
+	}
+
+	result.Submitted = len(predictions)
+	return result
+}
+
+// toPrediction checks a model output against the game it claims to be for
+func toPrediction(model models.ModelMetadata, games map[string]models.Game, output GameOutput) (models.Prediction, error) {
+	game, ok := games[output.GameId]
+	if !ok {
+		return models.Prediction{}, fmt.Errorf("game %s is not on the slate", output.GameId)
+	}
+	if output.PredictedWinnerId != game.HomeTeamId && output.PredictedWinnerId != game.AwayTeamId {
+		return models.Prediction{}, fmt.Errorf("predicted winner %s is not a valid team for game %s", output.PredictedWinnerId, game.GameId)
+	}
+	if output.HomeScorePredicted < 0 || output.AwayScorePredicted < 0 || output.TotalScorePredicted < 0 {
+		return models.Prediction{}, fmt.Errorf("predicted scores must be non-negative for game %s", game.GameId)
+	}
+
+	return models.Prediction{
+		UserId:              model.UserId,
+		GameId:              game.GameId,
+		ModelId:             model.ModelId,
+		HomeScorePredicted:  output.HomeScorePredicted,
+		AwayScorePredicted:  output.AwayScorePredicted,
+		TotalScorePredicted: output.TotalScorePredicted,
+		Confidence:          output.Confidence,
+		PredictedWinnerId:   output.PredictedWinnerId,
+	}, nil
+}
@@ -0,0 +1,281 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/onnx"
+)
+
+// testSha256 marks a model as inspected; the runner doesn't check it against the file
+const testSha256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+// seedGames stores a few results for BOS (111), NYY (147) and NYM (121), and an upcoming slate on 2025-06-10
+func seedGames(t *testing.T, db *database.MemoryDB) {
+	t.Helper()
+	at := func(day string, hour int) time.Time {
+		date, err := time.Parse(models.GameDayLayout, day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return date.Add(time.Duration(hour) * time.Hour)
+	}
+
+	games := []models.Game{
+		// BOS 1-1, NYY 2-1 this season. The exhibition doesn't count.
+		{GameId: "r1", Date: at("2025-06-01", 17), HomeTeamId: "111", AwayTeamId: "147", HomeScore: 5, AwayScore: 3, Status: models.GameStatusCompleted},
+		{GameId: "r2", Date: at("2025-06-02", 17), HomeTeamId: "147", AwayTeamId: "111", HomeScore: 4, AwayScore: 2, Status: models.GameStatusCompleted},
+		{GameId: "r3", Date: at("2025-06-05", 17), HomeTeamId: "147", AwayTeamId: "139", HomeScore: 7, AwayScore: 1, Status: models.GameStatusFinal},
+		{GameId: "r4", Date: at("2025-06-06", 17), HomeTeamId: "111", AwayTeamId: "121", HomeScore: 9, AwayScore: 0, Status: models.GameStatusCompleted, GameType: models.GameTypeExhibition},
+		// NYM hasn't played yet this season, so last season's game is used
+		{GameId: "r5", Date: at("2024-09-01", 17), HomeTeamId: "121", AwayTeamId: "139", HomeScore: 6, AwayScore: 2, Status: models.GameStatusCompleted},
+
+		{GameId: "started", Date: at("2025-06-10", 10), HomeTeamId: "139", AwayTeamId: "111", Status: models.GameStatusUpcoming},
+		{GameId: "g1", Date: at("2025-06-10", 19), HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusUpcoming},
+		{GameId: "g2", Date: at("2025-06-10", 20), HomeTeamId: "121", AwayTeamId: "147", Status: models.GameStatusUpcoming},
+	}
+	for i := range games {
+		if err := db.CreateGame(context.Background(), &games[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunnerSubprocessExecutor(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+	seedGames(t, db)
+
+	model := &models.ModelMetadata{
+		ModelId:   "m1",
+		UserId:    "u1",
+		S3Key:     "models/u1/m1.pkl",
+		Status:    models.ModelStatusActive,
+		Framework: models.ModelFrameworkPickle,
+		Sha256:    testSha256,
+	}
+	if err := db.CreateModel(ctx, model); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	requestPath := filepath.Join(dir, "request.json")
+	responsePath := filepath.Join(dir, "response.json")
+	// g2 names a team that isn't playing, so it is skipped
+	response := `{"predictions": [
+		{"game_id": "g1", "home_score_predicted": 5, "away_score_predicted": 3, "total_score_predicted": 8, "confidence": 0.6, "predicted_winner_id": "111"},
+		{"game_id": "g2", "home_score_predicted": 4, "away_score_predicted": 2, "total_score_predicted": 6, "confidence": 0.7, "predicted_winner_id": "111"}
+	]}`
+	if err := os.WriteFile(responsePath, []byte(response), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := NewSubprocessExecutor([]string{"sh", "testdata/stub_model.sh", requestPath, responsePath}, dir)
+	runner := NewRunner(db, FrameworkExecutors{models.ModelFrameworkPickle: executor}, NewResultFeatures(db))
+	runner.now = func() time.Time { return time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC) }
+
+	results, err := runner.RunAll(ctx)
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Submitted != 1 || results[0].Skipped != 1 || results[0].Error != "" {
+		t.Fatalf("RunAll() = %+v, want one model with 1 submitted and 1 skipped", results)
+	}
+
+	prediction, err := db.GetPredictionByUser(ctx, "u1", "g1", "m1")
+	if err != nil || prediction == nil {
+		t.Fatalf("GetPredictionByUser() = %v, %v, want the model's prediction for g1", prediction, err)
+	}
+	if prediction.PredictedWinnerId != "111" || prediction.HomeScorePredicted != 5 {
+		t.Errorf("prediction = %+v, want BOS to win 5-3", prediction)
+	}
+
+	raw, err := os.ReadFile(requestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request subprocessRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		t.Fatalf("failed to decode the request the process was sent: %v", err)
+	}
+	if request.ModelPath != filepath.Join(dir, "models", "u1", "m1.pkl") {
+		t.Errorf("model_path = %q", request.ModelPath)
+	}
+	if len(request.Games) != 2 || request.Games[0].GameId != "g1" || request.Games[1].GameId != "g2" {
+		t.Fatalf("games = %+v, want g1 and g2 only", request.Games)
+	}
+
+	want := map[string]map[string]float64{
+		"g1": {
+			"home_win_pct": 0.5, "home_runs_scored_per_game": 3.5, "home_runs_allowed_per_game": 3.5, "home_last10_win_pct": 0.5,
+			"away_win_pct": 2.0 / 3, "away_runs_scored_per_game": 14.0 / 3, "away_runs_allowed_per_game": 8.0 / 3, "away_last10_win_pct": 2.0 / 3,
+		},
+		"g2": {
+			"home_win_pct": 1, "home_runs_scored_per_game": 6, "home_runs_allowed_per_game": 2, "home_last10_win_pct": 1,
+			"away_win_pct": 2.0 / 3, "away_runs_scored_per_game": 14.0 / 3, "away_runs_allowed_per_game": 8.0 / 3, "away_last10_win_pct": 2.0 / 3,
+		},
+	}
+	// The model declared no features, so it gets the whole schema as rows too
+	if !slices.Equal(request.FeatureNames, onnx.FeatureNames) || len(request.Features) != 2 {
+		t.Fatalf("feature_names = %v with %d rows, want the schema for both games", request.FeatureNames, len(request.Features))
+	}
+	for i, game := range request.Games {
+		for j, name := range request.FeatureNames {
+			if request.Features[i][j] != game.Features[name] {
+				t.Errorf("%s row column %d = %v, want %s = %v", game.GameId, j, request.Features[i][j], name, game.Features[name])
+			}
+		}
+	}
+
+	for _, game := range request.Games {
+		if len(game.Features) != len(want[game.GameId]) {
+			t.Errorf("%s features = %v, want %v", game.GameId, game.Features, want[game.GameId])
+			continue
+		}
+		for name, value := range want[game.GameId] {
+			if math.Abs(game.Features[name]-value) > 1e-9 {
+				t.Errorf("%s %s = %v, want %v", game.GameId, name, game.Features[name], value)
+			}
+		}
+	}
+}
+
+func TestRunnerRecordsFailingModel(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+	seedGames(t, db)
+
+	if err := db.CreateModel(ctx, &models.ModelMetadata{ModelId: "m1", UserId: "u1", S3Key: "models/u1/m1.pkl", Status: models.ModelStatusActive, Sha256: testSha256}); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := NewSubprocessExecutor([]string{"sh", "-c", "echo model exploded >&2; exit 3"}, t.TempDir())
+	runner := NewRunner(db, FrameworkExecutors{models.ModelFrameworkPickle: executor}, NewResultFeatures(db))
+	runner.now = func() time.Time { return time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC) }
+
+	results, err := runner.RunAll(ctx)
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Submitted != 0 || results[0].Error == "" {
+		t.Fatalf("RunAll() = %+v, want the model's failure recorded", results)
+	}
+
+	predictions, err := db.GetModelPredictions(ctx, "m1")
+	if err != nil || len(predictions) != 0 {
+		t.Errorf("GetModelPredictions() = %v, %v, want none", predictions, err)
+	}
+}
+
+func TestRunnerRefusesUninspectedModel(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+	seedGames(t, db)
+
+	// Made active by the old upload handler, which never inspected the file
+	if err := db.CreateModel(ctx, &models.ModelMetadata{ModelId: "m1", UserId: "u1", S3Key: "models/u1/m1.pkl", Status: models.ModelStatusActive}); err != nil {
+		t.Fatal(err)
+	}
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	executor := NewSubprocessExecutor([]string{"sh", "-c", "touch " + marker}, t.TempDir())
+	runner := NewRunner(db, FrameworkExecutors{models.ModelFrameworkPickle: executor}, NewResultFeatures(db))
+	runner.now = func() time.Time { return time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC) }
+
+	results, err := runner.RunAll(ctx)
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Submitted != 0 || results[0].Error == "" {
+		t.Fatalf("RunAll() = %+v, want the uninspected model refused", results)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the uninspected model's process was started")
+	}
+}
+
+// partialFeatures leaves one feature out for the games in missing
+type partialFeatures struct {
+	FeatureSource
+	missing map[string]bool
+}
+
+func (p partialFeatures) GameFeatures(ctx context.Context, game models.Game) (map[string]float64, error) {
+	features, err := p.FeatureSource.GameFeatures(ctx, game)
+	if p.missing[game.GameId] {
+		delete(features, "away_last10_win_pct")
+	}
+	return features, err
+}
+
+func TestRunnerSkipsGamesMissingFeatures(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+	seedGames(t, db)
+
+	if err := db.CreateModel(ctx, &models.ModelMetadata{ModelId: "m1", UserId: "u1", S3Key: "models/u1/m1.pkl", Status: models.ModelStatusActive, Sha256: testSha256}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	requestPath := filepath.Join(dir, "request.json")
+	responsePath := filepath.Join(dir, "response.json")
+	response := `{"predictions": [
+		{"game_id": "g1", "home_score_predicted": 5, "away_score_predicted": 3, "total_score_predicted": 8, "confidence": 0.6, "predicted_winner_id": "111"}
+	]}`
+	if err := os.WriteFile(responsePath, []byte(response), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	executor := NewSubprocessExecutor([]string{"sh", "testdata/stub_model.sh", requestPath, responsePath}, dir)
+	features := partialFeatures{FeatureSource: NewResultFeatures(db), missing: map[string]bool{"g2": true}}
+	runner := NewRunner(db, FrameworkExecutors{models.ModelFrameworkPickle: executor}, features)
+	runner.now = func() time.Time { return time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC) }
+
+	results, err := runner.RunAll(ctx)
+	if err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Submitted != 1 || results[0].Skipped != 1 || results[0].Error != "" {
+		t.Fatalf("RunAll() = %+v, want g1 submitted and g2 skipped", results)
+	}
+
+	raw, err := os.ReadFile(requestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request subprocessRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		t.Fatalf("failed to decode the request the process was sent: %v", err)
+	}
+	if len(request.Games) != 1 || request.Games[0].GameId != "g1" || len(request.Features) != 1 {
+		t.Errorf("request games = %+v with %d rows, want only g1", request.Games, len(request.Features))
+	}
+}
+
+func TestResultFeaturesWithoutHistory(t *testing.T) {
+	db := database.NewMemoryDB()
+	seedGames(t, db)
+
+	// TB (139) has results but the expansion team (999) has none in either season
+	features, err := NewResultFeatures(db).GameFeatures(context.Background(), models.Game{
+		GameId: "g3", GameDay: "2025-06-10", HomeTeamId: "999", AwayTeamId: "139",
+	})
+	if err != nil {
+		t.Fatalf("GameFeatures() error = %v", err)
+	}
+	if _, ok := features["home_win_pct"]; ok {
+		t.Errorf("features = %v, want no home features", features)
+	}
+	if features["away_win_pct"] != 0 || features["away_runs_allowed_per_game"] != 7 {
+		t.Errorf("features = %v, want TB at 0-1 allowing 7 a game", features)
+	}
+}
@@ -0,0 +1,131 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/onnx"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+// SubprocessExecutor runs each model in a child process.
+//
+// The process receives a JSON object on stdin:
+//
+//	{"model": {...ModelMetadata}, "model_path": "/abs/path/to/artifact", "games": [...GameInput],
+//	 "feature_names": [...], "features": [[...], ...]}
+//
+// where features has one row per game and one column per feature name: the model's declared
+// features, or the whole schema when it declared none. Games missing one of them aren't sent. It must write {"predictions": [...GameOutput]}
+// to stdout. Anything on stderr is included in the error if the process exits non-zero.
+// Pickles can run arbitrary code when loaded, so the process only inherits PATH.
+type SubprocessExecutor struct {
+	Command     []string
+	ArtifactDir string
+	// Blobs, when set, is where artifacts missing from ArtifactDir are downloaded from
+	Blobs storage.BlobStore
+}
+
+// NewSubprocessExecutor creates an executor that runs command with artifacts resolved
+// under artifactDir by each model's S3 key
+func NewSubprocessExecutor(command []string, artifactDir string) *SubprocessExecutor {
+	return &SubprocessExecutor{Command: command, ArtifactDir: artifactDir}
+}
+
+type subprocessRequest struct {
+	Model        models.ModelMetadata `json:"model"`
+	ModelPath    string               `json:"model_path"`
+	Games        []GameInput          `json:"games"`
+	FeatureNames []string             `json:"feature_names"`
+	Features     [][]float64          `json:"features"`
+}
+
+type subprocessResponse struct {
+	Predictions []GameOutput `json:"predictions"`
+}
+
+func (e *SubprocessExecutor) Predict(ctx context.Context, model models.ModelMetadata, games []GameInput) ([]GameOutput, error) {
+	if len(e.Command) == 0 {
+		return nil, fmt.Errorf("no inference command configured")
+	}
+
+	featureNames := model.Features
+	if len(featureNames) == 0 {
+		featureNames = onnx.FeatureNames
+	}
+	games, features := featureMatrix(model, games, featureNames)
+	if len(games) == 0 {
+		return nil, nil
+	}
+
+	modelPath, err := artifactPath(e.ArtifactDir, model)
+	if err != nil {
+		return nil, err
+	}
+	if err := fetchArtifact(ctx, e.Blobs, modelPath, model); err != nil {
+		return nil, err
+	}
+
+	var response subprocessResponse
+	request := subprocessRequest{
+		Model:        model,
+		ModelPath:    modelPath,
+		Games:        games,
+		FeatureNames: featureNames,
+		Features:     features,
+	}
+	if err := runProcess(ctx, e.Command, request, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Predictions, nil
+}
+
+// runProcess writes request as JSON to the command's stdin and decodes its stdout into response.
+// The command runs user-supplied models, so it only gets PATH from the environment, never the
+// credentials and table names the API and jobs run with.
+func runProcess(ctx context.Context, command []string, request interface{}, response interface{}) error {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode inference request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH")}
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("inference process failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return fmt.Errorf("failed to decode inference output: %w", err)
+	}
+
+	return nil
+}
+
+// artifactPath maps the model's S3 key into artifactDir, refusing keys that escape it
+func artifactPath(artifactDir string, model models.ModelMetadata) (string, error) {
+	root, err := filepath.Abs(artifactDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve artifact directory: %w", err)
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(model.S3Key))
+	if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("model %s has an invalid artifact key", model.ModelId)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,41 @@
+package inference
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestSubprocessExecutorScrubsEnvironment(t *testing.T) {
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "do-not-leak")
+	t.Setenv("DYNAMODB_PREDICTIONS_TABLE", "mlb-prediction-pool-predictions")
+
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, "env")
+	executor := NewSubprocessExecutor([]string{"sh", "-c", `env > "$0"; echo '{"predictions": []}'`, envPath}, dir)
+
+	model := models.ModelMetadata{ModelId: "m1", UserId: "u1", S3Key: "models/u1/m1.pkl", Features: []string{"home_win_pct"}}
+	games := []GameInput{{GameId: "g1", HomeTeamId: "111", AwayTeamId: "147", Features: map[string]float64{"home_win_pct": 0.5}}}
+	if _, err := executor.Predict(context.Background(), model, games); err != nil {
+		t.Fatalf("Predict() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		name, _, _ := strings.Cut(line, "=")
+		// sh sets a few variables of its own, such as PWD
+		if name == "AWS_SECRET_ACCESS_KEY" || name == "DYNAMODB_PREDICTIONS_TABLE" || name == "HOME" {
+			t.Errorf("model process saw %s", line)
+		}
+	}
+	if !strings.Contains(string(raw), "PATH="+os.Getenv("PATH")) {
+		t.Errorf("model process environment = %q, want PATH passed through", raw)
+	}
+}
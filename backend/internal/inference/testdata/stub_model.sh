@@ -0,0 +1,5 @@
+#!/bin/sh
+# Stand-in for scripts/run_model.py in runner tests: saves the request it is sent
+# to $1 and replies with the canned response in $2
+cat > "$1"
+cat "$2"
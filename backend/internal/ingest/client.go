@@ -0,0 +1,67 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultStatsAPIURL is the public MLB Stats API
+const DefaultStatsAPIURL = "https://statsapi.mlb.com/api/v1"
+
+// ScheduleClient fetches the MLB schedule for a range of days (YYYY-MM-DD, inclusive)
+type ScheduleClient interface {
+	Schedule(ctx context.Context, from, to string) (*Schedule, error)
+}
+
+// StatsAPIClient reads the schedule from the MLB Stats API
+type StatsAPIClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewStatsAPIClient(baseURL string) *StatsAPIClient {
+	if baseURL == "" {
+		baseURL = DefaultStatsAPIURL
+	}
+	return &StatsAPIClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *StatsAPIClient) Schedule(ctx context.Context, from, to string) (*Schedule, error) {
+	query := url.Values{
+		"sportId":   {"1"},
+		"startDate": {from},
+		"endDate":   {to},
+		// Adds each venue's time zone, for the local start time
+		"hydrate": {"venue(timezone)"},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/schedule?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schedule: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch schedule: %s", response.Status)
+	}
+
+	var schedule Schedule
+	if err := json.NewDecoder(response.Body).Decode(&schedule); err != nil {
+		return nil, fmt.Errorf("failed to decode schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
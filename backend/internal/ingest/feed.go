@@ -0,0 +1,146 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// GameFeedClient fetches one game's live feed, which carries its status and line score
+type GameFeedClient interface {
+	GameFeed(ctx context.Context, gameId string) (*GameFeed, error)
+}
+
+// GameFeed is the response of the Stats API /game/{gamePk}/feed/live endpoint, keeping only what we use
+type GameFeed struct {
+	GamePk   int64        `json:"gamePk"`
+	GameData FeedGameData `json:"gameData"`
+	LiveData FeedLiveData `json:"liveData"`
+}
+
+type FeedGameData struct {
+	Status   GameStatus `json:"status"`
+	Datetime struct {
+		// DateTime is the scheduled start, or the actual first pitch once the game has begun
+		DateTime string `json:"dateTime"`
+	} `json:"datetime"`
+	Teams struct {
+		Home TeamRef `json:"home"`
+		Away TeamRef `json:"away"`
+	} `json:"teams"`
+}
+
+type FeedLiveData struct {
+	Linescore FeedLinescore `json:"linescore"`
+}
+
+type FeedLinescore struct {
+	CurrentInning    int          `json:"currentInning"`
+	ScheduledInnings int          `json:"scheduledInnings"`
+	Innings          []FeedInning `json:"innings"`
+	Teams            struct {
+		Home FeedLineTotals `json:"home"`
+		Away FeedLineTotals `json:"away"`
+	} `json:"teams"`
+}
+
+type FeedInning struct {
+	Num  int            `json:"num"`
+	Home FeedInningRuns `json:"home"`
+	Away FeedInningRuns `json:"away"`
+}
+
+// FeedInningRuns has no runs for a half inning that wasn't played
+type FeedInningRuns struct {
+	Runs *int `json:"runs"`
+}
+
+type FeedLineTotals struct {
+	Runs   int `json:"runs"`
+	Hits   int `json:"hits"`
+	Errors int `json:"errors"`
+}
+
+// GameFeed reads a game's live feed. The feed is only published under API version 1.1.
+func (c *StatsAPIClient) GameFeed(ctx context.Context, gameId string) (*GameFeed, error) {
+	base := c.BaseURL
+	if strings.HasSuffix(base, "/v1") {
+		base += ".1"
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/game/"+gameId+"/feed/live", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch game feed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch game feed for game %s: %s", gameId, response.Status)
+	}
+
+	var feed GameFeed
+	if err := json.NewDecoder(response.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to decode game feed: %w", err)
+	}
+
+	return &feed, nil
+}
+
+// ApplyResult copies a feed's status and, once the game is final, its line score and winner onto game
+func ApplyResult(game *models.Game, feed *GameFeed) error {
+	if strconv.FormatInt(feed.GamePk, 10) != game.GameId {
+		return fmt.Errorf("feed is for game %d, not %s", feed.GamePk, game.GameId)
+	}
+
+	linescore := feed.LiveData.Linescore
+	game.Status = gameStatus(feed.GameData.Status)
+
+	// A TBD start, as for game 2 of a traditional doubleheader, is filled in once it is known
+	if game.StartTimeTBD && !feed.GameData.Status.StartTimeTBD {
+		if start, err := time.Parse(time.RFC3339, feed.GameData.Datetime.DateTime); err == nil {
+			game.Date = start.UTC()
+			game.StartTimeTBD = false
+		}
+	}
+
+	game.HomeScore = linescore.Teams.Home.Runs
+	game.AwayScore = linescore.Teams.Away.Runs
+	game.Innings = len(linescore.Innings)
+
+	lineScore := &models.LineScore{
+		Innings: make([]models.InningScore, 0, len(linescore.Innings)),
+		Home:    models.LineTotals(linescore.Teams.Home),
+		Away:    models.LineTotals(linescore.Teams.Away),
+	}
+	for _, inning := range linescore.Innings {
+		lineScore.Innings = append(lineScore.Innings, models.InningScore{
+			Num:  inning.Num,
+			Home: inning.Home.Runs,
+			Away: inning.Away.Runs,
+		})
+	}
+	game.LineScore = lineScore
+
+	game.Winner = ""
+	if game.Status == models.GameStatusFinal {
+		switch {
+		case game.HomeScore > game.AwayScore:
+			game.Winner = game.HomeTeamId
+		case game.AwayScore > game.HomeScore:
+			game.Winner = game.AwayTeamId
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func loadFeed(t *testing.T, gameId string) *GameFeed {
+	t.Helper()
+	feed, err := fixtureClient{}.GameFeed(context.Background(), gameId)
+	if err != nil {
+		t.Fatalf("failed to load feed fixture: %v", err)
+	}
+	return feed
+}
+
+func TestStatsAPIClientGameFeedUsesVersion11(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/feed_777001.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1.1/game/777001/feed/live" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	feed, err := NewStatsAPIClient(server.URL+"/api/v1").GameFeed(context.Background(), "777001")
+	if err != nil {
+		t.Fatalf("GameFeed() error = %v", err)
+	}
+	if feed.GamePk != 777001 {
+		t.Errorf("GamePk = %d, want 777001", feed.GamePk)
+	}
+}
+
+func TestApplyResultFinal(t *testing.T) {
+	game := models.Game{GameId: "777001", HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusLive}
+
+	if err := ApplyResult(&game, loadFeed(t, "777001")); err != nil {
+		t.Fatalf("ApplyResult() error = %v", err)
+	}
+
+	if game.Status != models.GameStatusFinal || game.HomeScore != 5 || game.AwayScore != 3 || game.Winner != "111" {
+		t.Errorf("ApplyResult() = %+v, want a final 5-3 home win", game)
+	}
+	if game.Innings != 9 {
+		t.Errorf("Innings = %d, want 9", game.Innings)
+	}
+	if game.LineScore.Home != (models.LineTotals{Runs: 5, Hits: 9, Errors: 0}) {
+		t.Errorf("home totals = %+v", game.LineScore.Home)
+	}
+
+	// The home team led after the top of the 9th, so the bottom half wasn't played
+	ninth := game.LineScore.Innings[8]
+	if ninth.Num != 9 || ninth.Home != nil || ninth.Away == nil || *ninth.Away != 0 {
+		t.Errorf("9th inning = %+v, want no home half", ninth)
+	}
+}
+
+func TestApplyResultExtraInnings(t *testing.T) {
+	game := models.Game{GameId: "777005", HomeTeamId: "112", AwayTeamId: "158"}
+
+	if err := ApplyResult(&game, loadFeed(t, "777005")); err != nil {
+		t.Fatalf("ApplyResult() error = %v", err)
+	}
+
+	if game.Innings != 11 || len(game.LineScore.Innings) != 11 {
+		t.Errorf("Innings = %d, want 11", game.Innings)
+	}
+	if game.Status != models.GameStatusFinal || game.HomeScore != 3 || game.AwayScore != 5 || game.Winner != "158" {
+		t.Errorf("ApplyResult() = %+v, want a final 5-3 away win", game)
+	}
+
+	// Runs by inning add up to the total
+	awayRuns := 0
+	for _, inning := range game.LineScore.Innings {
+		awayRuns += *inning.Away
+	}
+	if awayRuns != game.AwayScore {
+		t.Errorf("away innings sum to %d, want %d", awayRuns, game.AwayScore)
+	}
+}
+
+func TestApplyResultSuspended(t *testing.T) {
+	game := models.Game{GameId: "777006", HomeTeamId: "137", AwayTeamId: "119"}
+
+	if err := ApplyResult(&game, loadFeed(t, "777006")); err != nil {
+		t.Fatalf("ApplyResult() error = %v", err)
+	}
+
+	if game.Status != models.GameStatusSuspended {
+		t.Errorf("Status = %q, want suspended", game.Status)
+	}
+	if game.Winner != "" {
+		t.Errorf("Winner = %q, want none for a suspended game", game.Winner)
+	}
+	if game.Innings != 6 || game.HomeScore != 2 || game.AwayScore != 2 {
+		t.Errorf("ApplyResult() = %+v, want the score when play stopped", game)
+	}
+}
+
+func TestApplyResultFillsTBDStart(t *testing.T) {
+	game := models.Game{
+		GameId:       "777001",
+		Date:         time.Date(2025, 6, 10, 7, 33, 0, 0, time.UTC),
+		StartTimeTBD: true,
+		GameNumber:   2,
+		HomeTeamId:   "111",
+		AwayTeamId:   "147",
+	}
+
+	feed := loadFeed(t, "777001")
+	feed.GameData.Datetime.DateTime = "2025-06-10T20:41:00Z"
+
+	if err := ApplyResult(&game, feed); err != nil {
+		t.Fatalf("ApplyResult() error = %v", err)
+	}
+	if game.StartTimeTBD || !game.Date.Equal(time.Date(2025, 6, 10, 20, 41, 0, 0, time.UTC)) {
+		t.Errorf("ApplyResult() start = %v, TBD %v, want the feed's 20:41 start", game.Date, game.StartTimeTBD)
+	}
+}
+
+func TestApplyResultRejectsOtherGame(t *testing.T) {
+	game := models.Game{GameId: "777005"}
+	if err := ApplyResult(&game, loadFeed(t, "777001")); err == nil {
+		t.Fatal("ApplyResult() error = nil, want an error for another game's feed")
+	}
+}
+
+// seedResultGames stores games that have started, plus one still to come and one already settled
+func seedResultGames(t *testing.T, db *database.MemoryDB) {
+	t.Helper()
+	ctx := context.Background()
+
+	started := time.Date(2025, 6, 10, 23, 5, 0, 0, time.UTC)
+	games := []models.Game{
+		{GameId: "777001", Date: started, HomeTeamId: "111", AwayTeamId: "147", Status: models.GameStatusLive},
+		{GameId: "777003", Date: started, HomeTeamId: "143", AwayTeamId: "121", Status: models.GameStatusLive},
+		{GameId: "777005", Date: started, HomeTeamId: "112", AwayTeamId: "158", Status: models.GameStatusUpcoming},
+		{GameId: "777006", Date: started, HomeTeamId: "137", AwayTeamId: "119", Status: models.GameStatusLive},
+		{GameId: "777007", Date: started, HomeTeamId: "110", AwayTeamId: "141", Status: models.GameStatusCompleted},
+		// No fixture exists for these, so fetching them would fail the run
+		{GameId: "777008", Date: time.Now().Add(24 * time.Hour), GameDay: "2025-06-10", HomeTeamId: "114", AwayTeamId: "116", Status: models.GameStatusUpcoming},
+		{GameId: "777009", Date: started, HomeTeamId: "133", AwayTeamId: "136", Status: models.GameStatusPostponed},
+	}
+	for i := range games {
+		if err := db.CreateGame(ctx, &games[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIngestResults(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+	seedResultGames(t, db)
+
+	var settled []string
+	ingester := NewIngester(fixtureClient{}, db)
+	ingester.Settle = func(ctx context.Context, game models.Game) error {
+		settled = append(settled, game.GameId)
+		return nil
+	}
+
+	result, err := ingester.IngestResults(ctx, "2025-06-10", "2025-06-10")
+	if err != nil {
+		t.Fatalf("IngestResults() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Fatalf("IngestResults() errors = %v", result.Errors)
+	}
+	if result.Fetched != 4 || result.Updated != 4 || result.Settled != 2 {
+		t.Errorf("IngestResults() = %+v, want 4 fetched, 4 updated and 2 settled", result)
+	}
+	if len(settled) != 2 || settled[0] != "777001" || settled[1] != "777005" {
+		t.Errorf("settled %v, want the two final games", settled)
+	}
+
+	suspended, err := db.GetGame(ctx, "777006")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suspended.Status != models.GameStatusSuspended {
+		t.Errorf("777006 status = %q, want suspended", suspended.Status)
+	}
+
+	// A second run finds nothing new to write but still offers final games for settlement
+	settled = nil
+	result, err = ingester.IngestResults(ctx, "2025-06-10", "2025-06-10")
+	if err != nil {
+		t.Fatalf("IngestResults() error = %v", err)
+	}
+	if result.Updated != 0 || result.Skipped != 4 || len(settled) != 2 {
+		t.Errorf("second IngestResults() = %+v settling %v, want nothing updated and 2 settled", result, settled)
+	}
+}
+
+func TestIngestResultsCompletesGames(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+	seedResultGames(t, db)
+
+	prediction := &models.Prediction{UserId: "user-1", GameId: "777005", PredictedWinnerId: "158", HomeScorePredicted: 2, AwayScorePredicted: 4}
+	if err := db.CreatePrediction(ctx, prediction); err != nil {
+		t.Fatal(err)
+	}
+
+	ingester := NewIngester(fixtureClient{}, db)
+	ingester.Settle = CompleteGames(db)
+
+	result, err := ingester.IngestResults(ctx, "2025-06-10", "2025-06-10")
+	if err != nil {
+		t.Fatalf("IngestResults() error = %v", err)
+	}
+	if result.Settled != 2 {
+		t.Fatalf("IngestResults() = %+v, want 2 settled", result)
+	}
+
+	game, err := db.GetGame(ctx, "777005")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Status != models.GameStatusCompleted || game.Innings != 11 || game.Winner != "158" {
+		t.Errorf("777005 = %+v, want completed after 11 innings", game)
+	}
+
+	predictions, err := db.GetPredictionsByGame(ctx, "777005")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(predictions) != 1 || predictions[0].WinnerCorrect == nil || !*predictions[0].WinnerCorrect {
+		t.Errorf("prediction was not scored: %+v", predictions)
+	}
+	if predictions[0].Season != 2025 || predictions[0].GameType != models.GameTypeRegular {
+		t.Errorf("prediction season = %d %q, want the game's 2025 regular season", predictions[0].Season, predictions[0].GameType)
+	}
+
+	// Settled games are left alone from then on
+	result, err = ingester.IngestResults(ctx, "2025-06-10", "2025-06-10")
+	if err != nil {
+		t.Fatalf("IngestResults() error = %v", err)
+	}
+	if result.Fetched != 2 || result.Settled != 0 {
+		t.Errorf("second IngestResults() = %+v, want only the live and suspended games fetched", result)
+	}
+}
+
+func TestIngestResultsBackfillsSeason(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+	seedResultGames(t, db)
+
+	// Longer than one GetGamesByDateRange call allows
+	result, err := NewIngester(fixtureClient{}, db).IngestResults(ctx, "2025-03-27", "2025-09-28")
+	if err != nil {
+		t.Fatalf("IngestResults() error = %v", err)
+	}
+	if len(result.Errors) != 0 || result.Fetched != 4 || result.Updated != 4 {
+		t.Errorf("IngestResults() = %+v, want the 4 started games fetched and updated", result)
+	}
+}
@@ -0,0 +1,177 @@
+// Package ingest loads the MLB schedule and game results into the games table, and each
+// season's calendar into the seasons table.
+//
+// Games are fetched through a ScheduleClient, normalized into models.Game and upserted with
+// CreateGame. Results come from each game's live feed once it has started. Games that have
+// already been settled are never overwritten, so re-running an ingestion over past days is safe.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// Client is everything the ingester reads from the Stats API
+type Client interface {
+	ScheduleClient
+	GameFeedClient
+}
+
+// SettleFunc is called for every final game after its result is saved, e.g. to score predictions.
+// It must be idempotent: a game stays final, and is passed again, until it is settled.
+type SettleFunc func(ctx context.Context, game models.Game) error
+
+type Ingester struct {
+	client Client
+	db     database.GameStore
+	// Settle, when set, is the settlement hook for final games
+	Settle SettleFunc
+}
+
+func NewIngester(client Client, db database.GameStore) *Ingester {
+	return &Ingester{client: client, db: db}
+}
+
+// CompleteGames is a SettleFunc that completes games in db, which scores their predictions
+func CompleteGames(db database.GameStore) SettleFunc {
+	return func(ctx context.Context, game models.Game) error {
+		return db.CompleteGame(ctx, game.GameId, game.HomeScore, game.AwayScore, game.Winner)
+	}
+}
+
+// Result counts what one ingestion run did
+type Result struct {
+	Fetched int      `json:"fetched"`
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Settled int      `json:"settled,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// IngestSchedule upserts every game scheduled from..to (YYYY-MM-DD, inclusive).
+// A game that fails to normalize or save is recorded in Result.Errors and the run carries on;
+// the error is only for a failed schedule fetch.
+func (i *Ingester) IngestSchedule(ctx context.Context, from, to string) (*Result, error) {
+	schedule, err := i.client.Schedule(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, date := range schedule.Dates {
+		for _, scheduled := range date.Games {
+			result.Fetched++
+
+			game, err := NormalizeGame(scheduled)
+			if err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+
+			created, saved, err := i.upsert(ctx, &game)
+			switch {
+			case err != nil:
+				result.Errors = append(result.Errors, fmt.Sprintf("game %s: %v", game.GameId, err))
+			case !saved:
+				result.Skipped++
+			case created:
+				result.Created++
+			default:
+				result.Updated++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// upsert saves game unless the stored copy has already been settled.
+// The schedule has no line score, so one recorded by result ingestion is kept.
+func (i *Ingester) upsert(ctx context.Context, game *models.Game) (created bool, saved bool, err error) {
+	existing, err := i.db.GetGame(ctx, game.GameId)
+	switch {
+	case errors.Is(err, database.ErrGameNotFound):
+		created = true
+	case err != nil:
+		return false, false, err
+	case existing.Status == models.GameStatusCompleted:
+		return false, false, nil
+	case game.LineScore == nil:
+		game.LineScore = existing.LineScore
+		game.Innings = existing.Innings
+		game.Winner = existing.Winner
+	}
+
+	if err := i.db.CreateGame(ctx, game); err != nil {
+		return false, false, err
+	}
+	return created, true, nil
+}
+
+// IngestResults refreshes every started, unsettled game scheduled from..to (YYYY-MM-DD, inclusive)
+// from its live feed, then passes final games to the settlement hook. Games whose feed hasn't
+// changed are counted as skipped and not rewritten. The range may span more than a month, so a
+// missed stretch of results can be backfilled in one run.
+func (i *Ingester) IngestResults(ctx context.Context, from, to string) (*Result, error) {
+	games, err := database.GetGamesInRange(ctx, i.db, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	now := time.Now()
+	for _, game := range games {
+		if !awaitingResult(game, now) {
+			continue
+		}
+		result.Fetched++
+
+		feed, err := i.client.GameFeed(ctx, game.GameId)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("game %s: %v", game.GameId, err))
+			continue
+		}
+
+		updated := game
+		if err := ApplyResult(&updated, feed); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+
+		if reflect.DeepEqual(updated, game) {
+			result.Skipped++
+		} else if err := i.db.CreateGame(ctx, &updated); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("game %s: %v", game.GameId, err))
+			continue
+		} else {
+			result.Updated++
+		}
+
+		if updated.Status != models.GameStatusFinal || i.Settle == nil {
+			continue
+		}
+		if err := i.Settle(ctx, updated); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("settle game %s: %v", game.GameId, err))
+			continue
+		}
+		result.Settled++
+	}
+
+	return result, nil
+}
+
+// awaitingResult is whether a game could have a new result: it has started and isn't settled or called off
+func awaitingResult(game models.Game, now time.Time) bool {
+	switch game.Status {
+	case models.GameStatusCompleted, models.GameStatusPostponed, models.GameStatusCancelled:
+		return false
+	}
+	return !game.Date.After(now)
+}
@@ -0,0 +1,285 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// fixtureClient serves a recorded Stats API response instead of calling the network
+type fixtureClient struct {
+	path string
+}
+
+func (c fixtureClient) Schedule(ctx context.Context, from, to string) (*Schedule, error) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	var schedule Schedule
+	return &schedule, json.Unmarshal(data, &schedule)
+}
+
+// GameFeed serves testdata/feed_{gameId}.json
+func (c fixtureClient) GameFeed(ctx context.Context, gameId string) (*GameFeed, error) {
+	data, err := os.ReadFile(filepath.Join("testdata", "feed_"+gameId+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var feed GameFeed
+	return &feed, json.Unmarshal(data, &feed)
+}
+
+// Season serves testdata/season_{year}.json
+func (c fixtureClient) Season(ctx context.Context, year int) (*SeasonDates, error) {
+	data, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("season_%d.json", year)))
+	if err != nil {
+		return nil, err
+	}
+	var seasons SeasonsResponse
+	if err := json.Unmarshal(data, &seasons); err != nil {
+		return nil, err
+	}
+	return &seasons.Seasons[0], nil
+}
+
+func loadFixture(t *testing.T) *Schedule {
+	t.Helper()
+	schedule, err := fixtureClient{path: "testdata/schedule.json"}.Schedule(context.Background(), "", "")
+	if err != nil {
+		t.Fatalf("failed to load fixture: %v", err)
+	}
+	return schedule
+}
+
+func TestStatsAPIClientSchedule(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/schedule.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/schedule" || query.Get("sportId") != "1" ||
+			query.Get("startDate") != "2025-06-10" || query.Get("endDate") != "2025-06-11" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	schedule, err := NewStatsAPIClient(server.URL).Schedule(context.Background(), "2025-06-10", "2025-06-11")
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if len(schedule.Dates) != 2 || len(schedule.Dates[0].Games) != 2 || len(schedule.Dates[1].Games) != 2 {
+		t.Fatalf("Schedule() returned %+v, want 2 days of 2 games", schedule.Dates)
+	}
+}
+
+func TestStatsAPIClientScheduleError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if _, err := NewStatsAPIClient(server.URL).Schedule(context.Background(), "2025-06-10", "2025-06-10"); err == nil {
+		t.Fatal("Schedule() error = nil, want an error for a 503")
+	}
+}
+
+func TestNormalizeGame(t *testing.T) {
+	schedule := loadFixture(t)
+
+	game, err := NormalizeGame(schedule.Dates[0].Games[0])
+	if err != nil {
+		t.Fatalf("NormalizeGame() error = %v", err)
+	}
+
+	want := models.Game{
+		GameId:     "777001",
+		Date:       time.Date(2025, 6, 10, 23, 5, 0, 0, time.UTC),
+		GameDay:    "2025-06-10",
+		Season:     2025,
+		GameType:   models.GameTypeRegular,
+		GameNumber: 1,
+		HomeTeam:   "Boston Red Sox",
+		HomeTeamId: "111",
+		AwayTeam:   "New York Yankees",
+		AwayTeamId: "147",
+		HomeScore:  5,
+		AwayScore:  3,
+		Status:     models.GameStatusFinal,
+
+		StartTimeZone: "America/New_York",
+	}
+	if game != want {
+		t.Errorf("NormalizeGame() = %+v, want %+v", game, want)
+	}
+}
+
+func TestNormalizeGameUsesOfficialDate(t *testing.T) {
+	schedule := loadFixture(t)
+
+	// Starts after midnight UTC but counts for the previous day
+	game, err := NormalizeGame(schedule.Dates[0].Games[1])
+	if err != nil {
+		t.Fatalf("NormalizeGame() error = %v", err)
+	}
+	if game.GameDay != "2025-06-10" {
+		t.Errorf("GameDay = %q, want 2025-06-10", game.GameDay)
+	}
+	if game.Date.Format(models.GameDayLayout) != "2025-06-11" {
+		t.Errorf("Date = %v, want the UTC start on 2025-06-11", game.Date)
+	}
+}
+
+func TestNormalizeGameDoubleheader(t *testing.T) {
+	schedule, err := fixtureClient{path: "testdata/schedule_doubleheader.json"}.Schedule(context.Background(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	games := schedule.Dates[0].Games
+
+	first, err := NormalizeGame(games[0])
+	if err != nil {
+		t.Fatalf("NormalizeGame() error = %v", err)
+	}
+	if first.GameNumber != 1 || first.Doubleheader != models.DoubleheaderTraditional || first.StartTimeTBD {
+		t.Errorf("game 1 = %+v, want game 1 of a traditional doubleheader with a start time", first)
+	}
+	if local := first.LocalStart().Format("15:04 MST"); local != "13:05 EDT" && local != "17:05 UTC" {
+		t.Errorf("game 1 LocalStart() = %s, want 13:05 EDT", local)
+	}
+
+	second, err := NormalizeGame(games[1])
+	if err != nil {
+		t.Fatalf("NormalizeGame() error = %v", err)
+	}
+	if second.GameNumber != 2 || second.Doubleheader != models.DoubleheaderTraditional || !second.StartTimeTBD {
+		t.Errorf("game 2 = %+v, want game 2 of a traditional doubleheader starting TBD", second)
+	}
+	if second.GameDay != "2025-06-14" {
+		t.Errorf("game 2 GameDay = %q, want 2025-06-14", second.GameDay)
+	}
+}
+
+func TestGameType(t *testing.T) {
+	for code, want := range map[string]string{
+		"R": models.GameTypeRegular,
+		"S": models.GameTypeSpring,
+		"F": models.GameTypeWildCard,
+		"W": models.GameTypeWorldSeries,
+		"":  models.GameTypeRegular,
+		"I": models.GameTypeExhibition,
+	} {
+		if got := GameType(code); got != want {
+			t.Errorf("GameType(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestNormalizeGameStatuses(t *testing.T) {
+	schedule := loadFixture(t)
+
+	want := map[string]string{
+		"777001": models.GameStatusFinal,
+		"777002": models.GameStatusPostponed,
+		"777003": models.GameStatusLive,
+		"777004": models.GameStatusUpcoming,
+	}
+	for _, date := range schedule.Dates {
+		for _, scheduled := range date.Games {
+			game, err := NormalizeGame(scheduled)
+			if err != nil {
+				t.Fatalf("NormalizeGame(%d) error = %v", scheduled.GamePk, err)
+			}
+			if game.Status != want[game.GameId] {
+				t.Errorf("game %s status = %q, want %q", game.GameId, game.Status, want[game.GameId])
+			}
+		}
+	}
+}
+
+func TestNormalizeGameRejectsMissingTeams(t *testing.T) {
+	scheduled := loadFixture(t).Dates[0].Games[0]
+	scheduled.Teams.Home.Team.Id = 0
+
+	if _, err := NormalizeGame(scheduled); err == nil {
+		t.Fatal("NormalizeGame() error = nil, want an error for a missing team")
+	}
+}
+
+func TestIngestSchedule(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+
+	result, err := NewIngester(fixtureClient{path: "testdata/schedule.json"}, db).IngestSchedule(ctx, "2025-06-10", "2025-06-11")
+	if err != nil {
+		t.Fatalf("IngestSchedule() error = %v", err)
+	}
+	if result.Fetched != 4 || result.Created != 4 || len(result.Errors) != 0 {
+		t.Fatalf("IngestSchedule() = %+v, want 4 games created", result)
+	}
+
+	games, err := db.GetGamesByDate(ctx, "2025-06-11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("GetGamesByDate() returned %d games, want 2", len(games))
+	}
+
+	// Running again updates rather than duplicating
+	result, err = NewIngester(fixtureClient{path: "testdata/schedule.json"}, db).IngestSchedule(ctx, "2025-06-10", "2025-06-11")
+	if err != nil {
+		t.Fatalf("IngestSchedule() error = %v", err)
+	}
+	if result.Created != 0 || result.Updated != 4 {
+		t.Errorf("second IngestSchedule() = %+v, want 4 games updated", result)
+	}
+}
+
+func TestIngestScheduleKeepsSettledGames(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+
+	settled := &models.Game{
+		GameId:     "777001",
+		Date:       time.Date(2025, 6, 10, 23, 5, 0, 0, time.UTC),
+		HomeTeamId: "111",
+		AwayTeamId: "147",
+		Status:     models.GameStatusUpcoming,
+	}
+	if err := db.CreateGame(ctx, settled); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CompleteGame(ctx, "777001", 6, 2, "111"); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewIngester(fixtureClient{path: "testdata/schedule.json"}, db).IngestSchedule(ctx, "2025-06-10", "2025-06-11")
+	if err != nil {
+		t.Fatalf("IngestSchedule() error = %v", err)
+	}
+	if result.Skipped != 1 || result.Created != 3 {
+		t.Errorf("IngestSchedule() = %+v, want 1 skipped and 3 created", result)
+	}
+
+	game, err := db.GetGame(ctx, "777001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Status != models.GameStatusCompleted || game.HomeScore != 6 || game.AwayScore != 2 {
+		t.Errorf("settled game was overwritten: %+v", game)
+	}
+}
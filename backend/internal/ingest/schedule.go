@@ -0,0 +1,177 @@
+package ingest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// Schedule is the response of the Stats API /schedule endpoint, keeping only what we use
+type Schedule struct {
+	Dates []ScheduleDate `json:"dates"`
+}
+
+type ScheduleDate struct {
+	Date  string         `json:"date"`
+	Games []ScheduleGame `json:"games"`
+}
+
+type ScheduleGame struct {
+	GamePk int64 `json:"gamePk"`
+	// GameType is a Stats API code such as R (regular season) or W (World Series)
+	GameType string `json:"gameType"`
+	Season   string `json:"season"`
+	// GameDate is the scheduled start in UTC; OfficialDate is the day the game counts for
+	GameDate     string     `json:"gameDate"`
+	OfficialDate string     `json:"officialDate"`
+	Status       GameStatus `json:"status"`
+	Teams        GameTeams  `json:"teams"`
+	// DoubleHeader is N for a single game, Y for a traditional doubleheader and S for a split one
+	DoubleHeader string `json:"doubleHeader"`
+	GameNumber   int    `json:"gameNumber"`
+	// Venue is only filled in when the schedule is requested with hydrate=venue(timezone)
+	Venue ScheduleVenue `json:"venue"`
+}
+
+type ScheduleVenue struct {
+	Id       int64 `json:"id"`
+	TimeZone struct {
+		// Id is the IANA zone, e.g. America/New_York
+		Id string `json:"id"`
+	} `json:"timeZone"`
+}
+
+type GameStatus struct {
+	// AbstractGameState is Preview, Live or Final
+	AbstractGameState string `json:"abstractGameState"`
+	DetailedState     string `json:"detailedState"`
+	// StartTimeTBD is set while the start is unscheduled; gameDate then holds a placeholder
+	StartTimeTBD bool `json:"startTimeTBD"`
+}
+
+type GameTeams struct {
+	Home TeamSide `json:"home"`
+	Away TeamSide `json:"away"`
+}
+
+type TeamSide struct {
+	Score *int    `json:"score"`
+	Team  TeamRef `json:"team"`
+}
+
+type TeamRef struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// NormalizeGame converts a schedule entry into a models.Game
+func NormalizeGame(scheduled ScheduleGame) (models.Game, error) {
+	if scheduled.GamePk == 0 {
+		return models.Game{}, fmt.Errorf("game has no gamePk")
+	}
+	if scheduled.Teams.Home.Team.Id == 0 || scheduled.Teams.Away.Team.Id == 0 {
+		return models.Game{}, fmt.Errorf("game %d is missing a team", scheduled.GamePk)
+	}
+
+	start, err := time.Parse(time.RFC3339, scheduled.GameDate)
+	if err != nil {
+		return models.Game{}, fmt.Errorf("game %d has an invalid start time %q", scheduled.GamePk, scheduled.GameDate)
+	}
+
+	gameDay := scheduled.OfficialDate
+	if gameDay == "" {
+		gameDay = start.Format(models.GameDayLayout)
+	}
+	if _, err := time.Parse(models.GameDayLayout, gameDay); err != nil {
+		return models.Game{}, fmt.Errorf("game %d has an invalid date %q", scheduled.GamePk, gameDay)
+	}
+
+	// Season is the game day's year when the schedule leaves it out
+	season, _ := strconv.Atoi(scheduled.Season)
+	if season == 0 {
+		season, _ = strconv.Atoi(gameDay[:4])
+	}
+
+	game := models.Game{
+		GameId:     strconv.FormatInt(scheduled.GamePk, 10),
+		Date:       start.UTC(),
+		GameDay:    gameDay,
+		Season:     season,
+		GameType:   GameType(scheduled.GameType),
+		HomeTeam:   scheduled.Teams.Home.Team.Name,
+		HomeTeamId: strconv.FormatInt(scheduled.Teams.Home.Team.Id, 10),
+		AwayTeam:   scheduled.Teams.Away.Team.Name,
+		AwayTeamId: strconv.FormatInt(scheduled.Teams.Away.Team.Id, 10),
+		Status:     gameStatus(scheduled.Status),
+		GameNumber: max(scheduled.GameNumber, 1),
+
+		Doubleheader:  doubleheaders[scheduled.DoubleHeader],
+		StartTimeTBD:  scheduled.Status.StartTimeTBD,
+		StartTimeZone: scheduled.Venue.TimeZone.Id,
+	}
+	if scheduled.Teams.Home.Score != nil {
+		game.HomeScore = *scheduled.Teams.Home.Score
+	}
+	if scheduled.Teams.Away.Score != nil {
+		game.AwayScore = *scheduled.Teams.Away.Score
+	}
+
+	return game, nil
+}
+
+// gameTypes maps Stats API gameType codes to our game types
+var gameTypes = map[string]string{
+	"S": models.GameTypeSpring,
+	"R": models.GameTypeRegular,
+	"F": models.GameTypeWildCard,
+	"D": models.GameTypeDivisionSeries,
+	"L": models.GameTypeLeagueChampionshipSeries,
+	"W": models.GameTypeWorldSeries,
+	"A": models.GameTypeAllStar,
+	"E": models.GameTypeExhibition,
+}
+
+// doubleheaders maps the Stats API doubleHeader flag to our doubleheader types; N is a single game
+var doubleheaders = map[string]string{
+	"Y": models.DoubleheaderTraditional,
+	"S": models.DoubleheaderSplit,
+}
+
+// GameType maps a Stats API gameType code to one of our game types. Schedules without a code are
+// regular season games; codes we don't score, such as intrasquad games, count as exhibitions.
+func GameType(code string) string {
+	if code == "" {
+		return models.GameTypeRegular
+	}
+	if gameType, ok := gameTypes[code]; ok {
+		return gameType
+	}
+	return models.GameTypeExhibition
+}
+
+// gameStatus maps the Stats API game state to our game statuses.
+// Played games are only final here; completing them is left to settlement.
+func gameStatus(status GameStatus) string {
+	// Suspended games are reported as Live or Final depending on the feed, e.g. "Suspended: Rain"
+	if strings.HasPrefix(status.DetailedState, "Suspended") {
+		return models.GameStatusSuspended
+	}
+
+	switch status.AbstractGameState {
+	case "Live":
+		return models.GameStatusLive
+	case "Final":
+		switch status.DetailedState {
+		case "Postponed":
+			return models.GameStatusPostponed
+		case "Cancelled":
+			return models.GameStatusCancelled
+		}
+		return models.GameStatusFinal
+	default:
+		return models.GameStatusUpcoming
+	}
+}
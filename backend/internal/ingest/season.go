@@ -0,0 +1,124 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// SeasonClient fetches one season's calendar
+type SeasonClient interface {
+	Season(ctx context.Context, year int) (*SeasonDates, error)
+}
+
+// SeasonsResponse is the response of the Stats API /seasons/{year} endpoint
+type SeasonsResponse struct {
+	Seasons []SeasonDates `json:"seasons"`
+}
+
+// SeasonDates are the phase boundaries the Stats API publishes for a season, keeping only what we use
+type SeasonDates struct {
+	SeasonId               string `json:"seasonId"`
+	SpringStartDate        string `json:"springStartDate"`
+	SpringEndDate          string `json:"springEndDate"`
+	RegularSeasonStartDate string `json:"regularSeasonStartDate"`
+	RegularSeasonEndDate   string `json:"regularSeasonEndDate"`
+	PostSeasonStartDate    string `json:"postSeasonStartDate"`
+	PostSeasonEndDate      string `json:"postSeasonEndDate"`
+}
+
+func (c *StatsAPIClient) Season(ctx context.Context, year int) (*SeasonDates, error) {
+	query := url.Values{"sportId": {"1"}}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/seasons/"+strconv.Itoa(year)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch season: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch season %d: %s", year, response.Status)
+	}
+
+	var seasons SeasonsResponse
+	if err := json.NewDecoder(response.Body).Decode(&seasons); err != nil {
+		return nil, fmt.Errorf("failed to decode season: %w", err)
+	}
+	if len(seasons.Seasons) == 0 {
+		return nil, fmt.Errorf("season %d not found", year)
+	}
+
+	return &seasons.Seasons[0], nil
+}
+
+// NormalizeSeason converts the Stats API dates into a models.Season with its spring, regular season
+// and postseason phases. A phase the API hasn't scheduled yet is left out.
+func NormalizeSeason(dates SeasonDates) (models.Season, error) {
+	year, err := strconv.Atoi(dates.SeasonId)
+	if err != nil {
+		return models.Season{}, fmt.Errorf("season has an invalid id %q", dates.SeasonId)
+	}
+
+	season := models.Season{Year: year, Phases: make([]models.SeasonPhase, 0, 3)}
+	for _, phase := range []models.SeasonPhase{
+		{Name: models.SeasonPhaseSpring, Start: dates.SpringStartDate, End: dates.SpringEndDate},
+		{Name: models.SeasonPhaseRegular, Start: dates.RegularSeasonStartDate, End: dates.RegularSeasonEndDate},
+		{Name: models.SeasonPhasePostseason, Start: dates.PostSeasonStartDate, End: dates.PostSeasonEndDate},
+	} {
+		if phase.Start == "" || phase.End == "" {
+			continue
+		}
+		for _, day := range []string{phase.Start, phase.End} {
+			if _, err := time.Parse(models.GameDayLayout, day); err != nil {
+				return models.Season{}, fmt.Errorf("season %d has an invalid %s date %q", year, phase.Name, day)
+			}
+		}
+		season.Phases = append(season.Phases, phase)
+	}
+
+	if len(season.Phases) == 0 {
+		return models.Season{}, fmt.Errorf("season %d has no scheduled phases", year)
+	}
+	return season, nil
+}
+
+// IngestSeason stores a season's calendar. Round weights already set on the stored season are kept,
+// so re-running this as the postseason is scheduled doesn't reset scoring.
+func IngestSeason(ctx context.Context, client SeasonClient, db database.SeasonStore, year int) (*models.Season, error) {
+	dates, err := client.Season(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+
+	season, err := NormalizeSeason(*dates)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := db.GetSeason(ctx, season.Year)
+	switch {
+	case errors.Is(err, database.ErrSeasonNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		season.RoundWeights = existing.RoundWeights
+	}
+
+	if err := db.PutSeason(ctx, &season); err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
@@ -0,0 +1,87 @@
+package ingest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func TestStatsAPIClientSeason(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/season_2025.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/seasons/2025" || r.URL.Query().Get("sportId") != "1" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	dates, err := NewStatsAPIClient(server.URL).Season(context.Background(), 2025)
+	if err != nil {
+		t.Fatalf("Season() error = %v", err)
+	}
+	if dates.SeasonId != "2025" || dates.RegularSeasonStartDate != "2025-03-18" {
+		t.Errorf("Season() = %+v, want the 2025 season", dates)
+	}
+}
+
+func TestNormalizeSeason(t *testing.T) {
+	dates, err := fixtureClient{}.Season(context.Background(), 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	season, err := NormalizeSeason(*dates)
+	if err != nil {
+		t.Fatalf("NormalizeSeason() error = %v", err)
+	}
+
+	want := []models.SeasonPhase{
+		{Name: models.SeasonPhaseSpring, Start: "2025-02-20", End: "2025-03-25"},
+		{Name: models.SeasonPhaseRegular, Start: "2025-03-18", End: "2025-09-28"},
+		{Name: models.SeasonPhasePostseason, Start: "2025-09-30", End: "2025-11-01"},
+	}
+	if season.Year != 2025 || !reflect.DeepEqual(season.Phases, want) {
+		t.Errorf("NormalizeSeason() = %+v, want 2025 with phases %+v", season, want)
+	}
+
+	// The Tokyo series opened the regular season during spring training
+	for day, phase := range map[string]string{
+		"2025-03-01": models.SeasonPhaseSpring,
+		"2025-03-18": models.SeasonPhaseRegular,
+		"2025-09-29": "",
+		"2025-10-25": models.SeasonPhasePostseason,
+	} {
+		if got := season.PhaseOn(day); got != phase {
+			t.Errorf("PhaseOn(%s) = %q, want %q", day, got, phase)
+		}
+	}
+}
+
+func TestIngestSeasonKeepsRoundWeights(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+
+	weights := map[string]float32{models.GameTypeWorldSeries: 2}
+	if err := db.PutSeason(ctx, &models.Season{Year: 2025, RoundWeights: weights}); err != nil {
+		t.Fatal(err)
+	}
+
+	season, err := IngestSeason(ctx, fixtureClient{}, db, 2025)
+	if err != nil {
+		t.Fatalf("IngestSeason() error = %v", err)
+	}
+	if len(season.Phases) != 3 || !reflect.DeepEqual(season.RoundWeights, weights) {
+		t.Errorf("IngestSeason() = %+v, want 3 phases and the stored round weights", season)
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Contest is a competition within a league over a slate of games, with its own entrants and standings
+type Contest struct {
+	ContestId string       `json:"contest_id" dynamodbav:"contestId"`
+	LeagueId  string       `json:"league_id" dynamodbav:"leagueId"`
+	Name      string       `json:"name" dynamodbav:"name"`
+	Rules     ContestRules `json:"rules" dynamodbav:"rules"`
+	// GameIds is the slate the rules resolved to when the contest was created, so games scheduled
+	// afterwards don't join it and the slate isn't looked up again from the rules
+	GameIds []string `json:"game_ids,omitempty" dynamodbav:"gameIds,omitempty"`
+	// League members can enter from EntryOpens until EntryCloses
+	EntryOpens  time.Time `json:"entry_opens" dynamodbav:"entryOpens"`
+	EntryCloses time.Time `json:"entry_closes" dynamodbav:"entryCloses"`
+	CreatedBy   string    `json:"created_by" dynamodbav:"createdBy"`
+	CreatedAt   time.Time `json:"created_at" dynamodbav:"createdAt"`
+}
+
+// ContestRules select a contest's games: either an explicit list, or every game in a date range
+// that also matches the teams and game types given
+type ContestRules struct {
+	// GameIds picks the games explicitly; when set, the other rules are ignored
+	GameIds []string `json:"game_ids,omitempty" dynamodbav:"gameIds,omitempty"`
+	// From and To are game days (YYYY-MM-DD), inclusive
+	From string `json:"from,omitempty" dynamodbav:"from,omitempty"`
+	To   string `json:"to,omitempty" dynamodbav:"to,omitempty"`
+	// TeamIds keeps games either team plays in; empty keeps every team
+	TeamIds []string `json:"team_ids,omitempty" dynamodbav:"teamIds,omitempty"`
+	// GameTypes keeps games of these types; empty keeps every type
+	GameTypes []string `json:"game_types,omitempty" dynamodbav:"gameTypes,omitempty"`
+}
+
+// Matches reports whether game is on the slate the rules select
+func (r ContestRules) Matches(game Game) bool {
+	if len(r.GameIds) > 0 {
+		return slices.Contains(r.GameIds, game.GameId)
+	}
+
+	if game.GameDay < r.From || game.GameDay > r.To {
+		return false
+	}
+	if len(r.TeamIds) > 0 && !slices.Contains(r.TeamIds, game.HomeTeamId) && !slices.Contains(r.TeamIds, game.AwayTeamId) {
+		return false
+	}
+
+	gameType := game.GameType
+	if gameType == "" {
+		gameType = GameTypeRegular
+	}
+	return len(r.GameTypes) == 0 || slices.Contains(r.GameTypes, gameType)
+}
+
+// IsOpen reports whether the contest is taking entries at t
+func (c *Contest) IsOpen(t time.Time) bool {
+	return !t.Before(c.EntryOpens) && t.Before(c.EntryCloses)
+}
+
+type ContestEntry struct {
+	ContestId string    `json:"contest_id" dynamodbav:"contestId"`
+	UserId    string    `json:"user_id" dynamodbav:"userId"`
+	EnteredAt time.Time `json:"entered_at" dynamodbav:"enteredAt"`
+}
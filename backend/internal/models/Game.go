@@ -2,9 +2,75 @@ package models
 
 import "time"
 
+// GameDayLayout is the format of Game.GameDay, the calendar date the game is scheduled on
+const GameDayLayout = "2006-01-02"
+
+// Game statuses. A game is final once it has been played and completed once it has been
+// settled, which is when its predictions are scored.
+const (
+	GameStatusUpcoming  = "upcoming"
+	GameStatusLive      = "live"
+	GameStatusFinal     = "final"
+	GameStatusCompleted = "completed"
+	GameStatusPostponed = "postponed"
+	GameStatusCancelled = "cancelled"
+	// A suspended game is resumed on a later day, so it stays unsettled until it finishes
+	GameStatusSuspended = "suspended"
+)
+
+// Game types, one per Stats API gameType code. The postseason types are its rounds.
+const (
+	GameTypeSpring                   = "spring"
+	GameTypeRegular                  = "regular"
+	GameTypeWildCard                 = "wild_card"
+	GameTypeDivisionSeries           = "division_series"
+	GameTypeLeagueChampionshipSeries = "league_championship_series"
+	GameTypeWorldSeries              = "world_series"
+	GameTypeAllStar                  = "all_star"
+	GameTypeExhibition               = "exhibition"
+)
+
+// GameTypes lists every game type
+var GameTypes = []string{
+	GameTypeSpring,
+	GameTypeRegular,
+	GameTypeWildCard,
+	GameTypeDivisionSeries,
+	GameTypeLeagueChampionshipSeries,
+	GameTypeWorldSeries,
+	GameTypeAllStar,
+	GameTypeExhibition,
+}
+
+// Doubleheader types. In a traditional doubleheader game 2 follows game 1 on the same ticket,
+// so its start time is TBD until game 1 ends; a split doubleheader schedules each game separately.
+const (
+	DoubleheaderTraditional = "traditional"
+	DoubleheaderSplit       = "split"
+)
+
+// PostseasonRounds lists the postseason game types in the order they are played
+var PostseasonRounds = []string{
+	GameTypeWildCard,
+	GameTypeDivisionSeries,
+	GameTypeLeagueChampionshipSeries,
+	GameTypeWorldSeries,
+}
+
+// IsPostseason reports whether gameType is a postseason round
+func IsPostseason(gameType string) bool {
+	for _, round := range PostseasonRounds {
+		if gameType == round {
+			return true
+		}
+	}
+	return false
+}
+
 type Game struct {
 	GameId     string    `json:"game_id" dynamodbav:"gameId"`
 	Date       time.Time `json:"date" dynamodbav:"date"`
+	GameDay    string    `json:"game_day" dynamodbav:"gameDay"`
 	HomeTeam   string    `json:"home_team" dynamodbav:"homeTeam"`
 	HomeTeamId string    `json:"home_id" dynamodbav:"homeTeamId"`
 	AwayTeamId string    `json:"away_id" dynamodbav:"awayTeamId"`
@@ -13,4 +79,49 @@ type Game struct {
 	AwayScore  int       `json:"away_score" dynamodbav:"awayScore"`
 	Status     string    `json:"status" dynamodbav:"status"`
 	Winner     string    `json:"winner,omitempty" dynamodbav:"winner,omitempty"`
+	// Innings is how many innings were played, more than 9 for extra innings
+	Innings   int        `json:"innings,omitempty" dynamodbav:"innings,omitempty"`
+	LineScore *LineScore `json:"line_score,omitempty" dynamodbav:"lineScore,omitempty"`
+	// Season is the year of the season the game belongs to; GameType is one of the GameType constants
+	Season   int    `json:"season,omitempty" dynamodbav:"season,omitempty"`
+	GameType string `json:"game_type,omitempty" dynamodbav:"gameType,omitempty"`
+	// GameNumber is 2 for the second game of a doubleheader and 1 otherwise.
+	// Doubleheader is one of the Doubleheader constants, or empty for a single game.
+	GameNumber   int    `json:"game_number,omitempty" dynamodbav:"gameNumber,omitempty"`
+	Doubleheader string `json:"doubleheader,omitempty" dynamodbav:"doubleheader,omitempty"`
+	// StartTimeTBD is set while the start time isn't known; Date then holds a placeholder on the game day
+	StartTimeTBD bool `json:"start_time_tbd,omitempty" dynamodbav:"startTimeTBD,omitempty"`
+	// StartTimeZone is the venue's IANA time zone, so Date can be shown as the local scheduled start
+	StartTimeZone string `json:"start_time_zone,omitempty" dynamodbav:"startTimeZone,omitempty"`
+}
+
+// LocalStart is the scheduled start in the venue's time zone, or UTC when the zone is unknown
+func (g *Game) LocalStart() time.Time {
+	if g.StartTimeZone != "" {
+		if location, err := time.LoadLocation(g.StartTimeZone); err == nil {
+			return g.Date.In(location)
+		}
+	}
+	return g.Date.UTC()
+}
+
+// LineScore is a game's runs by inning with its run, hit and error totals
+type LineScore struct {
+	Innings []InningScore `json:"innings" dynamodbav:"innings"`
+	Home    LineTotals    `json:"home" dynamodbav:"home"`
+	Away    LineTotals    `json:"away" dynamodbav:"away"`
+}
+
+// InningScore is the runs each team scored in one inning.
+// Home is nil when the bottom half wasn't played.
+type InningScore struct {
+	Num  int  `json:"num" dynamodbav:"num"`
+	Home *int `json:"home" dynamodbav:"home"`
+	Away *int `json:"away" dynamodbav:"away"`
+}
+
+type LineTotals struct {
+	Runs   int `json:"runs" dynamodbav:"runs"`
+	Hits   int `json:"hits" dynamodbav:"hits"`
+	Errors int `json:"errors" dynamodbav:"errors"`
 }
@@ -5,6 +5,9 @@ import "time"
 type LeaderboardEntry struct {
 	UserId              string    `json:"user_id" dynamodbav:"userId"`
 	Username            string    `json:"username" dynamodbav:"username"`
+	ModelId             string    `json:"model_id,omitempty" dynamodbav:"modelId,omitempty"`
+	ModelName           string    `json:"model_name,omitempty" dynamodbav:"modelName,omitempty"`
+	Season              int       `json:"season,omitempty" dynamodbav:"season,omitempty"` // 0 for all-time standings
 	TotalWinnersCorrect int       `json:"total_winners_correct" dynamodbav:"totalWinnersCorrect"`
 	WinnerAccuracy      float32   `json:"winner_accuracy" dynamodbav:"winnerAccuracy"`
 	TeamScoreMse        float32   `json:"team_score_mse" dynamodbav:"teamScoreMse"`
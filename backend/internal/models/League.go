@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// League visibilities. Anyone can see and join a public league; a private league is only
+// seen by its members and is joined with its invite code.
+const (
+	LeagueVisibilityPrivate = "private"
+	LeagueVisibilityPublic  = "public"
+)
+
+// When members can see each other's predictions. Locked hides a pick until its game locks.
+const (
+	PredictionVisibilityLocked = "locked"
+	PredictionVisibilityOpen   = "open"
+)
+
+// Scoring profiles weigh the parts of the leaderboard score differently
+const (
+	// ScoringProfileStandard is the global pool's: winner accuracy 60%, team and total score error 20% each
+	ScoringProfileStandard = "standard"
+	// ScoringProfileWinners is a pick'em, scored on winner accuracy alone
+	ScoringProfileWinners = "winners"
+	// ScoringProfileScores favours predicting the score: winner accuracy 20%, team and total score error 40% each
+	ScoringProfileScores = "scores"
+)
+
+// League member roles. The owner manages the league's settings, invite code and members.
+const (
+	LeagueRoleOwner  = "owner"
+	LeagueRoleMember = "member"
+)
+
+// League is a private pool with its own members and standings
+type League struct {
+	LeagueId string `json:"league_id" dynamodbav:"leagueId"`
+	Name     string `json:"name" dynamodbav:"name"`
+	OwnerId  string `json:"owner_id" dynamodbav:"ownerId"`
+	// InviteCode lets someone join the league; it is only shown to the owner
+	InviteCode string         `json:"invite_code,omitempty" dynamodbav:"inviteCode"`
+	Settings   LeagueSettings `json:"settings" dynamodbav:"settings"`
+	CreatedAt  time.Time      `json:"created_at" dynamodbav:"createdAt"`
+}
+
+type LeagueSettings struct {
+	ScoringProfile string `json:"scoring_profile" dynamodbav:"scoringProfile"`
+	// GameTypes are the game types whose predictions count; empty counts every game
+	GameTypes            []string `json:"game_types,omitempty" dynamodbav:"gameTypes,omitempty"`
+	Visibility           string   `json:"visibility" dynamodbav:"visibility"`
+	PredictionVisibility string   `json:"prediction_visibility" dynamodbav:"predictionVisibility"`
+}
+
+type LeagueMember struct {
+	LeagueId string    `json:"league_id" dynamodbav:"leagueId"`
+	UserId   string    `json:"user_id" dynamodbav:"userId"`
+	Role     string    `json:"role" dynamodbav:"role"`
+	JoinedAt time.Time `json:"joined_at" dynamodbav:"joinedAt"`
+}
@@ -2,13 +2,38 @@ package models
 
 import "time"
 
+// Model statuses. Uploads start pending and are moved to validated or rejected once the file
+// has been inspected. One validated version per family can then be promoted to active.
+const (
+	ModelStatusPending   = "pending"
+	ModelStatusValidated = "validated"
+	ModelStatusRejected  = "rejected"
+	ModelStatusActive    = "active"
+)
+
 type ModelMetadata struct {
-	ModelId   string    `json:"model_id" dynamodbav:"modelId"`
-	ModelName string    `json:"model_name" dynamodbav:"modelName"`
-	UserId    string    `json:"user_id" dynamodbav:"userId"`
-	FileName  string    `json:"file_name" dynamodbav:"fileName"`
-	S3Key     string    `json:"s3_key" dynamodbav:"s3Key"`
-	Status    string    `json:"status" dynamodbav:"status"`
-	CreatedAt time.Time `json:"created_at" dynamodbav:"createdAt"`
-	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updatedAt"`
+	ModelId   string `json:"model_id" dynamodbav:"modelId"`
+	ModelName string `json:"model_name" dynamodbav:"modelName"`
+	UserId    string `json:"user_id" dynamodbav:"userId"`
+	FileName  string `json:"file_name" dynamodbav:"fileName"`
+	S3Key     string `json:"s3_key" dynamodbav:"s3Key"`
+	Status    string `json:"status" dynamodbav:"status"`
+	// StatusReason explains a rejection
+	StatusReason string `json:"status_reason,omitempty" dynamodbav:"statusReason,omitempty"`
+	Sha256       string `json:"sha256,omitempty" dynamodbav:"sha256,omitempty"`
+	SizeBytes    int64  `json:"size_bytes,omitempty" dynamodbav:"sizeBytes,omitempty"`
+	// ContentType is what the uploader sent the file as
+	ContentType string `json:"content_type,omitempty" dynamodbav:"contentType,omitempty"`
+	// Framework is ModelFrameworkPickle or ModelFrameworkONNX; empty on models uploaded before ONNX support, which are pickles
+	Framework string          `json:"framework,omitempty" dynamodbav:"framework,omitempty"`
+	Signature *ModelSignature `json:"signature,omitempty" dynamodbav:"signature,omitempty"`
+	// Features are the input columns the model declared at upload, all drawn from the feature schema
+	Features []string `json:"features,omitempty" dynamodbav:"features,omitempty"`
+	// Uploads from one user with the same ModelName form a family, numbered from version 1
+	Version   int    `json:"version" dynamodbav:"version"`
+	Changelog string `json:"changelog,omitempty" dynamodbav:"changelog,omitempty"`
+	// PromotedAt is when this version last became the family's active version
+	PromotedAt *time.Time `json:"promoted_at,omitempty" dynamodbav:"promotedAt,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" dynamodbav:"createdAt"`
+	UpdatedAt  time.Time  `json:"updated_at" dynamodbav:"updatedAt"`
 }
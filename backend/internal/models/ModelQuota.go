@@ -0,0 +1,19 @@
+package models
+
+// ModelQuotaLimits caps what one user can store. A zero limit means unlimited.
+type ModelQuotaLimits struct {
+	MaxActiveModels  int   `json:"max_active_models"`
+	MaxStorageBytes  int64 `json:"max_storage_bytes"`
+	MaxUploadsPerDay int   `json:"max_uploads_per_day"`
+}
+
+// ModelQuotaUsage is how much of their quota a user has used, measured from the models table
+// and the daily upload counts
+type ModelQuotaUsage struct {
+	ActiveModels int `json:"active_models"`
+	// StorageBytes counts every file that is stored or being uploaded; rejected files aren't kept
+	StorageBytes int64 `json:"storage_bytes"`
+	// UploadsToday counts uploads since midnight UTC, rejected and deleted ones included
+	UploadsToday int              `json:"uploads_today"`
+	Limits       ModelQuotaLimits `json:"limits"`
+}
@@ -0,0 +1,22 @@
+package models
+
+const (
+	ModelFrameworkPickle = "pickle"
+	ModelFrameworkONNX   = "onnx"
+)
+
+// TensorSpec describes one graph input or output. Dynamic dimensions are -1.
+type TensorSpec struct {
+	Name     string  `json:"name" dynamodbav:"name"`
+	ElemType string  `json:"elem_type" dynamodbav:"elemType"`
+	Shape    []int64 `json:"shape" dynamodbav:"shape"`
+}
+
+// ModelSignature is the input/output signature parsed from an ONNX graph
+type ModelSignature struct {
+	IrVersion    int64        `json:"ir_version" dynamodbav:"irVersion"`
+	OpsetVersion int64        `json:"opset_version" dynamodbav:"opsetVersion"`
+	ProducerName string       `json:"producer_name,omitempty" dynamodbav:"producerName,omitempty"`
+	Inputs       []TensorSpec `json:"inputs" dynamodbav:"inputs"`
+	Outputs      []TensorSpec `json:"outputs" dynamodbav:"outputs"`
+}
@@ -2,9 +2,13 @@ package models
 
 import "time"
 
+// Prediction is one entrant's pick for a game. ModelId is empty for picks a user
+// submits themselves and set when one of their uploaded models made the pick.
 type Prediction struct {
 	UserId              string    `json:"user_id"              dynamodbav:"userId"`
 	GameId              string    `json:"game_id"              dynamodbav:"gameId"`
+	ModelId             string    `json:"model_id,omitempty"   dynamodbav:"modelId,omitempty"`
+	PredictionKey       string    `json:"-"                    dynamodbav:"predictionKey"`
 	HomeScorePredicted  float32   `json:"home_score_predicted"  dynamodbav:"homeScorePredicted"`
 	AwayScorePredicted  float32   `json:"away_score_predicted"  dynamodbav:"awayScorePredicted"`
 	TotalScorePredicted float32   `json:"total_score_predicted" dynamodbav:"totalScorePredicted"`
@@ -16,4 +20,7 @@ type Prediction struct {
 	AwayScoreError      float32   `json:"away_score_error,omitempty" dynamodbav:"awayScoreError,omitempty"`
 	TotalScoreError     float32   `json:"total_score_error,omitempty" dynamodbav:"totalScoreError,omitempty"`
 	SubmittedAt         time.Time `json:"submitted_at"          dynamodbav:"submittedAt"`
+	// Season and GameType are copied from the game when the prediction is scored
+	Season   int    `json:"season,omitempty"    dynamodbav:"season,omitempty"`
+	GameType string `json:"game_type,omitempty" dynamodbav:"gameType,omitempty"`
 }
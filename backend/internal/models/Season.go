@@ -0,0 +1,62 @@
+package models
+
+// Season phases, in the order they are played
+const (
+	SeasonPhaseSpring     = "spring"
+	SeasonPhaseRegular    = "regular"
+	SeasonPhasePostseason = "postseason"
+)
+
+// Season is one MLB season's calendar. RoundWeights optionally scores postseason games by round,
+// keyed by GameType; a game without a weight counts once, like a regular season game.
+type Season struct {
+	Year         int                `json:"year" dynamodbav:"year"`
+	Phases       []SeasonPhase      `json:"phases" dynamodbav:"phases"`
+	RoundWeights map[string]float32 `json:"round_weights,omitempty" dynamodbav:"roundWeights,omitempty"`
+}
+
+// SeasonPhase is a named range of game days (YYYY-MM-DD, inclusive)
+type SeasonPhase struct {
+	Name  string `json:"name" dynamodbav:"name"`
+	Start string `json:"start" dynamodbav:"start"`
+	End   string `json:"end" dynamodbav:"end"`
+}
+
+// Start is the first day of the season's first phase
+func (s *Season) Start() string {
+	start := ""
+	for _, phase := range s.Phases {
+		if start == "" || phase.Start < start {
+			start = phase.Start
+		}
+	}
+	return start
+}
+
+// End is the last day of the season's last phase
+func (s *Season) End() string {
+	end := ""
+	for _, phase := range s.Phases {
+		if phase.End > end {
+			end = phase.End
+		}
+	}
+	return end
+}
+
+// Contains reports whether day (YYYY-MM-DD) falls within the season
+func (s *Season) Contains(day string) bool {
+	return len(s.Phases) > 0 && day >= s.Start() && day <= s.End()
+}
+
+// PhaseOn returns the name of the phase day falls in, or "" between phases and outside the season.
+// Phases can overlap, e.g. an overseas opening series during spring training; the later phase wins.
+func (s *Season) PhaseOn(day string) string {
+	name := ""
+	for _, phase := range s.Phases {
+		if day >= phase.Start && day <= phase.End {
+			name = phase.Name
+		}
+	}
+	return name
+}
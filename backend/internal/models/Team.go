@@ -0,0 +1,22 @@
+package models
+
+// Team is an MLB club, keyed by its Stats API team ID (the HomeTeamId/AwayTeamId on games)
+type Team struct {
+	Id           string `json:"id" dynamodbav:"teamId"`
+	Name         string `json:"name" dynamodbav:"name"`
+	Abbreviation string `json:"abbreviation" dynamodbav:"abbreviation"`
+	League       string `json:"league" dynamodbav:"league"`
+	Division     string `json:"division" dynamodbav:"division"`
+	// LogoURL is the club's cap logo on MLB's static CDN, an SVG
+	LogoURL string `json:"logo_url" dynamodbav:"logoUrl"`
+	Venue   Venue  `json:"venue" dynamodbav:"venue"`
+}
+
+// Venue is a team's home ballpark
+type Venue struct {
+	Id   string `json:"id" dynamodbav:"venueId"`
+	Name string `json:"name" dynamodbav:"name"`
+	City string `json:"city" dynamodbav:"city"`
+	// TimeZone is the IANA zone local start times are quoted in
+	TimeZone string `json:"time_zone" dynamodbav:"timeZone"`
+}
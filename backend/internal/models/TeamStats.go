@@ -0,0 +1,13 @@
+package models
+
+import "encoding/json"
+
+// TeamStatsSnapshot is one category of season-to-date team stats as captured on Date.
+// Each team's stats are passed through from the Stats API as-is, since the fields differ by category.
+type TeamStatsSnapshot struct {
+	Category string `json:"category"`
+	Date     string `json:"date"`
+	// AsOf is the date that was asked for; Date is the newest snapshot on or before it
+	AsOf  string                     `json:"as_of,omitempty"`
+	Teams map[string]json.RawMessage `json:"teams"`
+}
@@ -0,0 +1,78 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Just enough of the protobuf wire format to walk an ONNX ModelProto without generated code.
+// See https://protobuf.dev/programming-guides/encoding/
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// field is one decoded key/value from a message. Bytes is set for length-delimited
+// fields and Varint for varint fields; fixed-width values are skipped.
+type field struct {
+	Number int
+	Wire   int
+	Varint uint64
+	Bytes  []byte
+}
+
+// walkMessage calls fn for every field in a serialized message, in order
+func walkMessage(data []byte, fn func(f field) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncated
+		}
+		data = data[n:]
+
+		f := field{Number: int(key >> 3), Wire: int(key & 7)}
+		if f.Number == 0 {
+			return fmt.Errorf("invalid protobuf field number 0")
+		}
+
+		switch f.Wire {
+		case wireVarint:
+			f.Varint, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errTruncated
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return errTruncated
+			}
+			data = data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return errTruncated
+			}
+			data = data[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return errTruncated
+			}
+			f.Bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", f.Wire)
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
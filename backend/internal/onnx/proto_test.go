@@ -0,0 +1,125 @@
+package onnx
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalkMessage(t *testing.T) {
+	// 1: varint 150, 2: "hi", 3: fixed64, 4: fixed32, 5: varint 1
+	data := []byte{
+		0x08, 0x96, 0x01,
+		0x12, 0x02, 'h', 'i',
+		0x19, 1, 2, 3, 4, 5, 6, 7, 8,
+		0x25, 1, 2, 3, 4,
+		0x28, 0x01,
+	}
+
+	var fields []field
+	if err := walkMessage(data, func(f field) error {
+		fields = append(fields, f)
+		return nil
+	}); err != nil {
+		t.Fatalf("walkMessage() error = %v", err)
+	}
+
+	if len(fields) != 5 {
+		t.Fatalf("walkMessage() visited %d fields, want 5", len(fields))
+	}
+	if fields[0].Number != 1 || fields[0].Wire != wireVarint || fields[0].Varint != 150 {
+		t.Errorf("field 1 = %+v, want varint 150", fields[0])
+	}
+	if fields[1].Number != 2 || fields[1].Wire != wireBytes || string(fields[1].Bytes) != "hi" {
+		t.Errorf("field 2 = %+v, want bytes hi", fields[1])
+	}
+	if fields[2].Wire != wireFixed64 || fields[3].Wire != wireFixed32 {
+		t.Errorf("fields 3 and 4 = %+v, %+v, want fixed64 and fixed32", fields[2], fields[3])
+	}
+	if fields[4].Number != 5 || fields[4].Varint != 1 {
+		t.Errorf("field 5 = %+v, want varint 1", fields[4])
+	}
+}
+
+func TestWalkMessageRejectsMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"unterminated key":    {0x80},
+		"unterminated varint": {0x08, 0x96},
+		"short fixed64":       {0x09, 1, 2, 3},
+		"short fixed32":       {0x0d, 1, 2},
+		"length past end":     {0x12, 0x05, 'h', 'i'},
+		"huge length":         {0x12, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+		"field number zero":   {0x00, 0x01},
+		"group wire type":     {0x0b},
+	}
+
+	for name, data := range tests {
+		err := walkMessage(data, func(f field) error { return nil })
+		if err == nil {
+			t.Errorf("%s: walkMessage() error = nil", name)
+		}
+	}
+}
+
+func TestWalkMessageStopsOnCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := walkMessage([]byte{0x08, 0x01, 0x08, 0x02}, func(f field) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("walkMessage() = %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+// FuzzParseSignature feeds the parser arbitrary bytes, since it reads untrusted uploads.
+// It must never panic, and whatever it returns has to be either an error or a signature.
+func FuzzParseSignature(f *testing.F) {
+	fixtures, err := filepath.Glob("testdata/*.onnx")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, fixture := range fixtures {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{0x08, 0x08, 0x3a, 0x00})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		signature, err := ParseSignature(data)
+		if err != nil {
+			if !errors.Is(err, ErrNotONNX) {
+				t.Errorf("ParseSignature() error = %v, want ErrNotONNX", err)
+			}
+			return
+		}
+		if signature == nil {
+			t.Fatal("ParseSignature() returned neither a signature nor an error")
+		}
+		ValidateSignature(signature)
+	})
+}
+
+// FuzzWalkMessage checks that every field handed out lies within the message
+func FuzzWalkMessage(f *testing.F) {
+	f.Add([]byte{0x08, 0x96, 0x01, 0x12, 0x02, 'h', 'i'})
+	f.Add([]byte{0x12, 0xff, 0xff, 0xff, 0xff, 0x0f})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		walkMessage(data, func(fd field) error {
+			if fd.Number <= 0 {
+				t.Errorf("field number %d", fd.Number)
+			}
+			if fd.Bytes != nil && !bytes.Contains(data, fd.Bytes) {
+				t.Errorf("field %d bytes are not part of the message", fd.Number)
+			}
+			return nil
+		})
+	})
+}
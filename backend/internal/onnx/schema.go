@@ -0,0 +1,73 @@
+// Package onnx validates uploaded ONNX models against the pool's feature schema.
+//
+// An ONNX model must have exactly one input and at least one output:
+//
+//	input:  float32 [N, len(FeatureNames)]  one row per game, columns in FeatureNames order
+//	output: float32 [N, 3]                  home runs, away runs, home win probability
+//
+// N (the batch dimension) may be dynamic. Only the first output is read, so classifiers
+// that also emit labels are fine as long as the first output matches. Every feature must have
+// a value for every game; a run where one is missing fails instead of guessing.
+package onnx
+
+import (
+	"fmt"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// FeatureSchemaVersion is bumped whenever FeatureNames changes
+const FeatureSchemaVersion = 1
+
+// FeatureNames are the model input columns, in order
+var FeatureNames = []string{
+	"home_win_pct",
+	"away_win_pct",
+	"home_runs_scored_per_game",
+	"away_runs_scored_per_game",
+	"home_runs_allowed_per_game",
+	"away_runs_allowed_per_game",
+	"home_last10_win_pct",
+	"away_last10_win_pct",
+}
+
+// Output columns of the first graph output
+const (
+	OutputHomeScore = iota
+	OutputAwayScore
+	OutputHomeWinProbability
+	outputColumns
+)
+
+// ValidateSignature checks a parsed signature against the feature schema
+func ValidateSignature(signature *models.ModelSignature) error {
+	if len(signature.Inputs) != 1 {
+		return fmt.Errorf("model must have exactly 1 input, found %d", len(signature.Inputs))
+	}
+	if err := checkMatrix(signature.Inputs[0], int64(len(FeatureNames))); err != nil {
+		return fmt.Errorf("input %q: %w", signature.Inputs[0].Name, err)
+	}
+
+	if len(signature.Outputs) == 0 {
+		return fmt.Errorf("model has no outputs")
+	}
+	if err := checkMatrix(signature.Outputs[0], outputColumns); err != nil {
+		return fmt.Errorf("output %q: %w", signature.Outputs[0].Name, err)
+	}
+
+	return nil
+}
+
+// checkMatrix requires a float [N, columns] tensor
+func checkMatrix(spec models.TensorSpec, columns int64) error {
+	if spec.ElemType != "float" {
+		return fmt.Errorf("must be float, found %s", spec.ElemType)
+	}
+	if len(spec.Shape) != 2 {
+		return fmt.Errorf("must be rank 2 [N, %d], found rank %d", columns, len(spec.Shape))
+	}
+	if spec.Shape[1] != columns {
+		return fmt.Errorf("must have %d columns, found %d", columns, spec.Shape[1])
+	}
+	return nil
+}
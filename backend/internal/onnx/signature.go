@@ -0,0 +1,249 @@
+package onnx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+var ErrNotONNX = errors.New("file is not a valid ONNX model")
+
+// ONNX protobuf field numbers, from onnx/onnx.proto
+const (
+	modelIrVersion    = 1
+	modelProducerName = 2
+	modelGraph        = 7
+	modelOpsetImport  = 8
+
+	graphInitializer       = 5
+	graphInput             = 11
+	graphOutput            = 12
+	graphSparseInitializer = 15
+
+	tensorName       = 8
+	sparseTensorVals = 1
+
+	valueInfoName = 1
+	valueInfoType = 2
+
+	typeTensor     = 1
+	tensorElemType = 1
+	tensorShape    = 2
+	shapeDim       = 1
+	dimValue       = 1
+	opsetDomain    = 1
+	opsetVersion   = 2
+)
+
+// elemTypes maps TensorProto.DataType to a readable name
+var elemTypes = map[uint64]string{
+	1:  "float",
+	2:  "uint8",
+	3:  "int8",
+	4:  "uint16",
+	5:  "int16",
+	6:  "int32",
+	7:  "int64",
+	8:  "string",
+	9:  "bool",
+	10: "float16",
+	11: "double",
+	12: "uint32",
+	13: "uint64",
+	16: "bfloat16",
+}
+
+// ParseSignature reads the graph inputs and outputs from a serialized ONNX model.
+// Only the metadata is decoded; node and weight payloads are skipped.
+func ParseSignature(data []byte) (*models.ModelSignature, error) {
+	signature := &models.ModelSignature{}
+	var graph []byte
+
+	err := walkMessage(data, func(f field) error {
+		switch {
+		case f.Number == modelIrVersion && f.Wire == wireVarint:
+			signature.IrVersion = int64(f.Varint)
+		case f.Number == modelProducerName && f.Wire == wireBytes:
+			signature.ProducerName = string(f.Bytes)
+		case f.Number == modelGraph && f.Wire == wireBytes:
+			graph = f.Bytes
+		case f.Number == modelOpsetImport && f.Wire == wireBytes:
+			domain, version, err := parseOpset(f.Bytes)
+			if err != nil {
+				return err
+			}
+			// The default operator set is "" or its alias "ai.onnx"
+			if domain == "" || domain == "ai.onnx" {
+				signature.OpsetVersion = version
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotONNX, err)
+	}
+	if signature.IrVersion == 0 || graph == nil {
+		return nil, ErrNotONNX
+	}
+
+	if err := parseGraph(graph, signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotONNX, err)
+	}
+
+	return signature, nil
+}
+
+func parseOpset(data []byte) (string, int64, error) {
+	var domain string
+	var version int64
+
+	err := walkMessage(data, func(f field) error {
+		switch {
+		case f.Number == opsetDomain && f.Wire == wireBytes:
+			domain = string(f.Bytes)
+		case f.Number == opsetVersion && f.Wire == wireVarint:
+			version = int64(f.Varint)
+		}
+		return nil
+	})
+
+	return domain, version, err
+}
+
+func parseGraph(data []byte, signature *models.ModelSignature) error {
+	initializers := make(map[string]bool)
+	var inputs, outputs []models.TensorSpec
+
+	err := walkMessage(data, func(f field) error {
+		if f.Wire != wireBytes {
+			return nil
+		}
+
+		switch f.Number {
+		case graphInitializer:
+			name, err := stringField(f.Bytes, tensorName)
+			if err != nil {
+				return err
+			}
+			initializers[name] = true
+		case graphSparseInitializer:
+			values, err := bytesField(f.Bytes, sparseTensorVals)
+			if err != nil {
+				return err
+			}
+			name, err := stringField(values, tensorName)
+			if err != nil {
+				return err
+			}
+			initializers[name] = true
+		case graphInput, graphOutput:
+			spec, err := parseValueInfo(f.Bytes)
+			if err != nil {
+				return err
+			}
+			if f.Number == graphInput {
+				inputs = append(inputs, spec)
+			} else {
+				outputs = append(outputs, spec)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Before IR version 4 initializers were also listed as graph inputs; they aren't fed by the caller
+	signature.Inputs = make([]models.TensorSpec, 0, len(inputs))
+	for _, input := range inputs {
+		if !initializers[input.Name] {
+			signature.Inputs = append(signature.Inputs, input)
+		}
+	}
+	signature.Outputs = outputs
+
+	return nil
+}
+
+func parseValueInfo(data []byte) (models.TensorSpec, error) {
+	var spec models.TensorSpec
+	var typeProto []byte
+
+	err := walkMessage(data, func(f field) error {
+		switch {
+		case f.Number == valueInfoName && f.Wire == wireBytes:
+			spec.Name = string(f.Bytes)
+		case f.Number == valueInfoType && f.Wire == wireBytes:
+			typeProto = f.Bytes
+		}
+		return nil
+	})
+	if err != nil {
+		return spec, err
+	}
+
+	tensorType, err := bytesField(typeProto, typeTensor)
+	if err != nil {
+		return spec, err
+	}
+	if tensorType == nil {
+		// Sequences, maps and optionals are allowed in a graph but not by our schema
+		spec.ElemType = "non-tensor"
+		return spec, nil
+	}
+
+	spec.ElemType = "undefined"
+	spec.Shape = []int64{}
+	err = walkMessage(tensorType, func(f field) error {
+		switch {
+		case f.Number == tensorElemType && f.Wire == wireVarint:
+			if name, ok := elemTypes[f.Varint]; ok {
+				spec.ElemType = name
+			}
+		case f.Number == tensorShape && f.Wire == wireBytes:
+			return walkMessage(f.Bytes, func(dim field) error {
+				if dim.Number != shapeDim || dim.Wire != wireBytes {
+					return nil
+				}
+				size, err := dimSize(dim.Bytes)
+				if err != nil {
+					return err
+				}
+				spec.Shape = append(spec.Shape, size)
+				return nil
+			})
+		}
+		return nil
+	})
+
+	return spec, err
+}
+
+// dimSize returns a fixed dimension's size, or -1 for a symbolic or unknown one
+func dimSize(data []byte) (int64, error) {
+	size := int64(-1)
+	err := walkMessage(data, func(f field) error {
+		if f.Number == dimValue && f.Wire == wireVarint {
+			size = int64(f.Varint)
+		}
+		return nil
+	})
+	return size, err
+}
+
+func bytesField(data []byte, number int) ([]byte, error) {
+	var value []byte
+	err := walkMessage(data, func(f field) error {
+		if f.Number == number && f.Wire == wireBytes {
+			value = f.Bytes
+		}
+		return nil
+	})
+	return value, err
+}
+
+func stringField(data []byte, number int) (string, error) {
+	value, err := bytesField(data, number)
+	return string(value), err
+}
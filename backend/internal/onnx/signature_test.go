@@ -0,0 +1,113 @@
+package onnx
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+func loadModel(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to load model fixture: %v", err)
+	}
+	return data
+}
+
+func TestParseSignature(t *testing.T) {
+	signature, err := ParseSignature(loadModel(t, "linear.onnx"))
+	if err != nil {
+		t.Fatalf("ParseSignature() error = %v", err)
+	}
+
+	want := &models.ModelSignature{
+		IrVersion:    8,
+		OpsetVersion: 17,
+		ProducerName: "mlb-pool-tests",
+		Inputs:       []models.TensorSpec{{Name: "features", ElemType: "float", Shape: []int64{-1, 8}}},
+		Outputs:      []models.TensorSpec{{Name: "scores", ElemType: "float", Shape: []int64{-1, 3}}},
+	}
+	if !reflect.DeepEqual(signature, want) {
+		t.Errorf("ParseSignature() = %+v, want %+v", signature, want)
+	}
+
+	if err := ValidateSignature(signature); err != nil {
+		t.Errorf("ValidateSignature() error = %v", err)
+	}
+}
+
+func TestParseSignatureSkipsInitializerInputs(t *testing.T) {
+	signature, err := ParseSignature(loadModel(t, "legacy_initializer_input.onnx"))
+	if err != nil {
+		t.Fatalf("ParseSignature() error = %v", err)
+	}
+
+	if signature.IrVersion != 3 || signature.OpsetVersion != 8 {
+		t.Errorf("IrVersion, OpsetVersion = %d, %d, want 3, 8", signature.IrVersion, signature.OpsetVersion)
+	}
+	if len(signature.Inputs) != 1 || signature.Inputs[0].Name != "features" {
+		t.Errorf("Inputs = %+v, want only features", signature.Inputs)
+	}
+	if len(signature.Outputs) != 2 || signature.Outputs[1].ElemType != "int64" {
+		t.Errorf("Outputs = %+v, want scores then an int64 label", signature.Outputs)
+	}
+
+	// Only the first output has to match the schema
+	if err := ValidateSignature(signature); err != nil {
+		t.Errorf("ValidateSignature() error = %v", err)
+	}
+}
+
+func TestValidateSignatureRejectsOtherSchemas(t *testing.T) {
+	signature, err := ParseSignature(loadModel(t, "wrong_features.onnx"))
+	if err != nil {
+		t.Fatalf("ParseSignature() error = %v", err)
+	}
+	if err := ValidateSignature(signature); err == nil {
+		t.Error("ValidateSignature() accepted a model with 5 input columns")
+	}
+
+	tests := map[string]*models.ModelSignature{
+		"no inputs": {
+			Outputs: []models.TensorSpec{{Name: "y", ElemType: "float", Shape: []int64{-1, 3}}},
+		},
+		"double input": {
+			Inputs:  []models.TensorSpec{{Name: "x", ElemType: "double", Shape: []int64{-1, 8}}},
+			Outputs: []models.TensorSpec{{Name: "y", ElemType: "float", Shape: []int64{-1, 3}}},
+		},
+		"rank 1 output": {
+			Inputs:  []models.TensorSpec{{Name: "x", ElemType: "float", Shape: []int64{-1, 8}}},
+			Outputs: []models.TensorSpec{{Name: "y", ElemType: "float", Shape: []int64{-1}}},
+		},
+		"no outputs": {
+			Inputs: []models.TensorSpec{{Name: "x", ElemType: "float", Shape: []int64{-1, 8}}},
+		},
+	}
+	for name, signature := range tests {
+		if err := ValidateSignature(signature); err == nil {
+			t.Errorf("%s: ValidateSignature() error = nil", name)
+		}
+	}
+}
+
+func TestParseSignatureRejectsOtherFiles(t *testing.T) {
+	linear := loadModel(t, "linear.onnx")
+
+	tests := map[string][]byte{
+		"empty":     {},
+		"json":      []byte(`{"model": "linear"}`),
+		"pickle":    {0x80, 0x04, 0x95, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, '.'},
+		"truncated": linear[:len(linear)/2],
+		// A model with no graph
+		"no graph": linear[:2],
+	}
+	for name, data := range tests {
+		if _, err := ParseSignature(data); !errors.Is(err, ErrNotONNX) {
+			t.Errorf("%s: ParseSignature() error = %v, want ErrNotONNX", name, err)
+		}
+	}
+}
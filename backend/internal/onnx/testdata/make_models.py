@@ -0,0 +1,95 @@
+#!/usr/bin/env python3
+"""Writes the ONNX fixtures in this directory.
+
+The protobuf is encoded by hand, following onnx/onnx.proto, so the fixtures can be
+regenerated without the onnx package. Each model is a single MatMul of the input
+against a weight initializer, which onnxruntime loads and runs as-is.
+
+    python3 make_models.py
+"""
+import struct
+
+FLOAT = 1
+INT64 = 7
+
+
+def varint(n):
+    out = b""
+    while True:
+        byte = n & 0x7F
+        n >>= 7
+        if n:
+            out += bytes([byte | 0x80])
+        else:
+            return out + bytes([byte])
+
+
+def field_varint(number, value):
+    return varint(number << 3) + varint(value)
+
+
+def field_bytes(number, value):
+    if isinstance(value, str):
+        value = value.encode()
+    return varint(number << 3 | 2) + varint(len(value)) + value
+
+
+def dim(size):
+    # Dimension: dim_value = 1, dim_param = 2
+    if isinstance(size, str):
+        return field_bytes(1, field_bytes(2, size))
+    return field_bytes(1, field_varint(1, size))
+
+
+def value_info(name, elem_type, shape):
+    # ValueInfoProto { name = 1, type = 2 }, TypeProto { tensor_type = 1 },
+    # TypeProto.Tensor { elem_type = 1, shape = 2 }, TensorShapeProto { dim = 1 }
+    tensor = field_varint(1, elem_type) + field_bytes(2, b"".join(dim(d) for d in shape))
+    return field_bytes(1, name) + field_bytes(2, field_bytes(1, tensor))
+
+
+def initializer(name, rows, columns):
+    # TensorProto { dims = 1, data_type = 2, name = 8, raw_data = 9 }
+    weights = [0.1 * (r + 1) * (c + 1) for r in range(rows) for c in range(columns)]
+    return (
+        field_varint(1, rows)
+        + field_varint(1, columns)
+        + field_varint(2, FLOAT)
+        + field_bytes(8, name)
+        + field_bytes(9, struct.pack("<%df" % len(weights), *weights))
+    )
+
+
+def matmul_model(path, features, outputs, ir_version=8, weights_as_input=False, extra_outputs=b""):
+    # NodeProto { input = 1, output = 2, name = 3, op_type = 4 }
+    node = field_bytes(1, "features") + field_bytes(1, "weights") + field_bytes(2, "scores") + field_bytes(3, "predict") + field_bytes(4, "MatMul")
+
+    # GraphProto { node = 1, name = 2, initializer = 5, input = 11, output = 12 }
+    graph = field_bytes(1, node) + field_bytes(2, "pool_model") + field_bytes(5, initializer("weights", features, outputs))
+    graph += field_bytes(11, value_info("features", FLOAT, ["N", features]))
+    if weights_as_input:
+        graph += field_bytes(11, value_info("weights", FLOAT, [features, outputs]))
+    graph += field_bytes(12, value_info("scores", FLOAT, ["N", outputs]))
+    graph += extra_outputs
+
+    # ModelProto { ir_version = 1, producer_name = 2, graph = 7, opset_import = 8 },
+    # OperatorSetIdProto { domain = 1, version = 2 }
+    model = field_varint(1, ir_version) + field_bytes(2, "mlb-pool-tests")
+    model += field_bytes(7, graph)
+    model += field_bytes(8, field_bytes(1, "") + field_varint(2, 17 if ir_version >= 8 else 8))
+    model += field_bytes(8, field_bytes(1, "ai.onnx.ml") + field_varint(2, 3))
+
+    with open(path, "wb") as f:
+        f.write(model)
+
+
+if __name__ == "__main__":
+    # Matches the feature schema: 8 features in, home runs, away runs and home win probability out
+    matmul_model("linear.onnx", 8, 3)
+    # IR version 3 lists initializers as inputs too, and a label output follows the scores
+    matmul_model(
+        "legacy_initializer_input.onnx", 8, 3, ir_version=3, weights_as_input=True,
+        extra_outputs=field_bytes(12, value_info("label", INT64, ["N"])),
+    )
+    # Trained on an older, five-feature schema
+    matmul_model("wrong_features.onnx", 5, 3)
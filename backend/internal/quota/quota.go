@@ -0,0 +1,137 @@
+// Package quota enforces per-user limits on uploaded models.
+//
+// Active models and storage are recomputed from the user's model records, so deleting a model
+// frees them straight away. Uploads are counted per day in an append-only counter instead, so
+// deleting a model doesn't hand an upload back.
+package quota
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+// Error codes returned to clients for over-quota attempts
+const (
+	CodeActiveModels = "quota_active_models"
+	CodeStorage      = "quota_storage_bytes"
+	CodeUploadRate   = "rate_limit_uploads"
+)
+
+// Defaults used when the environment doesn't set a limit
+const (
+	defaultMaxActiveModels  = 5
+	defaultMaxStorageBytes  = 2 * 1024 * 1024 * 1024
+	defaultMaxUploadsPerDay = 20
+)
+
+// Error is an over-quota attempt. RateLimited distinguishes the upload rate limit from the
+// storage quotas, so the caller can answer 429 instead of 403.
+type Error struct {
+	Code        string
+	Message     string
+	RateLimited bool
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// LimitsFromEnv reads MODEL_QUOTA_MAX_ACTIVE, MODEL_QUOTA_MAX_BYTES and MODEL_QUOTA_UPLOADS_PER_DAY.
+// Set a limit to 0 to disable it.
+func LimitsFromEnv() (models.ModelQuotaLimits, error) {
+	var limits models.ModelQuotaLimits
+	var err error
+
+	if limits.MaxActiveModels, err = intEnv("MODEL_QUOTA_MAX_ACTIVE", defaultMaxActiveModels); err != nil {
+		return limits, err
+	}
+	maxBytes, err := intEnv("MODEL_QUOTA_MAX_BYTES", defaultMaxStorageBytes)
+	if err != nil {
+		return limits, err
+	}
+	limits.MaxStorageBytes = int64(maxBytes)
+	if limits.MaxUploadsPerDay, err = intEnv("MODEL_QUOTA_UPLOADS_PER_DAY", defaultMaxUploadsPerDay); err != nil {
+		return limits, err
+	}
+
+	return limits, nil
+}
+
+func intEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return parsed, nil
+}
+
+// UploadDay is the day an upload made at now counts against. Days run midnight to midnight UTC.
+func UploadDay(now time.Time) string {
+	return now.UTC().Format(models.GameDayLayout)
+}
+
+// Usage measures a user's quota from all of their model records and the uploads counted for today
+func Usage(userModels []*models.ModelMetadata, uploadsToday int, limits models.ModelQuotaLimits) models.ModelQuotaUsage {
+	usage := models.ModelQuotaUsage{UploadsToday: uploadsToday, Limits: limits}
+
+	for _, model := range userModels {
+		if model.Status == models.ModelStatusActive {
+			usage.ActiveModels++
+		}
+		if model.Status != models.ModelStatusRejected {
+			usage.StorageBytes += model.SizeBytes
+		}
+	}
+
+	return usage
+}
+
+// CheckUpload returns an *Error if an upload of size bytes would go over quota.
+// newActive is whether the upload starts a model family and so would become active.
+func CheckUpload(usage models.ModelQuotaUsage, size int64, newActive bool) error {
+	limits := usage.Limits
+
+	if limits.MaxUploadsPerDay > 0 && usage.UploadsToday >= limits.MaxUploadsPerDay {
+		return UploadRateError(limits)
+	}
+	if limits.MaxStorageBytes > 0 && usage.StorageBytes+size > limits.MaxStorageBytes {
+		return &Error{
+			Code: CodeStorage,
+			Message: fmt.Sprintf("Storage quota exceeded: %d of %d bytes used, this upload needs %d",
+				usage.StorageBytes, limits.MaxStorageBytes, size),
+		}
+	}
+	if newActive {
+		return CheckActivation(usage)
+	}
+
+	return nil
+}
+
+// UploadRateError is the *Error for an upload over the daily limit
+func UploadRateError(limits models.ModelQuotaLimits) *Error {
+	return &Error{
+		Code:        CodeUploadRate,
+		Message:     fmt.Sprintf("Upload limit reached: at most %d uploads per day", limits.MaxUploadsPerDay),
+		RateLimited: true,
+	}
+}
+
+// CheckActivation returns an *Error if one more active model would go over quota
+func CheckActivation(usage models.ModelQuotaUsage) error {
+	if usage.Limits.MaxActiveModels > 0 && usage.ActiveModels >= usage.Limits.MaxActiveModels {
+		return &Error{
+			Code:    CodeActiveModels,
+			Message: fmt.Sprintf("Active model limit reached: at most %d active models", usage.Limits.MaxActiveModels),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+var testLimits = models.ModelQuotaLimits{MaxActiveModels: 2, MaxStorageBytes: 1000, MaxUploadsPerDay: 3}
+
+func TestUsage(t *testing.T) {
+	tests := []struct {
+		name         string
+		models       []*models.ModelMetadata
+		uploadsToday int
+		want         models.ModelQuotaUsage
+	}{
+		{
+			name: "no models",
+			want: models.ModelQuotaUsage{Limits: testLimits},
+		},
+		{
+			name: "active, validated, pending and rejected",
+			models: []*models.ModelMetadata{
+				{Status: models.ModelStatusActive, SizeBytes: 100},
+				{Status: models.ModelStatusActive, SizeBytes: 200},
+				{Status: models.ModelStatusValidated, SizeBytes: 50},
+				{Status: models.ModelStatusPending, SizeBytes: 25},
+				// Rejected files aren't kept, so they don't use storage
+				{Status: models.ModelStatusRejected, SizeBytes: 400},
+			},
+			uploadsToday: 2,
+			want:         models.ModelQuotaUsage{ActiveModels: 2, StorageBytes: 375, UploadsToday: 2, Limits: testLimits},
+		},
+		{
+			// Every model from today was deleted, but the uploads still count
+			name:         "uploads outlive deleted models",
+			uploadsToday: 3,
+			want:         models.ModelQuotaUsage{UploadsToday: 3, Limits: testLimits},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Usage(tt.models, tt.uploadsToday, testLimits); got != tt.want {
+			t.Errorf("%s: Usage() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckUpload(t *testing.T) {
+	tests := []struct {
+		name      string
+		usage     models.ModelQuotaUsage
+		size      int64
+		newActive bool
+		wantCode  string
+	}{
+		{name: "within every limit", usage: models.ModelQuotaUsage{ActiveModels: 1, StorageBytes: 500, UploadsToday: 2}, size: 500, newActive: true},
+		{name: "daily uploads used up", usage: models.ModelQuotaUsage{UploadsToday: 3}, size: 1, wantCode: CodeUploadRate},
+		{name: "storage would overflow", usage: models.ModelQuotaUsage{StorageBytes: 900}, size: 101, wantCode: CodeStorage},
+		{name: "storage exactly full", usage: models.ModelQuotaUsage{StorageBytes: 900}, size: 100},
+		{name: "new family over the active limit", usage: models.ModelQuotaUsage{ActiveModels: 2}, size: 1, newActive: true, wantCode: CodeActiveModels},
+		// A new version of an existing family doesn't become active on its own
+		{name: "new version at the active limit", usage: models.ModelQuotaUsage{ActiveModels: 2}, size: 1},
+		// The rate limit is checked first
+		{name: "over several limits", usage: models.ModelQuotaUsage{ActiveModels: 2, StorageBytes: 1000, UploadsToday: 3}, size: 1, newActive: true, wantCode: CodeUploadRate},
+	}
+
+	for _, tt := range tests {
+		tt.usage.Limits = testLimits
+		err := CheckUpload(tt.usage, tt.size, tt.newActive)
+		checkQuotaError(t, tt.name, err, tt.wantCode)
+	}
+
+	if err := CheckUpload(models.ModelQuotaUsage{ActiveModels: 50, StorageBytes: 1 << 40, UploadsToday: 500}, 1<<30, true); err != nil {
+		t.Errorf("zero limits: CheckUpload() error = %v, want no limits applied", err)
+	}
+}
+
+func TestCheckActivation(t *testing.T) {
+	tests := []struct {
+		name     string
+		usage    models.ModelQuotaUsage
+		wantCode string
+	}{
+		{name: "below the limit", usage: models.ModelQuotaUsage{ActiveModels: 1, Limits: testLimits}},
+		{name: "at the limit", usage: models.ModelQuotaUsage{ActiveModels: 2, Limits: testLimits}, wantCode: CodeActiveModels},
+		{name: "unlimited", usage: models.ModelQuotaUsage{ActiveModels: 10}},
+	}
+
+	for _, tt := range tests {
+		checkQuotaError(t, tt.name, CheckActivation(tt.usage), tt.wantCode)
+	}
+}
+
+func TestUploadDay(t *testing.T) {
+	// Late evening in New York is already the next day in UTC
+	newYork := time.FixedZone("EDT", -4*60*60)
+	if got := UploadDay(time.Date(2025, 6, 10, 22, 30, 0, 0, newYork)); got != "2025-06-11" {
+		t.Errorf("UploadDay() = %q, want 2025-06-11", got)
+	}
+}
+
+// checkQuotaError checks err is an *Error with wantCode, or nil when wantCode is empty
+func checkQuotaError(t *testing.T, name string, err error, wantCode string) {
+	t.Helper()
+	if wantCode == "" {
+		if err != nil {
+			t.Errorf("%s: error = %v, want nil", name, err)
+		}
+		return
+	}
+
+	var quotaErr *Error
+	if !errors.As(err, &quotaErr) {
+		t.Errorf("%s: error = %v, want a quota error with code %s", name, err, wantCode)
+		return
+	}
+	if quotaErr.Code != wantCode {
+		t.Errorf("%s: code = %s, want %s", name, quotaErr.Code, wantCode)
+	}
+	if quotaErr.RateLimited != (wantCode == CodeUploadRate) {
+		t.Errorf("%s: RateLimited = %v for %s", name, quotaErr.RateLimited, wantCode)
+	}
+}
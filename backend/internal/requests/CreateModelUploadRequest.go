@@ -0,0 +1,12 @@
+package requests
+
+type CreateModelUploadRequest struct {
+	ModelName string `json:"model_name"`
+	FileName  string `json:"file_name"`
+	SizeBytes int64  `json:"size_bytes"`
+	Sha256    string `json:"sha256"`
+	Changelog string `json:"changelog"`
+	// ContentType and Features are optional
+	ContentType string   `json:"content_type"`
+	Features    []string `json:"features"`
+}
@@ -3,6 +3,7 @@ package requests
 type SubmitPredictionRequest struct {
 	UserId              string  `json:"user_id"`
 	GameId              string  `json:"game_id"`
+	ModelId             string  `json:"model_id"`
 	HomeScorePredicted  float32 `json:"home_score_predicted"`
 	AwayScorePredicted  float32 `json:"away_score_predicted"`
 	TotalScorePredicted float32 `json:"total_score_predicted"`
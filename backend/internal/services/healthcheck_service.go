@@ -7,10 +7,10 @@ import (
 )
 
 type HealthcheckService struct {
-	db *database.DB
+	db database.Store
 }
 
-func NewHealthcheckService(db *database.DB) *HealthcheckService {
+func NewHealthcheckService(db database.Store) *HealthcheckService {
 	return &HealthcheckService{db: db}
 }
 
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+	"github.com/bendemouth/mlb-prediction-pool/internal/validation"
+)
+
+// uploadQueueSize is how many completed uploads can wait for inspection before new ones
+// are left pending for the reconcile job instead
+const uploadQueueSize = 64
+
+// inspectTimeout bounds reading and inspecting one stored model file
+const inspectTimeout = 10 * time.Minute
+
+// ErrModelSettled is returned when a model was already inspected by the time its turn came
+var ErrModelSettled = errors.New("model is no longer pending")
+
+// queuedModel identifies a model waiting for inspection
+type queuedModel struct {
+	modelId string
+	userId  string
+}
+
+// ModelUploadService inspects model files that were uploaded straight to storage and
+// settles their pending records. Inspection reads the whole file, so it runs in the
+// background rather than inside the request that completed the upload.
+type ModelUploadService struct {
+	db    database.ModelStore
+	blobs storage.BlobStore
+	queue chan queuedModel
+}
+
+func NewModelUploadService(db database.ModelStore, blobs storage.BlobStore) *ModelUploadService {
+	return &ModelUploadService{db: db, blobs: blobs, queue: make(chan queuedModel, uploadQueueSize)}
+}
+
+// Enqueue hands a pending model to Run. It returns false when the queue is full,
+// in which case the model stays pending until the reconcile job inspects it.
+func (service *ModelUploadService) Enqueue(model *models.ModelMetadata) bool {
+	select {
+	case service.queue <- queuedModel{modelId: model.ModelId, userId: model.UserId}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run inspects queued models one at a time until ctx is cancelled.
+// A model whose inspection is cut short stays pending for the reconcile job.
+func (service *ModelUploadService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case queued := <-service.queue:
+			inspectCtx, cancel := context.WithTimeout(ctx, inspectTimeout)
+			report, err := service.InspectStored(inspectCtx, queued.modelId, queued.userId)
+			cancel()
+			if err != nil {
+				if !errors.Is(err, ErrModelSettled) {
+					log.Printf("Failed to inspect model %s: %v", queued.modelId, err)
+				}
+				continue
+			}
+			log.Printf("Inspected model %s: %s %s", queued.modelId, report.Status(), report.Reason)
+		}
+	}
+}
+
+// InspectStored checks a pending model's stored file against what was declared, runs the
+// same checks as a direct upload and settles the model. Failed checks are recorded on the
+// model; the error is for storage and database failures, which leave it pending, or
+// ErrModelSettled when the model isn't pending any more.
+func (service *ModelUploadService) InspectStored(ctx context.Context, modelId string, userId string) (validation.Report, error) {
+	// Re-read the record so a model completed twice, or also picked up by the reconcile job, is only settled once
+	model, err := service.db.GetModelById(ctx, modelId, userId)
+	if err != nil {
+		return validation.Report{}, fmt.Errorf("failed to look up model: %w", err)
+	}
+	if model.Status != models.ModelStatusPending {
+		return validation.Report{}, ErrModelSettled
+	}
+
+	object, err := service.blobs.Head(ctx, model.S3Key)
+	if err != nil {
+		return validation.Report{}, fmt.Errorf("failed to check uploaded file: %w", err)
+	}
+
+	// Models sent back by Reinspect were stored before uploads declared a size and checksum,
+	// so only their contents are checked
+	declared := model.Sha256 != ""
+
+	report := validation.Report{Framework: model.Framework, Sha256: model.Sha256, Size: object.Size}
+	switch {
+	case declared && object.Size != model.SizeBytes:
+		report.Reason = fmt.Sprintf("uploaded file is %d bytes, %d were declared", object.Size, model.SizeBytes)
+	case declared && !checksumMatches(object.ChecksumSHA256, model.Sha256):
+		report.Reason = "uploaded file does not match the declared checksum"
+	default:
+		// The presigned PUT pins size and checksum, but the contents still need the same checks as a direct upload
+		body, err := service.blobs.Get(ctx, model.S3Key)
+		if err != nil {
+			return validation.Report{}, fmt.Errorf("failed to read uploaded file: %w", err)
+		}
+		report, err = validation.Inspect(body, model.FileName)
+		body.Close()
+		if err != nil {
+			return validation.Report{}, err
+		}
+	}
+
+	// Rejected files aren't kept in storage, same as for direct uploads
+	if !report.Passed() {
+		if err := service.blobs.Delete(ctx, model.S3Key); err != nil {
+			return validation.Report{}, fmt.Errorf("failed to remove rejected file: %w", err)
+		}
+		model.S3Key = ""
+	}
+
+	model.Framework = report.Framework
+	model.Signature = report.Signature
+	model.SizeBytes = report.Size
+	if report.Sha256 != "" {
+		model.Sha256 = report.Sha256
+	}
+	model.UpdatedAt = time.Now()
+	if err := service.db.UpdateModel(ctx, model); err != nil {
+		return validation.Report{}, fmt.Errorf("failed to save model metadata: %w", err)
+	}
+
+	versions, err := service.db.GetModelVersions(ctx, model.UserId, model.ModelName)
+	if err != nil {
+		return validation.Report{}, fmt.Errorf("failed to look up model versions: %w", err)
+	}
+
+	return report, service.Settle(ctx, model, report, versions)
+}
+
+// Reinspect sends a model that was made active or validated without being inspected, as every
+// upload was before inspection existed, back to pending and inspects its stored file
+func (service *ModelUploadService) Reinspect(ctx context.Context, modelId string, userId string) (validation.Report, error) {
+	if err := service.db.UpdateModelStatus(ctx, modelId, userId, models.ModelStatusPending, ""); err != nil {
+		return validation.Report{}, fmt.Errorf("failed to mark model pending: %w", err)
+	}
+	return service.InspectStored(ctx, modelId, userId)
+}
+
+// Uninspected reports whether a model was settled without being inspected. Inspection always
+// records the file's checksum.
+func Uninspected(model *models.ModelMetadata) bool {
+	return model.Status != models.ModelStatusPending && model.Status != models.ModelStatusRejected && model.Sha256 == ""
+}
+
+// Settle moves a pending model to validated or rejected from its inspection report.
+// The first runnable version of a family is promoted straight away; later ones wait for an explicit promotion.
+func (service *ModelUploadService) Settle(ctx context.Context, model *models.ModelMetadata, report validation.Report, versions []*models.ModelMetadata) error {
+	if err := service.db.UpdateModelStatus(ctx, model.ModelId, model.UserId, report.Status(), report.Reason); err != nil {
+		return fmt.Errorf("failed to update model status: %w", err)
+	}
+	model.Status = report.Status()
+	model.StatusReason = report.Reason
+
+	if report.Passed() && !HasActiveVersion(versions) {
+		if err := service.db.PromoteModel(ctx, model.ModelId, model.UserId); err != nil {
+			return fmt.Errorf("failed to promote model: %w", err)
+		}
+		model.Status = models.ModelStatusActive
+	}
+
+	return nil
+}
+
+// HasActiveVersion reports whether a model family has an active version. A family without one
+// has its first version that passes inspection promoted.
+func HasActiveVersion(versions []*models.ModelMetadata) bool {
+	for _, version := range versions {
+		if version.Status == models.ModelStatusActive {
+			return true
+		}
+	}
+	return false
+}
+
+// checksumMatches compares the store's base64 checksum with the hex digest the client declared
+func checksumMatches(stored string, declared string) bool {
+	digest, err := base64.StdEncoding.DecodeString(stored)
+	return err == nil && hex.EncodeToString(digest) == strings.ToLower(declared)
+}
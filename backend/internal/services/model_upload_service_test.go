@@ -0,0 +1,148 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+// uploadModel stores data as a pending model's file the way a presigned PUT would leave it
+func uploadModel(t *testing.T, db *database.MemoryDB, blobs *storage.LocalStore, modelId string, data []byte) *models.ModelMetadata {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	model := &models.ModelMetadata{
+		ModelId:   modelId,
+		ModelName: "picker",
+		UserId:    "u1",
+		FileName:  "model.pkl",
+		S3Key:     "models/u1/1/" + modelId + ".pkl",
+		Status:    models.ModelStatusPending,
+		Framework: models.ModelFrameworkPickle,
+		Sha256:    hex.EncodeToString(sum[:]),
+		SizeBytes: int64(len(data)),
+		Version:   1,
+		CreatedAt: time.Now(),
+	}
+	if err := db.CreateModel(context.Background(), model); err != nil {
+		t.Fatal(err)
+	}
+	if err := blobs.Put(context.Background(), model.S3Key, bytes.NewReader(data), "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func newUploadService(t *testing.T) (*ModelUploadService, *database.MemoryDB, *storage.LocalStore) {
+	t.Helper()
+	db := database.NewMemoryDB()
+	blobs, err := storage.NewLocalStore(t.TempDir(), "http://localhost", []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewModelUploadService(db, blobs), db, blobs
+}
+
+func TestInspectStoredPromotesFirstVersion(t *testing.T) {
+	ctx := context.Background()
+	service, db, blobs := newUploadService(t)
+	// PROTO 4, BININT1 1, STOP
+	model := uploadModel(t, db, blobs, "m1", []byte{0x80, 0x04, 'K', 0x01, '.'})
+
+	report, err := service.InspectStored(ctx, model.ModelId, model.UserId)
+	if err != nil {
+		t.Fatalf("InspectStored() error = %v", err)
+	}
+	if !report.Passed() {
+		t.Fatalf("InspectStored() = %+v, want a passing report", report)
+	}
+
+	stored, err := db.GetModelById(ctx, model.ModelId, model.UserId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != models.ModelStatusActive {
+		t.Errorf("status = %s, want the first version promoted to active", stored.Status)
+	}
+
+	// A second completion of the same upload leaves the settled model alone
+	if _, err := service.InspectStored(ctx, model.ModelId, model.UserId); !errors.Is(err, ErrModelSettled) {
+		t.Errorf("InspectStored() again error = %v, want ErrModelSettled", err)
+	}
+}
+
+func TestInspectStoredRejectsAndRemovesFile(t *testing.T) {
+	ctx := context.Background()
+	service, db, blobs := newUploadService(t)
+	model := uploadModel(t, db, blobs, "m1", []byte("not a pickle"))
+
+	report, err := service.InspectStored(ctx, model.ModelId, model.UserId)
+	if err != nil {
+		t.Fatalf("InspectStored() error = %v", err)
+	}
+	if report.Passed() {
+		t.Fatal("InspectStored() passed a text file")
+	}
+
+	stored, err := db.GetModelById(ctx, model.ModelId, model.UserId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != models.ModelStatusRejected || stored.StatusReason == "" || stored.S3Key != "" {
+		t.Errorf("model = %+v, want it rejected with a reason and no file", stored)
+	}
+	if _, err := blobs.Head(ctx, model.S3Key); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("Head() error = %v, want the rejected file removed", err)
+	}
+}
+
+func TestInspectStoredRejectsChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	service, db, blobs := newUploadService(t)
+	model := uploadModel(t, db, blobs, "m1", []byte{0x80, 0x04, 'K', 0x01, '.'})
+	model.Sha256 = hex.EncodeToString(make([]byte, 32))
+	if err := db.UpdateModel(ctx, model); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := service.InspectStored(ctx, model.ModelId, model.UserId)
+	if err != nil {
+		t.Fatalf("InspectStored() error = %v", err)
+	}
+	if report.Reason != "uploaded file does not match the declared checksum" {
+		t.Errorf("InspectStored() reason = %q, want the checksum mismatch", report.Reason)
+	}
+}
+
+func TestRunInspectsQueuedModels(t *testing.T) {
+	service, db, blobs := newUploadService(t)
+	model := uploadModel(t, db, blobs, "m1", []byte{0x80, 0x04, 'K', 0x01, '.'})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Run(ctx)
+
+	if !service.Enqueue(model) {
+		t.Fatal("Enqueue() = false on an empty queue")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		stored, err := db.GetModelById(context.Background(), model.ModelId, model.UserId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != models.ModelStatusPending {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("queued model was never inspected")
+}
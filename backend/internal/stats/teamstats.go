@@ -0,0 +1,132 @@
+// Package stats serves the team stat snapshots the ingestion Lambda writes to the data bucket,
+// for model authors building features
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+// Categories are the stat groups the Lambda snapshots
+var Categories = []string{"hitting", "pitching", "fielding", "catching"}
+
+var ErrUnknownCategory = errors.New("unknown stats category")
+var ErrNoSnapshot = errors.New("no team stats snapshot")
+
+// TeamStats reads snapshots stored as team-stats/{category}/{YYYY-MM-DD}.json.
+// The Lambda also keeps a latest.json copy, which is ignored so every response carries its date.
+type TeamStats struct {
+	blobs storage.BlobStore
+}
+
+func NewTeamStats(blobs storage.BlobStore) *TeamStats {
+	return &TeamStats{blobs: blobs}
+}
+
+// Dates lists the days a category was snapshotted, oldest first
+func (s *TeamStats) Dates(ctx context.Context, category string) ([]string, error) {
+	if !validCategory(category) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCategory, category)
+	}
+
+	blobs, err := s.blobs.List(ctx, prefix(category))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s snapshots: %w", category, err)
+	}
+
+	dates := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		date := strings.TrimSuffix(path.Base(blob.Key), ".json")
+		if _, err := time.Parse(models.GameDayLayout, date); err != nil {
+			continue
+		}
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	return dates, nil
+}
+
+// AsOf returns the newest snapshot taken on or before asOf, or the newest overall when asOf is empty.
+// The Lambda runs before the day's first pitch, so the snapshot for a day only covers earlier games
+// and is safe to use as features for that day's games.
+func (s *TeamStats) AsOf(ctx context.Context, category string, asOf string) (*models.TeamStatsSnapshot, error) {
+	dates, err := s.Dates(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	date, ok := SnapshotDate(dates, asOf)
+	if !ok {
+		if asOf == "" {
+			return nil, fmt.Errorf("%w for %s", ErrNoSnapshot, category)
+		}
+		return nil, fmt.Errorf("%w for %s on or before %s", ErrNoSnapshot, category, asOf)
+	}
+
+	snapshot, err := s.Snapshot(ctx, category, date)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.AsOf = asOf
+
+	return snapshot, nil
+}
+
+// SnapshotDate picks the date AsOf would serve from dates sorted oldest first
+func SnapshotDate(dates []string, asOf string) (string, bool) {
+	// Dates sort lexically, so the answer is the last one not after asOf
+	i := len(dates)
+	if asOf != "" {
+		i = sort.Search(len(dates), func(i int) bool { return dates[i] > asOf })
+	}
+	if i == 0 {
+		return "", false
+	}
+	return dates[i-1], true
+}
+
+// Snapshot reads the snapshot taken on exactly date, one of the dates listed by Dates
+func (s *TeamStats) Snapshot(ctx context.Context, category string, date string) (*models.TeamStatsSnapshot, error) {
+	if !validCategory(category) {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCategory, category)
+	}
+
+	body, err := s.blobs.Get(ctx, prefix(category)+date+".json")
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, fmt.Errorf("%w for %s on %s", ErrNoSnapshot, category, date)
+		}
+		return nil, fmt.Errorf("failed to read %s snapshot for %s: %w", category, date, err)
+	}
+	defer body.Close()
+
+	snapshot := &models.TeamStatsSnapshot{Category: category, Date: date}
+	if err := json.NewDecoder(body).Decode(&snapshot.Teams); err != nil {
+		return nil, fmt.Errorf("failed to decode %s snapshot for %s: %w", category, date, err)
+	}
+
+	return snapshot, nil
+}
+
+func prefix(category string) string {
+	return "team-stats/" + category + "/"
+}
+
+func validCategory(category string) bool {
+	for _, c := range Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
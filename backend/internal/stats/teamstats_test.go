@@ -0,0 +1,94 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/storage"
+)
+
+func TestSnapshotDate(t *testing.T) {
+	dates := []string{"2025-06-01", "2025-06-03", "2025-06-07"}
+
+	tests := []struct {
+		name   string
+		dates  []string
+		asOf   string
+		want   string
+		wantOk bool
+	}{
+		{"before the first snapshot", dates, "2025-05-31", "", false},
+		{"exact match", dates, "2025-06-03", "2025-06-03", true},
+		{"between two snapshots", dates, "2025-06-05", "2025-06-03", true},
+		{"after the last snapshot", dates, "2025-09-28", "2025-06-07", true},
+		{"empty asOf takes the newest", dates, "", "2025-06-07", true},
+		{"no snapshots", nil, "", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := SnapshotDate(tt.dates, tt.asOf)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("SnapshotDate(%s) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+// newTestTeamStats stores hitting snapshots for 2025-06-01 and 2025-06-03, the Lambda's latest.json
+// copy, and a pitching snapshot that mustn't show up under hitting
+func newTestTeamStats(t *testing.T) *TeamStats {
+	t.Helper()
+	blobs, err := storage.NewLocalStore(t.TempDir(), "http://localhost", []byte("test-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, body := range map[string]string{
+		"team-stats/hitting/2025-06-03.json":  `{"111": {"runs": 30}}`,
+		"team-stats/hitting/2025-06-01.json":  `{"111": {"runs": 12}}`,
+		"team-stats/hitting/latest.json":      `{"111": {"runs": 30}}`,
+		"team-stats/pitching/2025-06-02.json": `{"111": {"era": 3.5}}`,
+	} {
+		if err := blobs.Put(context.Background(), key, strings.NewReader(body), "application/json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewTeamStats(blobs)
+}
+
+func TestTeamStatsDates(t *testing.T) {
+	teamStats := newTestTeamStats(t)
+
+	dates, err := teamStats.Dates(context.Background(), "hitting")
+	if err != nil {
+		t.Fatalf("Dates() error = %v", err)
+	}
+	if !slices.Equal(dates, []string{"2025-06-01", "2025-06-03"}) {
+		t.Errorf("Dates() = %v, want the two dated snapshots oldest first, without latest.json", dates)
+	}
+
+	if _, err := teamStats.Dates(context.Background(), "baserunning"); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("Dates(baserunning) error = %v, want ErrUnknownCategory", err)
+	}
+}
+
+func TestTeamStatsAsOf(t *testing.T) {
+	ctx := context.Background()
+	teamStats := newTestTeamStats(t)
+
+	snapshot, err := teamStats.AsOf(ctx, "hitting", "2025-06-02")
+	if err != nil {
+		t.Fatalf("AsOf() error = %v", err)
+	}
+	if snapshot.Date != "2025-06-01" || snapshot.AsOf != "2025-06-02" || string(snapshot.Teams["111"]) != `{"runs": 12}` {
+		t.Errorf("AsOf(2025-06-02) = %+v, want the 2025-06-01 snapshot", snapshot)
+	}
+
+	if _, err := teamStats.AsOf(ctx, "hitting", "2025-05-31"); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("AsOf() before the first snapshot error = %v, want ErrNoSnapshot", err)
+	}
+	if _, err := teamStats.AsOf(ctx, "catching", ""); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("AsOf() for a category without snapshots error = %v, want ErrNoSnapshot", err)
+	}
+}
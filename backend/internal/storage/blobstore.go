@@ -0,0 +1,87 @@
+// Package storage keeps uploaded model files and ingested MLB data behind the BlobStore interface,
+// backed by S3 in production and by a local directory for development without AWS.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// PresignedUpload is a signed PUT the client sends straight to the store.
+// Every header in Headers must be sent with the request or the signature is rejected.
+type PresignedUpload struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	Key  string
+	Size int64
+	// ChecksumSHA256 is base64-encoded. S3 only reports it for objects uploaded with a checksum.
+	ChecksumSHA256 string
+	LastModified   time.Time
+}
+
+// BlobStore stores model files by key. Get and Head return ErrBlobNotFound for missing keys;
+// deleting a missing key is not an error.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Head(ctx context.Context, key string) (*BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+
+	// PresignPut signs an upload of exactly size bytes whose SHA-256 (base64) must match checksum
+	PresignPut(ctx context.Context, key string, size int64, checksum string, contentType string, expires time.Duration) (*PresignedUpload, error)
+	// PresignGet signs a download of key as an attachment named fileName
+	PresignGet(ctx context.Context, key string, fileName string, expires time.Duration) (string, error)
+}
+
+// NewBlobStoreFromEnv picks the blob store from BLOB_STORE, "s3" (the default) or "local"
+func NewBlobStoreFromEnv(ctx context.Context) (BlobStore, error) {
+	switch backend := getEnv("BLOB_STORE", "s3"); backend {
+	case "s3":
+		bucket := os.Getenv("AWS_S3_BUCKET_NAME")
+		if bucket == "" {
+			return nil, fmt.Errorf("AWS_S3_BUCKET_NAME is required for the s3 blob store")
+		}
+		return NewS3Store(ctx, getEnv("AWS_REGION", "us-east-1"), bucket)
+	case "local":
+		return NewLocalStore(getEnv("LOCAL_BLOB_DIR", "model-artifacts"), getEnv("LOCAL_BLOB_URL", "/blobs/"), []byte(os.Getenv("LOCAL_BLOB_SIGNING_KEY")))
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", backend)
+	}
+}
+
+// NewDataStoreFromEnv opens the MLB data the ingestion Lambda writes: the DATA_BUCKET bucket,
+// or LOCAL_DATA_DIR when BLOB_STORE is "local". Nothing in it is presigned.
+func NewDataStoreFromEnv(ctx context.Context) (BlobStore, error) {
+	switch backend := getEnv("BLOB_STORE", "s3"); backend {
+	case "s3":
+		bucket := os.Getenv("DATA_BUCKET")
+		if bucket == "" {
+			return nil, fmt.Errorf("DATA_BUCKET is required for the s3 blob store")
+		}
+		return NewS3Store(ctx, getEnv("AWS_REGION", "us-east-1"), bucket)
+	case "local":
+		return NewLocalStore(getEnv("LOCAL_DATA_DIR", "mlb-data"), "/data/", nil)
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", backend)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,292 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tempPrefix marks partially written files, which List skips
+const tempPrefix = ".upload-"
+
+// LocalStore keeps blobs as files under a directory, with each key as a relative path.
+// Presigned URLs point at the store's own HTTP handler, which must be mounted at baseURL.
+// The layout matches the inference artifact directory, so the same directory can serve both.
+type LocalStore struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+}
+
+// NewLocalStore stores blobs under dir. URLs are signed with signingKey; an empty key
+// is replaced by a random one, so URLs stop working when the process restarts.
+func NewLocalStore(dir string, baseURL string, signingKey []byte) (*LocalStore, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve blob directory: %w", err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		rand.Read(signingKey)
+	}
+
+	return &LocalStore{root: root, baseURL: strings.TrimSuffix(baseURL, "/") + "/", signingKey: signingKey}, nil
+}
+
+// path maps a key into the root directory, refusing keys that escape it
+func (l *LocalStore) path(key string) (string, error) {
+	path := filepath.Join(l.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, l.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return path, nil
+}
+
+func (l *LocalStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	return l.write(key, body, -1, nil)
+}
+
+// write stores body under key through a temporary file, so readers never see a partial blob.
+// With size >= 0 body must be exactly that long, and with a checksum its SHA-256 must match;
+// otherwise nothing is stored.
+func (l *LocalStore) write(key string, body io.Reader, size int64, checksum []byte) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if size >= 0 {
+		body = io.LimitReader(body, size+1)
+	}
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("expected %d bytes, received %d", size, written)
+	}
+	if checksum != nil && !hmac.Equal(hash.Sum(nil), checksum) {
+		return fmt.Errorf("checksum does not match the signed checksum")
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (l *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	}
+	return file, err
+}
+
+// Head hashes the file to report its checksum, which is fine for local development
+func (l *LocalStore) Head(ctx context.Context, key string) (*BlobInfo, error) {
+	file, err := l.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.(*os.File).Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlobInfo{
+		Key:            key,
+		Size:           size,
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(hash.Sum(nil)),
+		LastModified:   info.ModTime(),
+	}, nil
+}
+
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *LocalStore) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+
+	err := filepath.WalkDir(l.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasPrefix(entry.Name(), tempPrefix) {
+			return err
+		}
+
+		relative, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, BlobInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+
+	return blobs, err
+}
+
+func (l *LocalStore) PresignPut(ctx context.Context, key string, size int64, checksum string, contentType string, expires time.Duration) (*PresignedUpload, error) {
+	if _, err := l.path(key); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(expires)
+	query := url.Values{
+		"expires":  {strconv.FormatInt(expiresAt.Unix(), 10)},
+		"size":     {strconv.FormatInt(size, 10)},
+		"checksum": {checksum},
+	}
+
+	return &PresignedUpload{
+		URL:       l.sign(http.MethodPut, key, query),
+		Method:    http.MethodPut,
+		Headers:   map[string]string{},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (l *LocalStore) PresignGet(ctx context.Context, key string, fileName string, expires time.Duration) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"expires":  {strconv.FormatInt(time.Now().Add(expires).Unix(), 10)},
+		"filename": {fileName},
+	}
+	return l.sign(http.MethodGet, key, query), nil
+}
+
+// sign adds an HMAC over the method, key and query to the blob's URL
+func (l *LocalStore) sign(method string, key string, query url.Values) string {
+	query.Set("signature", l.signature(method, key, query))
+	return l.baseURL + key + "?" + query.Encode()
+}
+
+func (l *LocalStore) signature(method string, key string, query url.Values) string {
+	unsigned := url.Values{}
+	for name, values := range query {
+		if name != "signature" {
+			unsigned[name] = values
+		}
+	}
+
+	mac := hmac.New(sha256.New, l.signingKey)
+	fmt.Fprintf(mac, "%s\n%s\n%s", method, key, unsigned.Encode())
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// MountPath is the URL path ServeHTTP has to be mounted at
+func (l *LocalStore) MountPath() string {
+	base, err := url.Parse(l.baseURL)
+	if err != nil {
+		return l.baseURL
+	}
+	return base.Path
+}
+
+// ServeHTTP handles presigned uploads and downloads. It must be mounted at the path of baseURL.
+func (l *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, l.MountPath())
+	query := r.URL.Query()
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || !hmac.Equal([]byte(query.Get("signature")), []byte(l.signature(r.Method, key, query))) {
+		http.Error(w, "Invalid signature", http.StatusForbidden)
+		return
+	}
+	if time.Now().Unix() > expires {
+		http.Error(w, "URL has expired", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		size, err := strconv.ParseInt(query.Get("size"), 10, 64)
+		if err != nil || r.ContentLength != size {
+			http.Error(w, "Content length does not match the signed size", http.StatusBadRequest)
+			return
+		}
+
+		checksum, err := base64.StdEncoding.DecodeString(query.Get("checksum"))
+		if err != nil {
+			http.Error(w, "Invalid checksum", http.StatusBadRequest)
+			return
+		}
+		if err := l.write(key, r.Body, size, checksum); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		file, err := l.Get(r.Context(), key)
+		if err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		defer file.Close()
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": query.Get("filename")}))
+		io.Copy(w, file)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
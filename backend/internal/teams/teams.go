@@ -0,0 +1,51 @@
+// Package teams bundles the MLB team and venue reference data and seeds it into the store
+package teams
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+)
+
+//go:embed teams.json
+var bundled []byte
+
+// Bundled returns the teams shipped with the binary, checking that each is complete
+func Bundled() ([]models.Team, error) {
+	var teams []models.Team
+	if err := json.Unmarshal(bundled, &teams); err != nil {
+		return nil, fmt.Errorf("failed to decode bundled teams: %w", err)
+	}
+
+	seen := make(map[string]bool, len(teams))
+	for _, team := range teams {
+		if team.Id == "" || team.Name == "" || team.Abbreviation == "" || team.League == "" || team.Division == "" || team.LogoURL == "" {
+			return nil, fmt.Errorf("bundled team %q is missing required fields", team.Id)
+		}
+		if seen[team.Id] {
+			return nil, fmt.Errorf("bundled team %q is listed twice", team.Id)
+		}
+		seen[team.Id] = true
+	}
+
+	return teams, nil
+}
+
+// Seed writes the bundled teams to the store. It's safe to run on every start;
+// edits to the bundled file replace what's stored.
+func Seed(ctx context.Context, db database.TeamStore) (int, error) {
+	teams, err := Bundled()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.PutTeams(ctx, teams); err != nil {
+		return 0, fmt.Errorf("failed to seed teams: %w", err)
+	}
+
+	return len(teams), nil
+}
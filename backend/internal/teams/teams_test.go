@@ -0,0 +1,93 @@
+package teams
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/database"
+)
+
+func TestBundled(t *testing.T) {
+	teams, err := Bundled()
+	if err != nil {
+		t.Fatalf("Bundled() error = %v", err)
+	}
+	if len(teams) != 30 {
+		t.Fatalf("Bundled() returned %d teams, want 30", len(teams))
+	}
+
+	ids := make(map[string]bool, len(teams))
+	abbreviations := make(map[string]bool, len(teams))
+	divisions := make(map[string]int)
+	for _, team := range teams {
+		if ids[team.Id] {
+			t.Errorf("team id %s is listed twice", team.Id)
+		}
+		ids[team.Id] = true
+		if abbreviations[team.Abbreviation] {
+			t.Errorf("abbreviation %s is listed twice", team.Abbreviation)
+		}
+		abbreviations[team.Abbreviation] = true
+
+		if team.League != "AL" && team.League != "NL" {
+			t.Errorf("%s league = %q, want AL or NL", team.Name, team.League)
+		}
+		if !strings.HasPrefix(team.Division, team.League+" ") {
+			t.Errorf("%s division %q isn't in its league %s", team.Name, team.Division, team.League)
+		}
+		divisions[team.Division]++
+
+		if !strings.HasSuffix(team.LogoURL, "/"+team.Id+".svg") {
+			t.Errorf("%s logo_url = %q, want its own team id", team.Name, team.LogoURL)
+		}
+		if team.Venue.Id == "" || team.Venue.Name == "" || team.Venue.City == "" {
+			t.Errorf("%s venue %+v is incomplete", team.Name, team.Venue)
+		}
+		if _, err := time.LoadLocation(team.Venue.TimeZone); err != nil || team.Venue.TimeZone == "" {
+			t.Errorf("%s time_zone %q isn't an IANA zone: %v", team.Name, team.Venue.TimeZone, err)
+		}
+	}
+
+	if len(divisions) != 6 {
+		t.Errorf("got %d divisions, want 6: %v", len(divisions), divisions)
+	}
+	for division, clubs := range divisions {
+		if clubs != 5 {
+			t.Errorf("%s has %d clubs, want 5", division, clubs)
+		}
+	}
+}
+
+func TestSeedReplacesStoredTeams(t *testing.T) {
+	ctx := context.Background()
+	db := database.NewMemoryDB()
+
+	// Seeding runs on every start, so a second run has to leave the same teams behind
+	for range 2 {
+		seeded, err := Seed(ctx, db)
+		if err != nil {
+			t.Fatalf("Seed() error = %v", err)
+		}
+		if seeded != 30 {
+			t.Errorf("Seed() = %d, want 30", seeded)
+		}
+	}
+
+	stored, err := db.ListTeams(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 30 {
+		t.Errorf("ListTeams() returned %d teams, want 30", len(stored))
+	}
+
+	team, err := db.GetTeam(ctx, "111")
+	if err != nil {
+		t.Fatalf("GetTeam() error = %v", err)
+	}
+	if team.Abbreviation != "BOS" || team.Venue.TimeZone != "America/New_York" {
+		t.Errorf("GetTeam(111) = %+v, want the Red Sox at Fenway", team)
+	}
+}
@@ -0,0 +1,439 @@
+package validation
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// allowedGlobals are the only module.name pairs a pickle may import: the numpy, scikit-learn and
+// xgboost types a fitted estimator pickles as, and the few builtins their state is made of.
+// REDUCE calls whatever a pickle imports with arguments of its choosing, so anything not listed
+// here is rejected, however harmless its module looks. Both numpy.core and numpy._core are
+// listed because numpy 2 renamed the package, and scikit-learn classes are listed under the
+// private modules they are defined in, since that is where pickle records them.
+var allowedGlobals = newGlobalSet(map[string][]string{
+	"builtins": {"bool", "bytearray", "complex", "dict", "float", "frozenset", "int", "list", "object", "set", "slice", "str", "tuple"},
+	// Protocol 2 pickles store bytes as _codecs.encode(str, "latin1")
+	"_codecs":     {"encode"},
+	"copyreg":     {"_reconstructor"},
+	"collections": {"OrderedDict", "defaultdict"},
+
+	"numpy":                      {"dtype", "ndarray"},
+	"numpy.core.multiarray":      {"_reconstruct", "scalar"},
+	"numpy._core.multiarray":     {"_reconstruct", "scalar"},
+	"numpy.core.numeric":         {"_frombuffer"},
+	"numpy._core.numeric":        {"_frombuffer"},
+	"numpy.random._pickle":       {"__bit_generator_ctor", "__generator_ctor", "__randomstate_ctor"},
+	"numpy.random._mt19937":      {"MT19937"},
+	"numpy.random._pcg64":        {"PCG64"},
+	"numpy.random.mtrand":        {"RandomState"},
+	"numpy.random._generator":    {"Generator"},
+	"numpy.random.bit_generator": {"SeedSequence"},
+
+	"sklearn.pipeline":                    {"Pipeline"},
+	"sklearn.compose._column_transformer": {"ColumnTransformer"},
+	"sklearn.multioutput":                 {"MultiOutputClassifier", "MultiOutputRegressor", "RegressorChain"},
+	"sklearn.dummy":                       {"DummyClassifier", "DummyRegressor"},
+	"sklearn.preprocessing._data":         {"MaxAbsScaler", "MinMaxScaler", "RobustScaler", "StandardScaler"},
+	"sklearn.preprocessing._encoders":     {"OneHotEncoder", "OrdinalEncoder"},
+	"sklearn.preprocessing._label":        {"LabelEncoder"},
+	"sklearn.preprocessing._polynomial":   {"PolynomialFeatures"},
+	"sklearn.impute._base":                {"SimpleImputer"},
+
+	"sklearn.linear_model._base":                {"LinearRegression"},
+	"sklearn.linear_model._bayes":               {"BayesianRidge"},
+	"sklearn.linear_model._coordinate_descent":  {"ElasticNet", "Lasso"},
+	"sklearn.linear_model._glm.glm":             {"PoissonRegressor"},
+	"sklearn.linear_model._logistic":            {"LogisticRegression"},
+	"sklearn.linear_model._ridge":               {"Ridge", "RidgeClassifier"},
+	"sklearn.linear_model._stochastic_gradient": {"SGDClassifier", "SGDRegressor"},
+	"sklearn.svm._classes":                      {"LinearSVC", "LinearSVR", "SVC", "SVR"},
+	"sklearn.neighbors._classification":         {"KNeighborsClassifier"},
+	"sklearn.neighbors._regression":             {"KNeighborsRegressor"},
+	"sklearn.neighbors._kd_tree":                {"KDTree", "newObj"},
+	"sklearn.neighbors._ball_tree":              {"BallTree", "newObj"},
+	"sklearn.metrics._dist_metrics":             {"EuclideanDistance64", "ManhattanDistance64", "MinkowskiDistance64", "newObj"},
+	"sklearn.calibration":                       {"CalibratedClassifierCV", "_CalibratedClassifier", "_SigmoidCalibration"},
+	"sklearn.isotonic":                          {"IsotonicRegression"},
+
+	"sklearn.tree._classes": {"DecisionTreeClassifier", "DecisionTreeRegressor", "ExtraTreeClassifier", "ExtraTreeRegressor"},
+	"sklearn.tree._tree":    {"Tree"},
+	"sklearn.ensemble._forest": {
+		"ExtraTreesClassifier", "ExtraTreesRegressor", "RandomForestClassifier", "RandomForestRegressor",
+	},
+	"sklearn.ensemble._gb": {"GradientBoostingClassifier", "GradientBoostingRegressor"},
+	"sklearn.ensemble._hist_gradient_boosting.gradient_boosting": {
+		"HistGradientBoostingClassifier", "HistGradientBoostingRegressor",
+	},
+	"sklearn.ensemble._hist_gradient_boosting.binning":   {"_BinMapper"},
+	"sklearn.ensemble._hist_gradient_boosting.predictor": {"TreePredictor"},
+	"sklearn._loss.loss": {
+		"AbsoluteError", "HalfBinomialLoss", "HalfMultinomialLoss", "HalfPoissonLoss", "HalfSquaredError",
+	},
+	"sklearn._loss._loss": {
+		"CyAbsoluteError", "CyHalfBinomialLoss", "CyHalfMultinomialLoss", "CyHalfPoissonLoss", "CyHalfSquaredError",
+	},
+	"sklearn._loss.link": {"IdentityLink", "Interval", "LogLink", "LogitLink", "MultinomialLogit"},
+
+	"xgboost.sklearn": {"XGBClassifier", "XGBRegressor"},
+	"xgboost.core":    {"Booster"},
+})
+
+func newGlobalSet(modules map[string][]string) map[string]bool {
+	globals := make(map[string]bool)
+	for module, names := range modules {
+		for _, name := range names {
+			globals[module+"."+name] = true
+		}
+	}
+	return globals
+}
+
+var errUnresolvedGlobal = errors.New("pickle imports a global that can't be resolved statically")
+
+// scanPickle walks the pickle opcode stream without executing it and returns an error for
+// the first global import that isn't in allowedGlobals. Pickles below protocol 2 have no header and are refused.
+func scanPickle(r io.Reader) error {
+	reader := bufio.NewReader(r)
+
+	op, err := reader.ReadByte()
+	if err != nil || op != opProto {
+		return fmt.Errorf("not a pickle (protocol 2 or newer required)")
+	}
+
+	// Strings pushed since the last other opcode, so STACK_GLOBAL can be resolved.
+	// CPython always emits the module and name strings (or memo lookups of them) directly before it.
+	var pending []string
+	memo := make(map[uint64]string)
+	nextMemo := uint64(0)
+	lastString, lastIsString := "", false
+
+	pushString := func(s string) {
+		pending = append(pending, s)
+		lastString, lastIsString = s, true
+	}
+	remember := func(index uint64) {
+		if lastIsString {
+			memo[index] = lastString
+		} else {
+			delete(memo, index)
+		}
+	}
+	recall := func(index uint64) {
+		if s, ok := memo[index]; ok {
+			pushString(s)
+		} else {
+			pending, lastIsString = nil, false
+		}
+	}
+
+	for first := true; ; first = false {
+		if !first {
+			op, err = reader.ReadByte()
+			if err != nil {
+				return fmt.Errorf("truncated pickle: missing STOP opcode")
+			}
+		}
+
+		switch op {
+		case opProto:
+			if _, err := readN(reader, 1); err != nil {
+				return err
+			}
+			continue
+		case opFrame:
+			if _, err := readN(reader, 8); err != nil {
+				return err
+			}
+			continue
+		case opStop:
+			return nil
+
+		case opShortBinUnicode, opShortBinString, opBinUnicode, opBinString, opBinUnicode8:
+			s, ok, err := readSized(reader, stringLengthWidths[op])
+			if err != nil {
+				return err
+			}
+			if ok {
+				pushString(s)
+				continue
+			}
+		case opUnicode, opString:
+			line, err := readLine(reader)
+			if err != nil {
+				return err
+			}
+			pushString(strings.Trim(line, `'"`))
+			continue
+
+		case opMemoize:
+			remember(nextMemo)
+			nextMemo++
+			continue
+		case opBinPut:
+			index, err := readUint(reader, 1)
+			if err != nil {
+				return err
+			}
+			remember(index)
+			continue
+		case opLongBinPut:
+			index, err := readUint(reader, 4)
+			if err != nil {
+				return err
+			}
+			remember(index)
+			continue
+		case opBinGet:
+			index, err := readUint(reader, 1)
+			if err != nil {
+				return err
+			}
+			recall(index)
+			continue
+		case opLongBinGet:
+			index, err := readUint(reader, 4)
+			if err != nil {
+				return err
+			}
+			recall(index)
+			continue
+
+		case opGlobal, opInst:
+			module, err := readLine(reader)
+			if err != nil {
+				return err
+			}
+			name, err := readLine(reader)
+			if err != nil {
+				return err
+			}
+			if err := checkGlobal(module, name); err != nil {
+				return err
+			}
+		case opStackGlobal:
+			if len(pending) < 2 {
+				return errUnresolvedGlobal
+			}
+			if err := checkGlobal(pending[len(pending)-2], pending[len(pending)-1]); err != nil {
+				return err
+			}
+		case opExt1, opExt2, opExt4:
+			return fmt.Errorf("pickle uses the copyreg extension registry, which is not allowed")
+
+		default:
+			size, ok := opArgSizes[op]
+			if !ok {
+				return fmt.Errorf("unknown pickle opcode 0x%02x", op)
+			}
+			if err := skipArg(reader, size); err != nil {
+				return err
+			}
+		}
+
+		// Anything other than a string push or memo operation breaks the STACK_GLOBAL pattern
+		pending, lastIsString = nil, false
+	}
+}
+
+func checkGlobal(module, name string) error {
+	if !allowedGlobals[module+"."+name] {
+		return fmt.Errorf("pickle imports %s.%s, which is not an allowed numpy, scikit-learn or xgboost type", module, name)
+	}
+	return nil
+}
+
+// Pickle opcodes, from CPython's Lib/pickle.py
+const (
+	opMark           = '('
+	opStop           = '.'
+	opPop            = '0'
+	opPopMark        = '1'
+	opDup            = '2'
+	opFloat          = 'F'
+	opInt            = 'I'
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opLong           = 'L'
+	opBinInt2        = 'M'
+	opNone           = 'N'
+	opPersId         = 'P'
+	opBinPersId      = 'Q'
+	opReduce         = 'R'
+	opString         = 'S'
+	opBinString      = 'T'
+	opShortBinString = 'U'
+	opUnicode        = 'V'
+	opBinUnicode     = 'X'
+	opAppend         = 'a'
+	opBuild          = 'b'
+	opGlobal         = 'c'
+	opDict           = 'd'
+	opEmptyDict      = '}'
+	opAppends        = 'e'
+	opGet            = 'g'
+	opBinGet         = 'h'
+	opInst           = 'i'
+	opLongBinGet     = 'j'
+	opList           = 'l'
+	opEmptyList      = ']'
+	opObj            = 'o'
+	opPut            = 'p'
+	opBinPut         = 'q'
+	opLongBinPut     = 'r'
+	opSetItem        = 's'
+	opTuple          = 't'
+	opEmptyTuple     = ')'
+	opSetItems       = 'u'
+	opBinFloat       = 'G'
+	opBinBytes       = 'B'
+	opShortBinBytes  = 'C'
+
+	opProto           = 0x80
+	opNewObj          = 0x81
+	opExt1            = 0x82
+	opExt2            = 0x83
+	opExt4            = 0x84
+	opTuple1          = 0x85
+	opTuple2          = 0x86
+	opTuple3          = 0x87
+	opNewTrue         = 0x88
+	opNewFalse        = 0x89
+	opLong1           = 0x8a
+	opLong4           = 0x8b
+	opShortBinUnicode = 0x8c
+	opBinUnicode8     = 0x8d
+	opBinBytes8       = 0x8e
+	opEmptySet        = 0x8f
+	opAddItems        = 0x90
+	opFrozenSet       = 0x91
+	opNewObjEx        = 0x92
+	opStackGlobal     = 0x93
+	opMemoize         = 0x94
+	opFrame           = 0x95
+	opByteArray8      = 0x96
+	opNextBuffer      = 0x97
+	opReadonlyBuffer  = 0x98
+)
+
+// Argument encodings for opcodes that need no special handling
+const (
+	argNone = iota
+	argLine
+	argFixed1
+	argFixed2
+	argFixed4
+	argFixed8
+	argSized1
+	argSized4
+	argSized8
+)
+
+var stringLengthWidths = map[byte]int{
+	opShortBinUnicode: 1, opShortBinString: 1,
+	opBinUnicode: 4, opBinString: 4,
+	opBinUnicode8: 8,
+}
+
+var opArgSizes = map[byte]int{
+	opMark: argNone, opPop: argNone, opPopMark: argNone, opDup: argNone, opNone: argNone,
+	opBinPersId: argNone, opReduce: argNone, opAppend: argNone, opBuild: argNone, opDict: argNone,
+	opEmptyDict: argNone, opAppends: argNone, opList: argNone, opEmptyList: argNone, opObj: argNone,
+	opSetItem: argNone, opTuple: argNone, opEmptyTuple: argNone, opSetItems: argNone,
+	opNewObj: argNone, opTuple1: argNone, opTuple2: argNone, opTuple3: argNone, opNewTrue: argNone,
+	opNewFalse: argNone, opEmptySet: argNone, opAddItems: argNone, opFrozenSet: argNone,
+	opNewObjEx: argNone, opNextBuffer: argNone, opReadonlyBuffer: argNone,
+
+	opFloat: argLine, opInt: argLine, opLong: argLine, opPersId: argLine, opGet: argLine, opPut: argLine,
+
+	opBinInt1:  argFixed1,
+	opBinInt2:  argFixed2,
+	opBinInt:   argFixed4,
+	opBinFloat: argFixed8,
+
+	opShortBinBytes: argSized1, opLong1: argSized1,
+	opBinBytes: argSized4, opLong4: argSized4,
+	opBinBytes8: argSized8, opByteArray8: argSized8,
+}
+
+func skipArg(reader *bufio.Reader, size int) error {
+	var err error
+	switch size {
+	case argLine:
+		_, err = readLine(reader)
+	case argFixed1:
+		_, err = readN(reader, 1)
+	case argFixed2:
+		_, err = readN(reader, 2)
+	case argFixed4:
+		_, err = readN(reader, 4)
+	case argFixed8:
+		_, err = readN(reader, 8)
+	case argSized1:
+		_, _, err = readSized(reader, 1)
+	case argSized4:
+		_, _, err = readSized(reader, 4)
+	case argSized8:
+		_, _, err = readSized(reader, 8)
+	}
+	return err
+}
+
+var errTruncatedPickle = errors.New("truncated pickle")
+
+const maxNameLength = 1024
+
+func readN(reader *bufio.Reader, n uint64) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return nil, errTruncatedPickle
+	}
+	return buf, nil
+}
+
+func readUint(reader *bufio.Reader, width int) (uint64, error) {
+	buf, err := readN(reader, uint64(width))
+	if err != nil {
+		return 0, err
+	}
+
+	var padded [8]byte
+	copy(padded[:], buf)
+	return binary.LittleEndian.Uint64(padded[:]), nil
+}
+
+// readSized reads a little-endian length of the given width followed by that many bytes.
+// Long payloads can't be global names, so they are discarded rather than buffered and
+// reported with ok false.
+func readSized(reader *bufio.Reader, width int) (value string, ok bool, err error) {
+	length, err := readUint(reader, width)
+	if err != nil {
+		return "", false, err
+	}
+
+	if length > maxNameLength {
+		if length > math.MaxInt64 {
+			return "", false, errTruncatedPickle
+		}
+		if _, err := io.CopyN(io.Discard, reader, int64(length)); err != nil {
+			return "", false, errTruncatedPickle
+		}
+		return "", false, nil
+	}
+
+	buf, err := readN(reader, length)
+	return string(buf), true, err
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", errTruncatedPickle
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
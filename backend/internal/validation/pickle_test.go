@@ -0,0 +1,201 @@
+package validation
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadPickle(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to load pickle fixture: %v", err)
+	}
+	return data
+}
+
+// shortString encodes s as a protocol 4 SHORT_BINUNICODE followed by MEMOIZE
+func shortString(s string) []byte {
+	return append(append([]byte{opShortBinUnicode, byte(len(s))}, s...), opMemoize)
+}
+
+// stackGlobalPickle imports module.name with STACK_GLOBAL and calls it with no arguments
+func stackGlobalPickle(module, name string) []byte {
+	data := []byte{opProto, 4}
+	data = append(data, shortString(module)...)
+	data = append(data, shortString(name)...)
+	return append(data, opStackGlobal, opMemoize, opEmptyTuple, opReduce, opStop)
+}
+
+// globalPickle imports module.name with the protocol 2 GLOBAL opcode and calls it with no arguments
+func globalPickle(module, name string) []byte {
+	data := []byte{opProto, 2, opGlobal}
+	data = append(data, module+"\n"+name+"\n"...)
+	return append(data, opEmptyTuple, opReduce, opStop)
+}
+
+// instPickle imports module.name with INST, which calls it on the marked arguments
+func instPickle(module, name string) []byte {
+	data := []byte{opProto, 2, opMark, opInst}
+	data = append(data, module+"\n"+name+"\n"...)
+	return append(data, opStop)
+}
+
+func TestScanPickleAcceptsModels(t *testing.T) {
+	for _, fixture := range []string{
+		"linear_regression.pkl",
+		"linear_regression_protocol2.pkl",
+		"pipeline.pkl",
+		"random_forest.pkl",
+		"xgboost_regressor.pkl",
+	} {
+		if err := scanPickle(bytes.NewReader(loadPickle(t, fixture))); err != nil {
+			t.Errorf("%s: scanPickle() error = %v", fixture, err)
+		}
+	}
+}
+
+func TestScanPickleAcceptsAllowedGlobals(t *testing.T) {
+	for _, global := range []string{
+		"numpy._core.multiarray._reconstruct",
+		"numpy.core.multiarray.scalar",
+		"numpy.random._pickle.__randomstate_ctor",
+		"sklearn.ensemble._hist_gradient_boosting.gradient_boosting.HistGradientBoostingRegressor",
+		"sklearn.linear_model._logistic.LogisticRegression",
+		"builtins.set",
+		"collections.OrderedDict",
+	} {
+		dot := strings.LastIndex(global, ".")
+		module, name := global[:dot], global[dot+1:]
+
+		for opcode, data := range map[string][]byte{
+			"STACK_GLOBAL": stackGlobalPickle(module, name),
+			"GLOBAL":       globalPickle(module, name),
+		} {
+			if err := scanPickle(bytes.NewReader(data)); err != nil {
+				t.Errorf("%s via %s: scanPickle() error = %v", global, opcode, err)
+			}
+		}
+	}
+}
+
+func TestScanPickleRejectsFixtures(t *testing.T) {
+	for fixture, global := range map[string]string{
+		"custom_class.pkl": "__main__.Model",
+		"os_system.pkl":    "posix.system",
+		"timeit.pkl":       "timeit.timeit",
+		"cprofile_run.pkl": "cProfile.run",
+		"nested_eval.pkl":  "builtins.eval",
+	} {
+		err := scanPickle(bytes.NewReader(loadPickle(t, fixture)))
+		if err == nil || !strings.Contains(err.Error(), global) {
+			t.Errorf("%s: scanPickle() error = %v, want %s rejected", fixture, err, global)
+		}
+	}
+}
+
+func TestScanPickleRejectsGlobals(t *testing.T) {
+	// Everything here can run code, read files or load further pickles. None of it is on a
+	// denylist; it is rejected only because it isn't on the allowlist.
+	for _, global := range []string{
+		"timeit.timeit",
+		"pdb.run",
+		"cProfile.run",
+		"profile.run",
+		"trace.Trace",
+		"doctest.debug_script",
+		"_posixsubprocess.fork_exec",
+		"_io.open",
+		"io.open",
+		"posix.system",
+		"nt.system",
+		"subprocess.Popen",
+		"builtins.eval",
+		"builtins.getattr",
+		"builtins.__import__",
+		"operator.attrgetter",
+		"functools.partial",
+		"numpy.load",
+		"numpy.testing._private.utils.runstring",
+		"sklearn.datasets._openml.fetch_openml",
+		"joblib.load",
+		"xgboost.core._load_lib",
+		// An allowed module name alone isn't enough
+		"numpy.core.multiarray.fromfile",
+		"sklearn.pipeline.FunctionTransformer",
+	} {
+		dot := strings.LastIndex(global, ".")
+		module, name := global[:dot], global[dot+1:]
+
+		for opcode, data := range map[string][]byte{
+			"STACK_GLOBAL": stackGlobalPickle(module, name),
+			"GLOBAL":       globalPickle(module, name),
+			"INST":         instPickle(module, name),
+		} {
+			err := scanPickle(bytes.NewReader(data))
+			if err == nil || !strings.Contains(err.Error(), global) {
+				t.Errorf("%s via %s: scanPickle() error = %v, want it rejected", global, opcode, err)
+			}
+		}
+	}
+}
+
+func TestScanPickleResolvesMemoizedNames(t *testing.T) {
+	// numpy.ndarray is imported first, then numpy is recalled from the memo to import numpy.load
+	data := []byte{opProto, 4}
+	data = append(data, shortString("numpy")...)   // memo 0
+	data = append(data, shortString("ndarray")...) // memo 1
+	data = append(data, opStackGlobal, opMemoize, opPop)
+	data = append(data, opBinGet, 0)
+	data = append(data, shortString("load")...)
+	data = append(data, opStackGlobal, opEmptyTuple, opReduce, opStop)
+
+	err := scanPickle(bytes.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), "numpy.load") {
+		t.Errorf("scanPickle() error = %v, want numpy.load rejected", err)
+	}
+}
+
+func TestScanPickleRejectsUnresolvableGlobals(t *testing.T) {
+	tests := map[string][]byte{
+		// The module name is built at runtime rather than pushed as a string
+		"computed name": {opProto, 4, opShortBinUnicode, 5, 'n', 'u', 'm', 'p', 'y', opEmptyTuple, opStackGlobal, opStop},
+		// A memo slot that held something other than a string
+		"non-string memo": append(append([]byte{opProto, 4, opEmptyDict, opMemoize, opBinGet, 0}, shortString("eval")...), opStackGlobal, opStop),
+		"extension":       {opProto, 4, opExt1, 1, opStop},
+	}
+
+	for name, data := range tests {
+		if err := scanPickle(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: scanPickle() error = nil", name)
+		}
+	}
+}
+
+func TestInspectPickle(t *testing.T) {
+	report, err := Inspect(bytes.NewReader(loadPickle(t, "pipeline.pkl")), "model.pkl")
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if !report.Passed() || report.Sha256 == "" || report.Size == 0 {
+		t.Errorf("Inspect() = %+v, want a passing report with a checksum", report)
+	}
+
+	report, err = Inspect(bytes.NewReader(loadPickle(t, "timeit.pkl")), "model.pkl")
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if report.Passed() || report.Status() != "rejected" {
+		t.Errorf("Inspect() = %+v, want timeit.timeit rejected", report)
+	}
+
+	report, err = Inspect(strings.NewReader("not a pickle"), "model.pkl")
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if report.Passed() {
+		t.Error("Inspect() passed a text file")
+	}
+}
@@ -0,0 +1,156 @@
+#!/usr/bin/env python3
+"""Writes the pickle fixtures in this directory.
+
+numpy, scikit-learn and xgboost don't need to be installed: stand-in modules are
+registered under the real module names, and their classes reduce the same way the
+real ones do, so the opcode streams match what pickling a fitted model produces.
+
+    python3 make_pickles.py
+"""
+import cProfile
+import os
+import pickle
+import sys
+import timeit
+import types
+
+
+def stub_module(name, **attrs):
+    # Importing a.b.c imports a and a.b first, so those need to exist too
+    parts = name.split(".")
+    for i in range(1, len(parts)):
+        sys.modules.setdefault(".".join(parts[:i]), types.ModuleType(".".join(parts[:i])))
+
+    module = types.ModuleType(name)
+    for attr, value in attrs.items():
+        value.__module__ = name
+        value.__qualname__ = attr
+        setattr(module, attr, value)
+    sys.modules[name] = module
+    return module
+
+
+# numpy: arrays reduce to _reconstruct plus a state tuple, dtypes to numpy.dtype
+def _reconstruct(subtype, shape, dtype):
+    raise NotImplementedError
+
+
+class dtype:
+    def __init__(self, code):
+        self.code = code
+
+    def __reduce__(self):
+        return (dtype, (self.code, False, True), (3, "<", None, None, None, -1, -1, 0))
+
+
+class ndarray:
+    def __init__(self, values, code="f8"):
+        self.values = values
+        self.code = code
+
+    def __reduce__(self):
+        import struct
+
+        raw = struct.pack("<%dd" % len(self.values), *self.values)
+        state = (1, (len(self.values),), dtype(self.code), False, raw)
+        return (_reconstruct, (ndarray, (0,), b"b"), state)
+
+
+def scalar(dt, raw):
+    raise NotImplementedError
+
+
+stub_module("numpy", ndarray=ndarray, dtype=dtype)
+stub_module("numpy._core.multiarray", _reconstruct=_reconstruct, scalar=scalar)
+
+
+class Estimator:
+    """Pickles like sklearn.base.BaseEstimator: NEWOBJ of the class, then BUILD with __dict__"""
+
+    def __init__(self, **params):
+        self.__dict__.update(params)
+        self._sklearn_version = "1.5.2"
+
+
+def estimator(module, name):
+    cls = type(name, (Estimator,), {})
+    stub_module(module, **{name: cls})
+    return cls
+
+
+class Tree:
+    """Pickles like the Cython sklearn.tree._tree.Tree: REDUCE with its constructor arguments"""
+
+    def __init__(self, n_features, nodes):
+        self.n_features = n_features
+        self.nodes = nodes
+
+    def __reduce__(self):
+        state = {"max_depth": 2, "node_count": len(self.nodes), "nodes": ndarray(self.nodes), "values": ndarray(self.nodes)}
+        return (Tree, (self.n_features, ndarray([1.0]), 1), state)
+
+
+stub_module("sklearn.tree._tree", Tree=Tree)
+
+LinearRegression = estimator("sklearn.linear_model._base", "LinearRegression")
+Ridge = estimator("sklearn.linear_model._ridge", "Ridge")
+StandardScaler = estimator("sklearn.preprocessing._data", "StandardScaler")
+MultiOutputRegressor = estimator("sklearn.multioutput", "MultiOutputRegressor")
+Pipeline = estimator("sklearn.pipeline", "Pipeline")
+DecisionTreeRegressor = estimator("sklearn.tree._classes", "DecisionTreeRegressor")
+RandomForestRegressor = estimator("sklearn.ensemble._forest", "RandomForestRegressor")
+
+
+class Booster:
+    """Pickles like xgboost.core.Booster, whose state is the raw model buffer"""
+
+    def __getstate__(self):
+        return {"handle": bytearray(b"{L\x00\x00\x00\x00\x00\x00\x00\x07learner")}
+
+
+stub_module("xgboost.core", Booster=Booster)
+XGBRegressor = estimator("xgboost.sklearn", "XGBRegressor")
+
+
+class Model:
+    """A hand-written model class, which the allowlist has no way to vouch for"""
+
+    def predict_game(self, game):
+        return {}
+
+
+class Reduce:
+    def __init__(self, fn, *args):
+        self.fn, self.args = fn, args
+
+    def __reduce__(self):
+        return (self.fn, self.args)
+
+
+def write(name, obj, protocol=4):
+    with open(name, "wb") as f:
+        pickle.dump(obj, f, protocol=protocol)
+
+
+if __name__ == "__main__":
+    coefficients = [0.8, -0.6, 3.1, -2.9, -3.0, 2.8, 0.4, -0.4]
+
+    write("linear_regression.pkl", LinearRegression(
+        fit_intercept=True, n_features_in_=8, coef_=ndarray(coefficients), intercept_=4.3,
+    ))
+    write("pipeline.pkl", Pipeline(steps=[
+        ("scale", StandardScaler(mean_=ndarray(coefficients), scale_=ndarray(coefficients), with_mean=True)),
+        ("model", MultiOutputRegressor(estimators_=[Ridge(alpha=1.0, coef_=ndarray(coefficients)) for _ in range(3)])),
+    ], memory=None, verbose=False))
+    forest_trees = [DecisionTreeRegressor(max_depth=2, tree_=Tree(8, [0.5, 1.5, 2.5])) for _ in range(3)]
+    write("random_forest.pkl", RandomForestRegressor(n_estimators=3, estimators_=forest_trees, random_state=42))
+    write("xgboost_regressor.pkl", XGBRegressor(n_estimators=100, max_depth=4, _Booster=Booster()))
+    # Protocol 2 stores bytes through _codecs.encode and uses GLOBAL instead of STACK_GLOBAL
+    write("linear_regression_protocol2.pkl", LinearRegression(coef_=ndarray(coefficients), intercept_=4.3, raw=b"\x00\x01"), protocol=2)
+
+    write("custom_class.pkl", Model())
+    write("os_system.pkl", Reduce(os.system, "id"))
+    write("timeit.pkl", Reduce(timeit.timeit, "__import__('os').system('id')"))
+    write("cprofile_run.pkl", Reduce(cProfile.run, "__import__('os').system('id')"))
+    # The payload hides inside an otherwise allowed model's state
+    write("nested_eval.pkl", LinearRegression(coef_=ndarray(coefficients), intercept_=Reduce(eval, "4.3")))
@@ -0,0 +1,181 @@
+// Package validation inspects uploaded model files before they are allowed to run.
+//
+// Every upload is checked for size, magic bytes matching its extension, and a SHA-256 checksum.
+// Pickles are additionally scanned opcode by opcode and may only import allowlisted globals, and ONNX
+// models have their graph signature checked against the feature schema.
+package validation
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bendemouth/mlb-prediction-pool/internal/models"
+	"github.com/bendemouth/mlb-prediction-pool/internal/onnx"
+)
+
+// MaxModelSize is the largest model file accepted
+const MaxModelSize = 500 * 1024 * 1024
+
+// Report is the outcome of inspecting one model file.
+// Reason is empty when the file passed every check.
+type Report struct {
+	Framework string
+	Sha256    string
+	Size      int64
+	Signature *models.ModelSignature
+	Reason    string
+}
+
+func (r Report) Passed() bool {
+	return r.Reason == ""
+}
+
+// Status is the model status the report leads to
+func (r Report) Status() string {
+	if r.Passed() {
+		return models.ModelStatusValidated
+	}
+	return models.ModelStatusRejected
+}
+
+// Inspect reads the whole file once, so the caller has to rewind it before storing it.
+// Pickles are scanned as they stream past; ONNX files are buffered to parse the graph.
+// Failed checks are reported in Report.Reason; the error is only for read failures.
+func Inspect(file io.Reader, fileName string) (Report, error) {
+	var report Report
+
+	switch path.Ext(fileName) {
+	case ".pkl":
+		report.Framework = models.ModelFrameworkPickle
+	case ".onnx":
+		report.Framework = models.ModelFrameworkONNX
+	default:
+		report.Reason = "unsupported file type, only .pkl and .onnx files are allowed"
+		return report, nil
+	}
+
+	// Read one byte past the limit to tell an oversized file from one exactly at it
+	hash := sha256.New()
+	source := &readRecorder{reader: io.LimitReader(file, MaxModelSize+1)}
+	reader := bufio.NewReader(io.TeeReader(source, hash))
+
+	var reason string
+	switch report.Framework {
+	case models.ModelFrameworkPickle:
+		reason = checkPickle(reader)
+	case models.ModelFrameworkONNX:
+		report.Signature, reason = checkONNX(reader)
+	}
+
+	// Drain whatever the checks didn't need so the size and checksum cover the whole file
+	if _, err := io.Copy(io.Discard, reader); err != nil && source.err == nil {
+		source.err = err
+	}
+	if source.err != nil {
+		return report, fmt.Errorf("failed to read model file: %w", source.err)
+	}
+
+	report.Size = source.read
+	report.Sha256 = hex.EncodeToString(hash.Sum(nil))
+
+	switch {
+	case report.Size > MaxModelSize:
+		report.Sha256 = ""
+		report.Signature = nil
+		report.Reason = fmt.Sprintf("file is larger than the %d MB limit", MaxModelSize/(1024*1024))
+	case report.Size == 0:
+		report.Reason = "file is empty"
+	default:
+		report.Reason = reason
+	}
+
+	return report, nil
+}
+
+// readRecorder counts bytes read and keeps the first real read error, so a failed
+// download isn't mistaken for a malformed file
+type readRecorder struct {
+	reader io.Reader
+	read   int64
+	err    error
+}
+
+func (r *readRecorder) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+func checkPickle(reader *bufio.Reader) string {
+	// Protocol 2+ pickles start with PROTO and the protocol number
+	header, _ := reader.Peek(2)
+	if len(header) < 2 || header[0] != opProto || header[1] < 2 || header[1] > 5 {
+		return "file does not look like a pickle (protocol 2 to 5 expected)"
+	}
+	if err := scanPickle(reader); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func checkONNX(reader *bufio.Reader) (*models.ModelSignature, string) {
+	// An ONNX ModelProto starts with ir_version, field 1 as a varint
+	header, _ := reader.Peek(1)
+	if len(header) < 1 || header[0] != 0x08 {
+		return nil, "file does not look like an ONNX model"
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "failed to read ONNX model"
+	}
+
+	signature, err := onnx.ParseSignature(data)
+	if err != nil {
+		return nil, err.Error()
+	}
+	if err := onnx.ValidateSignature(signature); err != nil {
+		return signature, err.Error()
+	}
+	return signature, ""
+}
+
+// CheckFeatures validates the input columns declared for a model and returns the list to record.
+// ONNX models always take the full schema in order, so an empty declaration means exactly that.
+// Pickles may declare any subset of the schema in the column order they were trained on;
+// one that declares none is given the whole schema in order.
+func CheckFeatures(framework string, declared []string) ([]string, error) {
+	if framework == models.ModelFrameworkONNX {
+		if len(declared) == 0 {
+			return append([]string(nil), onnx.FeatureNames...), nil
+		}
+		if !slices.Equal(declared, onnx.FeatureNames) {
+			return nil, fmt.Errorf("ONNX models must declare the feature schema in order: %s", strings.Join(onnx.FeatureNames, ", "))
+		}
+		return declared, nil
+	}
+
+	seen := make(map[string]bool, len(declared))
+	for _, feature := range declared {
+		if !slices.Contains(onnx.FeatureNames, feature) {
+			return nil, fmt.Errorf("unknown feature %q", feature)
+		}
+		if seen[feature] {
+			return nil, fmt.Errorf("feature %q is declared twice", feature)
+		}
+		seen[feature] = true
+	}
+	if len(declared) == 0 {
+		return nil, nil
+	}
+	return declared, nil
+}
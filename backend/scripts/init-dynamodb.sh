@@ -26,8 +26,19 @@ aws dynamodb create-table \
 # Create Games Table
 aws dynamodb create-table \
     --table-name mlb-prediction-pool-dev-games \
-    --attribute-definitions AttributeName=gameId,AttributeType=S \
+    --attribute-definitions \
+        AttributeName=gameId,AttributeType=S \
+        AttributeName=status,AttributeType=S \
+        AttributeName=date,AttributeType=S \
+        AttributeName=gameDay,AttributeType=S \
+        AttributeName=homeTeamId,AttributeType=S \
+        AttributeName=awayTeamId,AttributeType=S \
     --key-schema AttributeName=gameId,KeyType=HASH \
+    --global-secondary-indexes \
+        "IndexName=StatusDateIndex,KeySchema=[{AttributeName=status,KeyType=HASH},{AttributeName=date,KeyType=RANGE}],Projection={ProjectionType=ALL}" \
+        "IndexName=GameDayIndex,KeySchema=[{AttributeName=gameDay,KeyType=HASH},{AttributeName=date,KeyType=RANGE}],Projection={ProjectionType=ALL}" \
+        "IndexName=HomeTeamDayIndex,KeySchema=[{AttributeName=homeTeamId,KeyType=HASH},{AttributeName=gameDay,KeyType=RANGE}],Projection={ProjectionType=ALL}" \
+        "IndexName=AwayTeamDayIndex,KeySchema=[{AttributeName=awayTeamId,KeyType=HASH},{AttributeName=gameDay,KeyType=RANGE}],Projection={ProjectionType=ALL}" \
     --billing-mode PAY_PER_REQUEST \
     --endpoint-url http://dynamodb-local:8000 \
     --region us-east-1 || echo "Games table already exists"
@@ -38,11 +49,14 @@ aws dynamodb create-table \
     --attribute-definitions \
         AttributeName=userId,AttributeType=S \
         AttributeName=gameId,AttributeType=S \
+        AttributeName=predictionKey,AttributeType=S \
+        AttributeName=modelId,AttributeType=S \
     --key-schema \
         AttributeName=userId,KeyType=HASH \
-        AttributeName=gameId,KeyType=RANGE \
+        AttributeName=predictionKey,KeyType=RANGE \
     --global-secondary-indexes \
         "IndexName=GameIdIndex,KeySchema=[{AttributeName=gameId,KeyType=HASH}],Projection={ProjectionType=ALL}" \
+        "IndexName=ModelIdIndex,KeySchema=[{AttributeName=modelId,KeyType=HASH},{AttributeName=gameId,KeyType=RANGE}],Projection={ProjectionType=ALL}" \
     --billing-mode PAY_PER_REQUEST \
     --endpoint-url http://dynamodb-local:8000 \
     --region us-east-1 || echo "Predictions table already exists"
@@ -56,4 +70,102 @@ aws dynamodb create-table \
     --endpoint-url http://dynamodb-local:8000 \
     --region us-east-1 || echo "Models table already exists"
 
+# Create Model Uploads Table, one counter per user and day
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-model-uploads \
+    --attribute-definitions \
+        AttributeName=userId,AttributeType=S \
+        AttributeName=day,AttributeType=S \
+    --key-schema \
+        AttributeName=userId,KeyType=HASH \
+        AttributeName=day,KeyType=RANGE \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "Model uploads table already exists"
+
+# Create Model Versions Table, one item per version a user's model family has in use
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-model-versions \
+    --attribute-definitions \
+        AttributeName=userId,AttributeType=S \
+        AttributeName=familyVersion,AttributeType=S \
+    --key-schema \
+        AttributeName=userId,KeyType=HASH \
+        AttributeName=familyVersion,KeyType=RANGE \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "Model versions table already exists"
+
+# Create Teams Table
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-teams \
+    --attribute-definitions AttributeName=teamId,AttributeType=S \
+    --key-schema AttributeName=teamId,KeyType=HASH \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "Teams table already exists"
+
+# Create Seasons Table
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-seasons \
+    --attribute-definitions AttributeName=year,AttributeType=N \
+    --key-schema AttributeName=year,KeyType=HASH \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "Seasons table already exists"
+
+# Create Leagues Table
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-leagues \
+    --attribute-definitions \
+        AttributeName=leagueId,AttributeType=S \
+        AttributeName=inviteCode,AttributeType=S \
+    --key-schema AttributeName=leagueId,KeyType=HASH \
+    --global-secondary-indexes \
+        "IndexName=InviteCodeIndex,KeySchema=[{AttributeName=inviteCode,KeyType=HASH}],Projection={ProjectionType=ALL}" \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "Leagues table already exists"
+
+# Create League Members Table
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-league-members \
+    --attribute-definitions \
+        AttributeName=leagueId,AttributeType=S \
+        AttributeName=userId,AttributeType=S \
+    --key-schema \
+        AttributeName=leagueId,KeyType=HASH \
+        AttributeName=userId,KeyType=RANGE \
+    --global-secondary-indexes \
+        "IndexName=UserIdIndex,KeySchema=[{AttributeName=userId,KeyType=HASH},{AttributeName=leagueId,KeyType=RANGE}],Projection={ProjectionType=ALL}" \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "League members table already exists"
+
+# Create Contests Table
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-contests \
+    --attribute-definitions \
+        AttributeName=contestId,AttributeType=S \
+        AttributeName=leagueId,AttributeType=S \
+    --key-schema AttributeName=contestId,KeyType=HASH \
+    --global-secondary-indexes \
+        "IndexName=LeagueIdIndex,KeySchema=[{AttributeName=leagueId,KeyType=HASH}],Projection={ProjectionType=ALL}" \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "Contests table already exists"
+
+# Create Contest Entries Table
+aws dynamodb create-table \
+    --table-name mlb-prediction-pool-dev-contest-entries \
+    --attribute-definitions \
+        AttributeName=contestId,AttributeType=S \
+        AttributeName=userId,AttributeType=S \
+    --key-schema \
+        AttributeName=contestId,KeyType=HASH \
+        AttributeName=userId,KeyType=RANGE \
+    --billing-mode PAY_PER_REQUEST \
+    --endpoint-url http://dynamodb-local:8000 \
+    --region us-east-1 || echo "Contest entries table already exists"
+
 echo "Tables created successfully!"
\ No newline at end of file
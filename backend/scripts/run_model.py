@@ -0,0 +1,56 @@
+#!/usr/bin/env python3
+"""Reference runner for the Go SubprocessExecutor (cmd/infer).
+
+Reads {"model": ..., "model_path": ..., "games": [...], "feature_names": [...],
+"features": [[...], ...]} from stdin and writes {"predictions": [...]} to stdout.
+
+The pickle must be a fitted scikit-learn or xgboost estimator, or a Pipeline of
+them, whose predict(features) returns one row per game of [home runs, away runs,
+home win probability], the same output an ONNX model gives. features has one
+column per feature name, in the order the model declared them at upload.
+
+Uploads are only validated when every global the pickle imports is on the
+allowlist in internal/validation/pickle.go, which limits what unpickling can call.
+Keep running this inside a sandbox all the same.
+Requires: pip install numpy scikit-learn (and xgboost for xgboost models)
+"""
+import json
+import pickle
+import sys
+
+import numpy as np
+
+
+def main():
+    request = json.load(sys.stdin)
+
+    with open(request["model_path"], "rb") as f:
+        model = pickle.load(f)
+
+    games = request["games"]
+    features = np.asarray(request["features"], dtype=np.float64).reshape(len(games), len(request["feature_names"]))
+    outputs = np.asarray(model.predict(features), dtype=np.float64).reshape(len(games), -1)
+
+    predictions = []
+    for game, row in zip(games, outputs):
+        home, away = float(row[0]), float(row[1])
+        home_win = min(max(float(row[2]), 0.0), 1.0)
+
+        winner, confidence = game["home_id"], home_win
+        if home_win < 0.5:
+            winner, confidence = game["away_id"], 1.0 - home_win
+
+        predictions.append({
+            "game_id": game["game_id"],
+            "home_score_predicted": home,
+            "away_score_predicted": away,
+            "total_score_predicted": home + away,
+            "confidence": confidence,
+            "predicted_winner_id": winner,
+        })
+
+    json.dump({"predictions": predictions}, sys.stdout)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,30 @@
+#!/usr/bin/env python3
+"""Reference runner for the Go ONNXExecutor (cmd/infer).
+
+Reads {"model_path": ..., "input_name": ..., "features": [[...], ...]} from stdin and
+writes {"outputs": [[home, away, home_win_probability], ...]} to stdout.
+
+Only tensors cross the process boundary and onnxruntime never executes Python from
+the model file, so unlike run_model.py this is safe to run on untrusted uploads.
+Requires: pip install onnxruntime numpy
+"""
+import json
+import sys
+
+import numpy as np
+import onnxruntime as ort
+
+
+def main():
+    request = json.load(sys.stdin)
+
+    session = ort.InferenceSession(request["model_path"], providers=["CPUExecutionProvider"])
+    features = np.asarray(request["features"], dtype=np.float32).reshape(-1, session.get_inputs()[0].shape[1])
+
+    outputs = session.run(None, {request["input_name"]: features})[0]
+
+    json.dump({"outputs": np.asarray(outputs, dtype=np.float64).tolist()}, sys.stdout)
+
+
+if __name__ == "__main__":
+    main()
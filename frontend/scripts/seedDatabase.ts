@@ -124,6 +124,7 @@ type SeedUser = { userId: string; username: string; email: string; createdAt: st
 type SeedGame = {
   gameId: string;
   date: string;
+  gameDay: string;
   homeTeam: string;
   homeTeamId: string;
   awayTeam: string;
@@ -199,6 +200,7 @@ function makeGames(): SeedGame[] {
     games.push({
       gameId: `${DATASET_ID}-game-${String(i + 1).padStart(4, '0')}`,
       date: gameDate.toISOString(),
+      gameDay: gameDate.toISOString().slice(0, 10),
       homeTeam: homeTeam.name,
       homeTeamId: homeTeam.id,
       awayTeam: awayTeam.name,
@@ -262,6 +264,8 @@ function makeConfidence(game: SeedGame, persona: Persona, predictedWinnerId: str
 type SeedPrediction = {
   userId: string;
   gameId: string;
+  // Sort key: gameId for a user's own picks, gameId#modelId for a model's
+  predictionKey: string;
   homeScorePredicted: number;
   awayScorePredicted: number;
   totalScorePredicted: number;
@@ -312,6 +316,7 @@ function makePredictions(users: SeedUser[], games: SeedGame[]): SeedPrediction[]
       const prediction: SeedPrediction = {
         userId: u.userId,
         gameId: game.gameId,
+        predictionKey: game.gameId,
         ...scores,
         confidence,
         predictedWinnerId,
@@ -0,0 +1,34 @@
+import { Game } from "./game";
+
+// Either game_ids, or a from/to range of game days (YYYY-MM-DD) narrowed by team_ids and game_types
+export interface ContestRules {
+    game_ids?: string[];
+    from?: string;
+    to?: string;
+    team_ids?: string[];
+    game_types?: string[];
+}
+
+export interface Contest {
+    contest_id: string;
+    league_id: string;
+    name: string;
+    rules: ContestRules;
+    entry_opens: string;
+    entry_closes: string;
+    created_by: string;
+    created_at: string;
+    // Whether entries are being taken right now
+    open: boolean;
+    entrant_count: number;
+    // Whether the caller has entered
+    entered: boolean;
+    // The contest's slate, only included when a single contest is fetched
+    games?: Game[];
+}
+
+export interface ContestEntry {
+    contest_id: string;
+    user_id: string;
+    entered_at: string;
+}
@@ -1,6 +1,9 @@
+import { Team } from "./team";
+
 export interface Game {
     game_id: string;
     date: string;
+    game_day: string;
     home_team: string;
     home_team_id: string;
     away_team: string;
@@ -9,4 +12,15 @@ export interface Game {
     away_score: number | null;
     status: string;
     winner: string | null;
+    season?: number;
+    game_type?: string;
+    game_number?: number;
+    // "traditional" or "split" for games in a doubleheader
+    doubleheader?: string;
+    // Game 2 of a traditional doubleheader starts after game 1 ends, so its date is a placeholder
+    start_time_tbd?: boolean;
+    start_time_zone?: string;
+    // Present when requested with ?expand=teams
+    home_team_details?: Team;
+    away_team_details?: Team;
 }
\ No newline at end of file
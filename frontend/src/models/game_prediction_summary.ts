@@ -1,9 +1,24 @@
 import { Game } from './game';
 
+export interface ScoreBucket {
+    runs: number;
+    count: number;
+}
+
 export interface GamePredictionSummary extends Game {
     prediction_count: number;
     avg_home_score_predicted: number;
     avg_away_score_predicted: number;
     avg_total_score_predicted: number;
     avg_confidence: number;
+    home_pick_pct: number;
+    away_pick_pct: number;
+    // Share of the confidence placed on each team (0-1), not a calibrated chance of winning
+    home_win_probability: number;
+    away_win_probability: number;
+    median_total_score_predicted: number;
+    stddev_total_score_predicted: number;
+    home_score_histogram: ScoreBucket[];
+    away_score_histogram: ScoreBucket[];
+    total_score_histogram: ScoreBucket[];
 }
@@ -1,6 +1,10 @@
 export interface LeaderboardEntry {
     user_id: string;
     username: string;
+    model_id?: string;
+    model_name?: string;
+    // Omitted for all-time standings
+    season?: number;
     total_winners_correct: number;
     winner_accuracy: number;
     team_score_mse: number;
@@ -0,0 +1,30 @@
+export interface LeagueSettings {
+    // "standard", "winners" or "scores"
+    scoring_profile: string;
+    // Game types whose predictions count; omitted when every game counts
+    game_types?: string[];
+    // "private" or "public"
+    visibility: string;
+    // "locked" hides other members' picks until the game locks, "open" shows them right away
+    prediction_visibility: string;
+}
+
+export interface League {
+    league_id: string;
+    name: string;
+    owner_id: string;
+    // Only sent to the league owner
+    invite_code?: string;
+    settings: LeagueSettings;
+    created_at: string;
+    // The caller's role, "owner" or "member"; omitted when they aren't a member
+    role?: string;
+    member_count: number;
+}
+
+export interface LeagueMember {
+    league_id: string;
+    user_id: string;
+    role: string;
+    joined_at: string;
+}
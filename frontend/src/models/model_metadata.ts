@@ -1,10 +1,65 @@
+import { LeaderboardEntry } from './leaderboard_entry';
+
+export interface TensorSpec {
+    name: string;
+    elem_type: string;
+    shape: number[];
+}
+
+export interface ModelSignature {
+    ir_version: number;
+    opset_version: number;
+    producer_name?: string;
+    inputs: TensorSpec[];
+    outputs: TensorSpec[];
+}
+
 export interface ModelMetadata {
     model_id: string;
     model_name: string;
     user_id: string;
     file_name: string;
     s3_key: string;
-    status: string;
+    status: string; // pending, validated, rejected or active
+    status_reason?: string;
+    sha256?: string;
+    size_bytes?: number;
+    content_type?: string;
+    framework?: "pickle" | "onnx";
+    signature?: ModelSignature;
+    features?: string[];
+    version: number;
+    changelog?: string;
+    promoted_at?: string | Date;
     created_at: string | Date;
     updated_at: string | Date;
-}
\ No newline at end of file
+}
+
+export interface ModelQuotaLimits {
+    max_active_models: number; // 0 means unlimited
+    max_storage_bytes: number;
+    max_uploads_per_day: number;
+}
+
+export interface ModelQuotaUsage {
+    active_models: number;
+    storage_bytes: number;
+    uploads_today: number;
+    limits: ModelQuotaLimits;
+}
+
+export interface UserModels {
+    models: ModelMetadata[];
+    quota: ModelQuotaUsage;
+}
+
+export interface ModelDownload {
+    url: string;
+    expires_at: string | Date;
+    model: ModelMetadata;
+}
+
+export interface ModelVersionSummary {
+    model: ModelMetadata;
+    stats?: LeaderboardEntry;
+}
@@ -1,6 +1,7 @@
 export interface Prediction {
     user_id: string;
     game_id: string;
+    model_id?: string;
     home_score_predicted: number;
     away_score_predicted: number;
     total_score_predicted: number;
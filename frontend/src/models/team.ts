@@ -0,0 +1,16 @@
+export interface Venue {
+    id: string;
+    name: string;
+    city: string;
+    time_zone: string;
+}
+
+export interface Team {
+    id: string;
+    name: string;
+    abbreviation: string;
+    league: string;
+    division: string;
+    logo_url: string;
+    venue: Venue;
+}
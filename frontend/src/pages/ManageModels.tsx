@@ -27,12 +27,18 @@ import { useNavigate } from "react-router-dom";
 import useAuth from "../hooks/useAuth";
 import { useEffect, useState } from "react";
 import { Plus, Trash2, Download, Eye } from "lucide-react";
-import { ModelMetadata } from "../models/model_metadata";
+import { ModelDownload, ModelMetadata, ModelQuotaUsage, UserModels } from "../models/model_metadata";
+
+const formatLimit = (used: number, limit: number, format: (n: number) => string = String) =>
+    limit > 0 ? `${format(used)} / ${format(limit)}` : format(used);
+
+const formatMegabytes = (bytes: number) => `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
 
 export default function ManageModels() {
     const navigate = useNavigate();
     const { user, getToken } = useAuth();
     const [models, setModels] = useState<ModelMetadata[]>([]);
+    const [quota, setQuota] = useState<ModelQuotaUsage | null>(null);
     const [loading, setLoading] = useState(true);
     const [error, setError] = useState<string | null>(null);
     const [deleteDialogOpen, setDeleteDialogOpen] = useState(false);
@@ -70,8 +76,9 @@ export default function ManageModels() {
                 throw new Error(`Failed to fetch models: ${response.statusText}`);
             }
 
-            const modelsData = await response.json();
-            setModels(modelsData || []);
+            const modelsData: UserModels = await response.json();
+            setModels(modelsData.models || []);
+            setQuota(modelsData.quota);
         } catch (err: any) {
             setError(err.message || "Failed to load models");
         } finally {
@@ -119,20 +126,43 @@ export default function ManageModels() {
         }
     };
 
-    const handleDownload = (model: ModelMetadata) => {
-        // TODO: Implement backend endpoint to generate a download URL
-        // Expected endpoint: POST /models/{modelId}/download
-        // Should return a signed S3 URL for downloading the model file
-        console.log("Downloading model:", model.model_id);
+    const handleDownload = async (model: ModelMetadata) => {
+        try {
+            const token = await getToken();
+
+            if (!token) {
+                setError("Authentication failed");
+                return;
+            }
+
+            // The backend returns a short-lived signed S3 URL for the model file
+            const response = await fetch(`/models/${model.model_id}/download`, {
+                headers: {
+                    "Authorization": `Bearer ${token}`,
+                },
+            });
+
+            if (!response.ok) {
+                const errorData = await response.json().catch(() => ({}));
+                throw new Error(errorData.error || `Failed to download model: ${response.statusText}`);
+            }
+
+            const download: ModelDownload = await response.json();
+            window.location.href = download.url;
+        } catch (err: any) {
+            setError(err.message || "Failed to download model");
+        }
     };
 
     const getStatusColor = (status: string): "default" | "primary" | "secondary" | "error" | "info" | "success" | "warning" => {
         switch (status?.toLowerCase()) {
             case "active":
+            case "validated":
                 return "success";
             case "pending":
                 return "info";
             case "failed":
+            case "rejected":
                 return "error";
             default:
                 return "default";
@@ -188,6 +218,14 @@ export default function ManageModels() {
                 </Alert>
             )}
 
+            {quota && (
+                <Box sx={{ display: "flex", gap: 1, mb: 3, flexWrap: "wrap" }}>
+                    <Chip label={`Active models: ${formatLimit(quota.active_models, quota.limits.max_active_models)}`} />
+                    <Chip label={`Storage: ${formatLimit(quota.storage_bytes, quota.limits.max_storage_bytes, formatMegabytes)}`} />
+                    <Chip label={`Uploads today: ${formatLimit(quota.uploads_today, quota.limits.max_uploads_per_day)}`} />
+                </Box>
+            )}
+
             {models.length === 0 ? (
                 <Card>
                     <CardContent sx={{ textAlign: "center", py: 6 }}>
@@ -226,15 +264,17 @@ export default function ManageModels() {
                                 <TableRow key={model.model_id} sx={{ "&:hover": { backgroundColor: "#f9f9f9" } }}>
                                     <TableCell>
                                         <Typography variant="body2" sx={{ fontWeight: 500 }}>
-                                            {model.model_name}
+                                            {model.model_name} (v{model.version ?? 1})
                                         </Typography>
                                     </TableCell>
                                     <TableCell>
-                                        <Chip
-                                            label={model.status || "Unknown"}
-                                            color={getStatusColor(model.status)}
-                                            size="small"
-                                        />
+                                        <Tooltip title={model.status_reason ?? ""}>
+                                            <Chip
+                                                label={model.status || "Unknown"}
+                                                color={getStatusColor(model.status)}
+                                                size="small"
+                                            />
+                                        </Tooltip>
                                     </TableCell>
                                     <TableCell>
                                         <Typography variant="caption">{model.file_name}</Typography>
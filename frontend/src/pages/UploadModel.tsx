@@ -19,6 +19,7 @@ import { Upload as UploadIcon } from "lucide-react";
 
 interface UploadFormData {
     modelName: string;
+    changelog: string;
     file: File | null;
 }
 
@@ -27,6 +28,7 @@ export default function UploadModel() {
     const { user, getToken } = useAuth();
     const [formData, setFormData] = useState<UploadFormData>({
         modelName: "",
+        changelog: "",
         file: null,
     });
     const [error, setError] = useState<string | null>(null);
@@ -40,12 +42,19 @@ export default function UploadModel() {
         }));
     };
 
+    const handleChangelogChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+        setFormData((prev) => ({
+            ...prev,
+            changelog: e.target.value,
+        }));
+    };
+
     const handleFileChange = (e: React.ChangeEvent<HTMLInputElement>) => {
         const file = e.target.files?.[0];
         if (file) {
             // Validate file type
-            if (!file.name.endsWith(".pkl") && file.type !== "application/octet-stream") {
-                setError("Please select a valid .pkl file");
+            if (!file.name.endsWith(".pkl") && !file.name.endsWith(".onnx") && file.type !== "application/octet-stream") {
+                setError("Please select a valid .pkl or .onnx file");
                 return;
             }
             setFormData((prev) => ({
@@ -81,18 +90,53 @@ export default function UploadModel() {
                 return;
             }
 
-            // Create FormData for multipart upload
-            const uploadFormData = new FormData();
-            uploadFormData.append("modelName", formData.modelName);
-            uploadFormData.append("file", formData.file);
+            // Hash the file so S3 can verify the upload against the checksum the URL was signed for
+            const digest = await crypto.subtle.digest("SHA-256", await formData.file.arrayBuffer());
+            const sha256 = Array.from(new Uint8Array(digest))
+                .map((b) => b.toString(16).padStart(2, "0"))
+                .join("");
 
-            // Upload to backend
-            const uploadResponse = await fetch("/models/submitModel", {
+            // Create the pending model and get a presigned upload URL
+            const createResponse = await fetch("/models/uploads", {
+                method: "POST",
+                headers: {
+                    "Authorization": `Bearer ${token}`,
+                    "Content-Type": "application/json",
+                },
+                body: JSON.stringify({
+                    model_name: formData.modelName,
+                    file_name: formData.file.name,
+                    size_bytes: formData.file.size,
+                    sha256: sha256,
+                    changelog: formData.changelog,
+                    content_type: formData.file.type || "application/octet-stream",
+                }),
+            });
+
+            if (!createResponse.ok) {
+                const errorData = await createResponse.json().catch(() => ({}));
+                throw new Error(errorData.error || `Upload failed with status ${createResponse.status}`);
+            }
+
+            const { model, upload } = await createResponse.json();
+
+            // Send the file straight to S3
+            const putResponse = await fetch(upload.url, {
+                method: upload.method,
+                headers: upload.headers,
+                body: formData.file,
+            });
+
+            if (!putResponse.ok) {
+                throw new Error(`Upload to storage failed with status ${putResponse.status}`);
+            }
+
+            // Let the backend verify and validate the stored file
+            const uploadResponse = await fetch(`/models/uploads/${model.model_id}/complete`, {
                 method: "POST",
                 headers: {
                     "Authorization": `Bearer ${token}`,
                 },
-                body: uploadFormData,
             });
 
             if (!uploadResponse.ok) {
@@ -109,6 +153,7 @@ export default function UploadModel() {
             // Reset form
             setFormData({
                 modelName: "",
+                changelog: "",
                 file: null,
             });
 
@@ -130,7 +175,7 @@ export default function UploadModel() {
                     Upload Your Model
                 </Typography>
                 <Typography variant="body1" color="textSecondary">
-                    Upload your trained .pkl (pickle) machine learning model to share with the
+                    Upload your trained .onnx or .pkl (pickle) machine learning model to share with the
                     prediction pool.
                 </Typography>
             </Box>
@@ -158,12 +203,24 @@ export default function UploadModel() {
                                 value={formData.modelName}
                                 onChange={handleModelNameChange}
                                 disabled={uploading}
-                                helperText="Choose a descriptive name for your model"
+                                helperText="Reuse an existing name to upload a new version of that model"
+                            />
+
+                            <TextField
+                                label="Changelog"
+                                placeholder="e.g., Retrained with 2025 data"
+                                fullWidth
+                                multiline
+                                minRows={2}
+                                value={formData.changelog}
+                                onChange={handleChangelogChange}
+                                disabled={uploading}
+                                helperText="Optional notes on what changed in this version"
                             />
 
                             <Box>
                                 <Typography variant="subtitle2" sx={{ mb: 1, fontWeight: 600 }}>
-                                    Model File (.pkl)
+                                    Model File (.onnx or .pkl)
                                 </Typography>
                                 <Paper
                                     variant="outlined"
@@ -191,19 +248,19 @@ export default function UploadModel() {
                                         <Typography variant="caption" color="textSecondary">
                                             {formData.file
                                                 ? `${(formData.file.size / 1024 / 1024).toFixed(2)} MB`
-                                                : "Only .pkl files are supported"}
+                                                : "Only .onnx and .pkl files are supported"}
                                         </Typography>
                                     </Box>
                                     <input
                                         type="file"
                                         hidden
-                                        accept=".pkl"
+                                        accept=".onnx,.pkl"
                                         onChange={handleFileChange}
                                         disabled={uploading}
                                     />
                                 </Paper>
                                 <FormHelperText sx={{ mt: 1 }}>
-                                    Upload a Python pickle file (.pkl) containing your trained model
+                                    ONNX models are validated against the feature schema on upload and are the safer choice
                                 </FormHelperText>
                             </Box>
 
@@ -241,10 +298,11 @@ export default function UploadModel() {
                     📝 Tips for uploading models:
                 </Typography>
                 <ul style={{ margin: 0, paddingLeft: 20 }}>
-                    <li>Ensure your model is saved as a .pkl (pickle) file</li>
+                    <li>Prefer ONNX: one float input of shape [N, 8] and a first output of shape [N, 3] (home runs, away runs, home win probability)</li>
+                    <li>Pickle (.pkl) files must be a fitted scikit-learn or xgboost estimator (or Pipeline) whose predict() returns the same three columns; custom classes are rejected</li>
                     <li>Use descriptive model names to help you identify them later</li>
                     <li>Keep file sizes reasonable for optimal performance</li>
-                    <li>You can upload multiple versions of your models</li>
+                    <li>Uploading under an existing name creates the next version; promote it once you're happy with it</li>
                 </ul>
             </Box>
         </Container>
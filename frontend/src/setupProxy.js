@@ -12,5 +12,6 @@ module.exports = function(app) {
         '/leaderboard',
         '/predictions',
         '/users',
+        '/blobs',
     ], backendProxy);
 };
\ No newline at end of file
@@ -16,7 +16,9 @@ resource "aws_dynamodb_table" "users" {
     }
 }
 
-# Predictions Table
+# Predictions Table, keyed by user and game as before model picks. The API moves to
+# predictions_v2 below; this one is kept until the copy has been checked. See
+# backend/cmd/migrate-predictions for the rollout.
 resource "aws_dynamodb_table" "predictions" {
     name = "${var.project_name}-${var.environment}-predictions"
     billing_mode = "PAY_PER_REQUEST"
@@ -40,6 +42,60 @@ resource "aws_dynamodb_table" "predictions" {
         projection_type = "ALL"
     }
 
+    lifecycle {
+        prevent_destroy = true
+    }
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# Predictions keyed by predictionKey, which is gameId for a user's own picks and
+# gameId#modelId for a model's, so each of a user's models can predict the same game.
+# A range key can't change in place, so this is a new table filled by
+# backend/cmd/migrate-predictions rather than a change to the one above.
+resource "aws_dynamodb_table" "predictions_v2" {
+    name = "${var.project_name}-${var.environment}-predictions-v2"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "userId"
+        type = "S"
+    }
+
+    attribute {
+        name = "gameId"
+        type = "S"
+    }
+
+    attribute {
+        name = "predictionKey"
+        type = "S"
+    }
+
+    attribute {
+        name = "modelId"
+        type = "S"
+    }
+
+    hash_key  = "userId"
+    range_key = "predictionKey"
+
+    global_secondary_index {
+        name            = "GameIdIndex"
+        hash_key        = "gameId"
+        projection_type = "ALL"
+    }
+
+    global_secondary_index {
+        name            = "ModelIdIndex"
+        hash_key        = "modelId"
+        range_key       = "gameId"
+        projection_type = "ALL"
+    }
+
     tags = {
         Project     = var.project_name
         Environment = var.environment
@@ -56,8 +112,64 @@ resource "aws_dynamodb_table" "games" {
         type = "S"
     }
 
+    attribute {
+        name = "status"
+        type = "S"
+    }
+
+    attribute {
+        name = "date"
+        type = "S"
+    }
+
+    attribute {
+        name = "gameDay"
+        type = "S"
+    }
+
+    attribute {
+        name = "homeTeamId"
+        type = "S"
+    }
+
+    attribute {
+        name = "awayTeamId"
+        type = "S"
+    }
+
     hash_key = "gameId"
 
+    # Upcoming games without a table scan
+    global_secondary_index {
+        name            = "StatusDateIndex"
+        hash_key        = "status"
+        range_key       = "date"
+        projection_type = "ALL"
+    }
+
+    # Games on a given day (YYYY-MM-DD), ordered by start time
+    global_secondary_index {
+        name            = "GameDayIndex"
+        hash_key        = "gameDay"
+        range_key       = "date"
+        projection_type = "ALL"
+    }
+
+    # A team's schedule, split by home and away
+    global_secondary_index {
+        name            = "HomeTeamDayIndex"
+        hash_key        = "homeTeamId"
+        range_key       = "gameDay"
+        projection_type = "ALL"
+    }
+
+    global_secondary_index {
+        name            = "AwayTeamDayIndex"
+        hash_key        = "awayTeamId"
+        range_key       = "gameDay"
+        projection_type = "ALL"
+    }
+
     tags = {
         Project     = var.project_name
         Environment = var.environment
@@ -80,4 +192,204 @@ resource "aws_dynamodb_table" "models" {
         Project     = var.project_name
         Environment = var.environment
     }
-}
\ No newline at end of file
+}
+
+# Model uploads per user and day. Counts only go up, so deleting a model doesn't
+# reset the daily upload limit.
+resource "aws_dynamodb_table" "model_uploads" {
+    name = "${var.project_name}-${var.environment}-model-uploads"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "userId"
+        type = "S"
+    }
+
+    attribute {
+        name = "day"
+        type = "S"
+    }
+
+    hash_key  = "userId"
+    range_key = "day"
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# Model versions in use, one item per user and "modelName#version". Uploads claim their
+# version here in the same transaction that stores the model, so two concurrent uploads
+# to a family can't both become the same version.
+resource "aws_dynamodb_table" "model_versions" {
+    name = "${var.project_name}-${var.environment}-model-versions"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "userId"
+        type = "S"
+    }
+
+    attribute {
+        name = "familyVersion"
+        type = "S"
+    }
+
+    hash_key  = "userId"
+    range_key = "familyVersion"
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# Teams table, seeded by the API from its bundled reference data
+resource "aws_dynamodb_table" "teams" {
+    name = "${var.project_name}-${var.environment}-teams"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "teamId"
+        type = "S"
+    }
+
+    hash_key = "teamId"
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# Seasons table, loaded by cmd/ingest -mode=season
+resource "aws_dynamodb_table" "seasons" {
+    name = "${var.project_name}-${var.environment}-seasons"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "year"
+        type = "N"
+    }
+
+    hash_key = "year"
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# Leagues table. Invite codes are looked up when someone joins.
+resource "aws_dynamodb_table" "leagues" {
+    name = "${var.project_name}-${var.environment}-leagues"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "leagueId"
+        type = "S"
+    }
+
+    attribute {
+        name = "inviteCode"
+        type = "S"
+    }
+
+    hash_key = "leagueId"
+
+    global_secondary_index {
+        name            = "InviteCodeIndex"
+        hash_key        = "inviteCode"
+        projection_type = "ALL"
+    }
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# League members table, one item per league and user
+resource "aws_dynamodb_table" "league_members" {
+    name = "${var.project_name}-${var.environment}-league-members"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "leagueId"
+        type = "S"
+    }
+
+    attribute {
+        name = "userId"
+        type = "S"
+    }
+
+    hash_key  = "leagueId"
+    range_key = "userId"
+
+    global_secondary_index {
+        name            = "UserIdIndex"
+        hash_key        = "userId"
+        range_key       = "leagueId"
+        projection_type = "ALL"
+    }
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# Contests table. A league's contests are listed by leagueId.
+resource "aws_dynamodb_table" "contests" {
+    name = "${var.project_name}-${var.environment}-contests"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "contestId"
+        type = "S"
+    }
+
+    attribute {
+        name = "leagueId"
+        type = "S"
+    }
+
+    hash_key = "contestId"
+
+    global_secondary_index {
+        name            = "LeagueIdIndex"
+        hash_key        = "leagueId"
+        projection_type = "ALL"
+    }
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
+
+# Contest entries table, one item per contest and user
+resource "aws_dynamodb_table" "contest_entries" {
+    name = "${var.project_name}-${var.environment}-contest-entries"
+    billing_mode = "PAY_PER_REQUEST"
+
+    attribute {
+        name = "contestId"
+        type = "S"
+    }
+
+    attribute {
+        name = "userId"
+        type = "S"
+    }
+
+    hash_key  = "contestId"
+    range_key = "userId"
+
+    tags = {
+        Project     = var.project_name
+        Environment = var.environment
+    }
+}
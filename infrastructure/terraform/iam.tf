@@ -37,8 +37,8 @@ resource "aws_iam_role_policy" "lambda_dynamodb_policy" {
                     "dynamodb:BatchWriteItem"
                 ]
                 Resource = [
-                    aws_dynamodb_table.games.arn,
                     aws_dynamodb_table.predictions.arn,
+                    aws_dynamodb_table.predictions_v2.arn,
                     aws_dynamodb_table.users.arn,
                     aws_dynamodb_table.models.arn
                 ]
@@ -127,10 +127,23 @@ resource "aws_iam_role_policy" "ec2_dynamodb_policy" {
                     "${aws_dynamodb_table.games.arn}/index/*",
                     aws_dynamodb_table.predictions.arn,
                     "${aws_dynamodb_table.predictions.arn}/index/*",
+                    aws_dynamodb_table.predictions_v2.arn,
+                    "${aws_dynamodb_table.predictions_v2.arn}/index/*",
                     aws_dynamodb_table.users.arn,
                     "${aws_dynamodb_table.users.arn}/index/*",
                     aws_dynamodb_table.models.arn,
-                    "${aws_dynamodb_table.models.arn}/index/*"
+                    "${aws_dynamodb_table.models.arn}/index/*",
+                    aws_dynamodb_table.model_uploads.arn,
+                    aws_dynamodb_table.model_versions.arn,
+                    aws_dynamodb_table.teams.arn,
+                    aws_dynamodb_table.seasons.arn,
+                    aws_dynamodb_table.leagues.arn,
+                    "${aws_dynamodb_table.leagues.arn}/index/*",
+                    aws_dynamodb_table.league_members.arn,
+                    "${aws_dynamodb_table.league_members.arn}/index/*",
+                    aws_dynamodb_table.contests.arn,
+                    "${aws_dynamodb_table.contests.arn}/index/*",
+                    aws_dynamodb_table.contest_entries.arn
                 ]
             }
         ]
@@ -32,8 +32,7 @@ resource "aws_lambda_function" "data_ingestion" {
 
     environment {
         variables = {
-            GAMES_TABLE        = aws_dynamodb_table.games.name
-            PREDICTIONS_TABLE  = aws_dynamodb_table.predictions.name
+            PREDICTIONS_TABLE  = aws_dynamodb_table.predictions_v2.name
             USERS_TABLE        = aws_dynamodb_table.users.name
             MODELS_TABLE       = aws_dynamodb_table.models.name
             DATA_BUCKET        = aws_s3_bucket.mlb_data.bucket
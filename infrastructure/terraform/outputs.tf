@@ -2,9 +2,18 @@ output "dynamodb_tables" {
     description = "DynamoDB table names"
     value = {
         users_table       = aws_dynamodb_table.users.name
-        predictions_table = aws_dynamodb_table.predictions.name
+        predictions_table = aws_dynamodb_table.predictions_v2.name
+        legacy_predictions_table = aws_dynamodb_table.predictions.name
         games_table       = aws_dynamodb_table.games.name
         models = aws_dynamodb_table.models.name
+        model_uploads_table = aws_dynamodb_table.model_uploads.name
+        model_versions_table = aws_dynamodb_table.model_versions.name
+        teams_table       = aws_dynamodb_table.teams.name
+        seasons_table     = aws_dynamodb_table.seasons.name
+        leagues_table     = aws_dynamodb_table.leagues.name
+        league_members_table = aws_dynamodb_table.league_members.name
+        contests_table    = aws_dynamodb_table.contests.name
+        contest_entries_table = aws_dynamodb_table.contest_entries.name
     }
 }
 
@@ -36,6 +36,19 @@ resource "aws_s3_bucket_public_access_block" "user_models" {
     restrict_public_buckets = true
 }
 
+# Browsers upload model files straight to the bucket with presigned PUT URLs.
+# The signature is the authorization, so any origin may send them.
+resource "aws_s3_bucket_cors_configuration" "user_models" {
+    bucket = aws_s3_bucket.user_models.id
+
+    cors_rule {
+        allowed_methods = ["PUT"]
+        allowed_origins = ["*"]
+        allowed_headers = ["*"]
+        max_age_seconds = 3000
+    }
+}
+
 resource "aws_s3_bucket_versioning" "user_models_versioning" {
     bucket = aws_s3_bucket.user_models.id
 
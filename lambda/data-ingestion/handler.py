@@ -2,28 +2,26 @@ import json
 import os
 import boto3
 import requests
-from datetime import datetime, timedelta
-from typing import List, Dict
+from datetime import datetime
+from typing import Dict
 import statsapi
 
-dynamodb = boto3.resource('dynamodb')
 s3 = boto3.client('s3')
 
-GAMES_TABLE = os.environ.get("GAMES_TABLE", "mlb-prediction-pool-dev-games")
 DATA_BUCKET = os.environ.get("DATA_BUCKET", "mlb-prediction-pool-dev-mlb-data")
 MLB_API_BASE = os.environ.get("MLB_API_BASE", "https://statsapi.mlb.com/api/v1")
 
+
+def current_season() -> str:
+    """The season to fetch stats for: SEASON if set, otherwise the current year."""
+    return os.environ.get("SEASON") or str(datetime.now().year)
+
 def lambda_handler(event, context):
     '''
-    Fetches daily MLB data and stores it in DynamoDB and S3.
+    Fetches daily MLB team stats and stores them in S3. Games are loaded by cmd/ingest, which owns
+    the games table.
     '''
     try:
-        games = fetch_upcoming_games()
-        print(f"Fetched {len(games)} upcoming games.")
-
-        store_games_in_dynamodb(games)
-        print("Stored games in DynamoDB.")
-
         fetch_and_store_all_stats()
         print("Fetched and stored team stats in S3.")
 
@@ -31,49 +29,18 @@ def lambda_handler(event, context):
             "statusCode": 200,
             "body": json.dumps({
                 "message": "Data ingestion completed successfully",
-                "games_fetched": len(games),
                 "teams_updated": len(fetch_team_hitting_stats()),
             })
         }
     except Exception as e:
-        print(f"Error fetching games: {e}")
-        return {"statusCode": 500, "body": f"Error fetching games: {e}"}
+        print(f"Error fetching team stats: {e}")
+        return {"statusCode": 500, "body": f"Error fetching team stats: {e}"}
     
 
-def fetch_upcoming_games() -> List[Dict]:
-    """
-    Fetches games for the next 3 days from MLB Stats API.
-    """
-    today = datetime.now()
-    end_date = today + timedelta(days=3)
-    
-    game_data: List[Dict] = statsapi.schedule(
-        start_date=today.strftime("%Y-%m-%d"),
-        end_date=end_date.strftime("%Y-%m-%d"),
-        include_series_status=False
-    )
-
-    games = []
-
-    for game in game_data:
-        game_info = {
-            'gameId': str(game.get('game_id')),
-            'date': game.get('game_date'),
-            'homeTeamId': game.get('home_id'),
-            'homeTeam': game.get('home_name'),
-            'awayTeamId': game.get('away_id'),
-            'awayTeam': game.get('away_name'),
-            'status': str(game.get('status'))
-        }
-
-        games.append(game_info)
-
-    return games
-
 def fetch_team_hitting_stats() -> Dict:
     """Fetches team hitting stats for the current season from MLB Stats API."""
     url = build_mlb_api_url("teams/stats", {
-        "season": "2025",
+        "season": current_season(),
         "group": "hitting",
         "sportIds": "1"
     })
@@ -93,7 +60,7 @@ def fetch_team_hitting_stats() -> Dict:
 def fetch_team_pitching_stats() -> Dict:
     """Fetches team pitching stats for the current season from MLB Stats API."""
     url = build_mlb_api_url("teams/stats", {
-        "season": "2025",
+        "season": current_season(),
         "group": "pitching",
         "sportIds": "1"
     })
@@ -113,7 +80,7 @@ def fetch_team_pitching_stats() -> Dict:
 def fetch_team_fielding_stats() -> Dict:
     """Fetches team fielding stats for the current season from MLB Stats API."""
     url = build_mlb_api_url("teams/stats", {
-        "season": "2025",
+        "season": current_season(),
         "group": "fielding",
         "sportIds": "1"
     })
@@ -132,7 +99,7 @@ def fetch_team_fielding_stats() -> Dict:
 def fetch_team_catching_stats() -> Dict:
     """Fetches team catching stats for the current season from MLB Stats API."""
     url = build_mlb_api_url("teams/stats", {
-        "season": "2025",
+        "season": current_season(),
         "group": "catching",
         "sportIds": "1"
     })
@@ -166,14 +133,6 @@ def fetch_standings() -> Dict:
 
     return standings
 
-def store_games_in_dynamodb(games: List[Dict]):
-    """Store fetched games in DynamoDB"""
-    table = dynamodb.Table(GAMES_TABLE) # type: ignore
-
-    with table.batch_writer() as batch:
-        for game in games:
-            batch.put_item(Item=game)
-
 def store_data_in_s3(team_stats: Dict, category: str):
     date_str = datetime.now().strftime("%Y-%m-%d")
     # Store each day